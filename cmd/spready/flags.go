@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/reporter"
+)
+
+// parseIssueTypes resolves -only-type names to issue types, ignoring case.
+// It returns nil when no names are given, and the first unknown name.
+func parseIssueTypes(names []string) (map[models.IssueType]bool, string) {
+	if len(names) == 0 {
+		return nil, ""
+	}
+
+	types := make(map[models.IssueType]bool)
+	for _, name := range names {
+		found := false
+		for _, issueType := range models.IssueTypes {
+			if strings.EqualFold(name, string(issueType)) {
+				types[issueType] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, name
+		}
+	}
+	return types, ""
+}
+
+// issueTypeList names every issue type for error messages
+func issueTypeList() string {
+	names := make([]string, len(models.IssueTypes))
+	for i, issueType := range models.IssueTypes {
+		names[i] = string(issueType)
+	}
+	return strings.Join(names, ", ")
+}
+
+// reportFormats returns the report formats to write: those listed in
+// -format, in order and without repeats, or else the ones turned on by
+// -json, -csv, -html and -xml
+func reportFormats(list string, withJSON, withCSV, withHTML, withXML bool) ([]string, error) {
+	if list == "" {
+		var formats []string
+		for _, f := range []struct {
+			name    string
+			enabled bool
+		}{
+			{reporter.FormatJSON, withJSON},
+			{reporter.FormatCSV, withCSV},
+			{reporter.FormatHTML, withHTML},
+			{reporter.FormatXML, withXML},
+		} {
+			if f.enabled {
+				formats = append(formats, f.name)
+			}
+		}
+		return formats, nil
+	}
+
+	known := reporter.Formats()
+	var formats []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || containsFormat(formats, name) {
+			continue
+		}
+		if !containsFormat(known, name) {
+			return nil, fmt.Errorf("invalid -format value %q (expected a comma-separated list of %s)", name, strings.Join(known, ", "))
+		}
+		formats = append(formats, name)
+	}
+	return formats, nil
+}
+
+func containsFormat(formats []string, format string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// stringListFlag collects the values of a repeatable flag.
+// Comma-separated values are split into separate entries.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*f = append(*f, part)
+		}
+	}
+	return nil
+}
+
+// headerFlag collects "Name: value" HTTP headers for -post-url
+type headerFlag []string
+
+func (f *headerFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *headerFlag) Set(value string) error {
+	name, _, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid header %q (expected \"Name: value\")", value)
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+func (f headerFlag) values() map[string]string {
+	headers := make(map[string]string, len(f))
+	for _, header := range f {
+		name, value, _ := strings.Cut(header, ":")
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
+// sizeFlag parses a byte size with an optional B, KB, MB, GB or TB suffix.
+// Units are binary, so 1KB is 1024 bytes.
+type sizeFlag int64
+
+func (f *sizeFlag) String() string {
+	return strconv.FormatInt(int64(*f), 10)
+}
+
+func (f *sizeFlag) Set(value string) error {
+	s := strings.ToUpper(strings.TrimSpace(value))
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"TB", 1 << 40},
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid size %q (expected a number with an optional B, KB, MB, GB or TB suffix)", value)
+	}
+
+	*f = sizeFlag(n * float64(multiplier))
+	return nil
+}
+
+// sizeTiersFlag parses comma-separated size:severity pairs such as
+// "100MB:info,2GB:warning"
+type sizeTiersFlag struct {
+	tiers []config.FileSizeTier
+	set   bool
+}
+
+func (f *sizeTiersFlag) String() string {
+	var parts []string
+	for _, tier := range f.tiers {
+		parts = append(parts, strconv.FormatInt(tier.Bytes, 10)+":"+tier.Severity)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *sizeTiersFlag) Set(value string) error {
+	var tiers []config.FileSizeTier
+	for _, pair := range strings.Split(value, ",") {
+		size, severity, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return fmt.Errorf("invalid size tier %q (expected size:severity, e.g. 100MB:info)", pair)
+		}
+		var bytes sizeFlag
+		if err := bytes.Set(size); err != nil {
+			return err
+		}
+		tiers = append(tiers, config.FileSizeTier{Bytes: int64(bytes), Severity: strings.TrimSpace(severity)})
+	}
+	f.tiers = tiers
+	f.set = true
+	return nil
+}
+
+// dateFlag parses an RFC3339 timestamp or a YYYY-MM-DD date in local time
+type dateFlag time.Time
+
+func (f *dateFlag) String() string {
+	t := time.Time(*f)
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func (f *dateFlag) Set(value string) error {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		*f = dateFlag(t)
+		return nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		*f = dateFlag(t)
+		return nil
+	}
+	return fmt.Errorf("invalid date %q (expected YYYY-MM-DD or RFC3339)", value)
+}
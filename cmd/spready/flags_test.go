@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+)
+
+func TestSizeTiersFlag(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []config.FileSizeTier // After SetFileSizeWarnings, largest first
+		wantErr bool
+	}{
+		{"100MB:info,2GB:warning", []config.FileSizeTier{{Bytes: 2 << 30, Severity: "Warning"}, {Bytes: 100 << 20, Severity: "Info"}}, false},
+		{" 2GB : Warning , 100MB:INFO ", []config.FileSizeTier{{Bytes: 2 << 30, Severity: "Warning"}, {Bytes: 100 << 20, Severity: "Info"}}, false},
+		{"1.5KB:critical", []config.FileSizeTier{{Bytes: 1536, Severity: "Critical"}}, false},
+		{"100XB:info", nil, true},
+		{"100MB", nil, true},
+		{"100MB:", nil, true},
+		{"100MB:urgent", nil, true},
+		{"-1MB:info", nil, true},
+		{"0:info", nil, true},
+	}
+
+	for _, tt := range tests {
+		var f sizeTiersFlag
+		err := f.Set(tt.value)
+		cfg := config.NewDefaultConfig()
+		if err == nil {
+			err = cfg.SetFileSizeWarnings(f.tiers)
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("-size-warn %q: error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(cfg.Settings.FileSizeWarnings, tt.want) {
+			t.Errorf("-size-warn %q: tiers = %v, want %v", tt.value, cfg.Settings.FileSizeWarnings, tt.want)
+		}
+	}
+}
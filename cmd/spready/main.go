@@ -1,22 +1,23 @@
 package main
 
 import (
-	"context"
+	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"syscall"
+	"strings"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
 	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
 	"github.com/ajoshuasmith/sharepoint-prescan/internal/reporter"
-	"github.com/ajoshuasmith/sharepoint-prescan/internal/scanner"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/runner"
 	"github.com/ajoshuasmith/sharepoint-prescan/internal/ui"
 	"github.com/ajoshuasmith/sharepoint-prescan/internal/validator"
+	"github.com/ajoshuasmith/sharepoint-prescan/scan"
 	"github.com/mattn/go-isatty"
 )
 
@@ -25,31 +26,469 @@ var (
 	commit  = "dev"
 )
 
+// Exit codes. Issue-driven codes are kept separate from operational
+// failures so CI pipelines can tell "scan found problems" from "scan broke".
+const (
+	exitOK          = 0   // No issues at or above the -fail-on severity
+	exitWarnings    = 1   // Warnings found (with -fail-on warning)
+	exitCritical    = 2   // Critical issues found
+	exitError       = 3   // Invalid usage or operational failure
+	exitPostFailed  = 4   // Scan succeeded but -post-url delivery failed
+	exitPanic       = 5   // Internal error; partial results were saved as JSON
+	exitInterrupted = 130 // Scan canceled by the user
+)
+
 func main() {
+	// Parse errors exit with exitError rather than the flag package's default of 2
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+
 	// Command line flags
 	scanPath := flag.String("path", "", "Path to scan (required)")
 	destinationURL := flag.String("destination", "", "SharePoint destination URL (optional)")
+	encodingBasis := flag.String("encoding-basis", "decoded", "Path length basis: decoded (SharePoint's server-relative formula) or encoded (percent-encoded, more conservative)")
 	outputDir := flag.String("output", ".", "Output directory for reports")
+	configFile := flag.String("config", "", "JSON config file with rule overrides and custom rules (optional)")
 	outputJSON := flag.Bool("json", true, "Generate JSON report")
 	outputCSV := flag.Bool("csv", true, "Generate CSV report")
 	outputHTML := flag.Bool("html", true, "Generate HTML report")
+	outputXML := flag.Bool("xml", false, "Generate XML report")
+	formatList := flag.String("format", "", "Comma-separated report formats to write, e.g. html,csv ("+strings.Join(reporter.Formats(), ", ")+"); replaces -json, -csv, -html and -xml")
+	splitBySeverity := flag.Bool("split-by-severity", false, "Also write each report once per severity found, e.g. sp-readiness-<timestamp>-critical.csv")
+	splitOnly := flag.Bool("split-only", false, "With -split-by-severity, skip the combined reports")
+	splitEmpty := flag.Bool("split-empty", false, "With -split-by-severity, also write reports for severities with no issues")
+	progressInterval := flag.Int("progress-interval", 0, "Milliseconds between progress updates (default from config, 500)")
+	statusFile := flag.String("status-file", "", "Keep a JSON status file with the latest progress, for unattended scans")
+	metricsFile := flag.String("metrics-file", "", "Write Prometheus text-format metrics to this file after the scan, e.g. for the node_exporter textfile collector")
+	liveHTML := flag.Bool("live-html", false, "Rewrite the HTML report every 10 seconds during the scan, so it can be watched in a browser")
+	streamCSV := flag.Bool("stream-csv", false, "Write CSV rows as issues are found, in scan order instead of sorted by severity")
+	sortBy := flag.String("sort", reporter.SortSeverity, "Order of issues in the CSV and HTML reports: severity, path, size or type")
+	reportTitle := flag.String("report-title", "", "Title shown at the top of the HTML report (default \""+reporter.DefaultReportTitle+"\")")
+	companyName := flag.String("company", "", "Company name shown in the HTML report and used for {company} in filenames")
+	projectName := flag.String("project", "", "Project name shown in the HTML report and used for {project} in filenames")
+	noRemediation := flag.Bool("no-remediation", false, "Leave the Recommended Actions section out of the HTML report")
+	noTimestamp := flag.Bool("no-timestamp", false, "Leave the timestamp out of report filenames and the HTML report, so unchanged scans write identical reports")
+	var filenameTemplate string
+	flag.StringVar(&filenameTemplate, "filename-template", "", "Report filename template; tokens: {timestamp}, {company}, {project}, {root} (default \""+reporter.DefaultFilenameTemplate+"\")")
+	flag.StringVar(&filenameTemplate, "name", "", "Shorthand for -filename-template")
+	collapseProblematic := flag.Bool("collapse-problematic", false, "Report problematic files as one summary issue per category")
+	collapseThreshold := flag.Int("collapse-threshold", 0, "Issues per category before -collapse-problematic folds them (default from config, 100)")
+	collapseList := flag.Bool("collapse-list", true, "With -collapse-problematic, write the folded files to a sidecar CSV")
+	maxIssuesPerFolder := flag.Int("max-issues-per-folder", 0, "Report at most this many issues per folder, then one row with the number left out (0 = no limit)")
+	folderStats := flag.Bool("folder-stats", false, "Generate a per-folder size, file and issue rollup (CSV, plus JSON with -json)")
+	folderStatsDepth := flag.Int("folder-stats-depth", 1, "Folder depth for -folder-stats (1 = top-level folders)")
+	renameMap := flag.Bool("rename-map", false, "Write a CSV of suggested SharePoint-safe names (OriginalPath,SuggestedName,Reason) for review")
+	bundle := flag.Bool("bundle", false, "Write all reports and a run.json into a new <timestamp>-<root> folder under -output")
+	zipBundle := flag.Bool("zip", false, "With -bundle, also archive the bundle folder as a .zip next to it")
+	outputManifest := flag.Bool("manifest", false, "Generate CSV manifest of every scanned file and folder")
+	incremental := flag.Bool("incremental", false, "Only validate files changed since the last -incremental run, reusing earlier issues for the rest")
+	indexFile := flag.String("index-file", "", "Index used by -incremental (default spready-index.json in the output directory)")
+	workers := flag.Int("workers", 0, "Validation workers (default: CPU count, up to 8)")
+	var maxMemory sizeFlag
+	flag.Var(&maxMemory, "max-memory", "Soft limit on memory use, e.g. 2GB; with -stream-csv, issues already on disk are dropped from memory near it. Memory held by whole-tree checks, such as name conflicts and empty folders, is not covered")
 	maxItems := flag.Int64("max-items", 0, "Maximum items to scan (0 = unlimited)")
+	readRetries := flag.Int("read-retries", 2, "Times to retry a folder or file that fails to read with a transient error, such as a network timeout (0 = no retries)")
+	retryBackoff := flag.Duration("retry-backoff", 500*time.Millisecond, "Wait before the first retry of a failed read; doubles for each retry after it")
+	var minSize, maxSize sizeFlag
+	flag.Var(&minSize, "min-size", "Skip files smaller than this size (e.g. 10MB)")
+	flag.Var(&maxSize, "max-size", "Skip files larger than this size (e.g. 1GB)")
+	var sizeWarn sizeTiersFlag
+	flag.Var(&sizeWarn, "size-warn", "Report large files at these size:severity tiers instead of the defaults (e.g. 100MB:info,2GB:warning)")
+	var modifiedBefore, modifiedAfter dateFlag
+	flag.Var(&modifiedBefore, "modified-before", "Skip files modified at or after this date (YYYY-MM-DD or RFC3339)")
+	flag.Var(&modifiedAfter, "modified-after", "Skip files modified at or before this date (YYYY-MM-DD or RFC3339)")
+	ignoreFile := flag.String("ignore-file", "", "File of path globs, optionally prefixed with [IssueType], whose issues are suppressed")
+	explain := flag.String("explain", "", "Describe the rules behind an issue type (e.g. ProblematicFile) or extension (e.g. .pst) and exit")
+	validateName := flag.String("validate-name", "", "Check a single proposed file or folder name against the rules and exit")
+	validatePath := flag.String("validate-path", "", "Check a proposed path below the library root, folder by folder, against the rules and exit")
+	asFolder := flag.Bool("as-folder", false, "With -validate-name or -validate-path, treat the last name as a folder instead of a file")
+	summaryFormat := flag.String("summary-format", "", "Print a compact summary for chat: plain, slack or teams")
+	githubAnnotations := flag.Bool("github-annotations", false, "Print issues as GitHub Actions annotations (at most 10 of each severity, then a count of the rest)")
+	postURL := flag.String("post-url", "", "POST the JSON scan result to this URL when the scan completes")
+	var postHeaders headerFlag
+	flag.Var(&postHeaders, "post-header", "Header for -post-url as \"Name: value\" (repeatable)")
+	postTimeout := flag.Duration("post-timeout", 60*time.Second, "Time limit for -post-url, including retries")
+	invalidChars := flag.String("invalid-chars", "", "Characters not allowed in names, replacing the built-in set \" * : < > ? / \\ |")
+	var nameReplacement *string
+	flag.Func("name-replacement", "Replacement for invalid characters in suggested names (default \"_\")", func(value string) error {
+		nameReplacement = &value
+		return nil
+	})
+	lang := flag.String("lang", "", "Language of issue messages: en, or the path of a message catalog file (default en)")
+	pathWarnPercent := flag.Int("path-warn-percent", 0, "Warn when a path uses at least this percentage (1-99) of the path limit (default 80)")
+	maxPathLength := flag.Int("max-path-length", 0, "Override the SharePoint path length limit (default 400)")
+	checkSyncPaths := flag.Bool("check-sync-paths", false, "Warn on paths that will be too long for Windows once the library is synced with OneDrive")
+	syncRoot := flag.String("sync-root", "", "Local folder the library syncs to, for -check-sync-paths (implies it; default "+config.DefaultSyncRoot+")")
+	folderNameWarn := flag.Int("folder-name-warn-length", 0, "Warn on folder names at least this many characters long, below the name limit (default off)")
+	maxNameLength := flag.Int("max-name-length", 0, "Override the file and folder name length limit (default 255)")
+	countFiltered := flag.Bool("count-filtered", false, "Include files skipped by -min-size, -max-size and -modified-* in the item totals")
+	sniff := flag.Bool("sniff", false, "Read the first 4 KB of each file and flag content that does not match the extension")
+	var sniffMaxSize sizeFlag
+	flag.Var(&sniffMaxSize, "sniff-max-size", "Only sniff files up to this size (default 100MB)")
+	var problematicMinSize sizeFlag
+	flag.Var(&problematicMinSize, "problematic-min-size", "Only report problematic file types (CAD, media, backups...) larger than this size, e.g. 100KB; secrets are always reported")
+	checkLocks := flag.Bool("check-locks", false, "Flag files that are open in another process (Windows only; opens every file)")
+	checkStreams := flag.Bool("check-streams", false, "Flag files with NTFS alternate data streams (Windows only; lists the streams of every file)")
+	checkShortNames := flag.Bool("check-short-names", false, "Flag 8.3 short names that collide with another name in the same folder (Windows only; looks up the short name of every item)")
+	detectCaseConflicts := flag.Bool("detect-case-conflicts", false, "Flag paths anywhere in the tree that differ only by letter case")
+	secretsOnly := flag.Bool("secrets-only", false, "Run only the check for files whose names suggest keys or credentials")
+	detectConfusables := flag.Bool("detect-confusables", false, "Flag names in the same folder that look the same but use lookalike letters from another script (advisory)")
+	pathsFrom := flag.String("paths-from", "", "Validate only the newline-delimited paths in this file (- for stdin) instead of walking -path")
+	verifyReport := flag.String("verify", "", "Re-check only the paths with issues in this earlier JSON report and list which issues are resolved")
+	noDefaultExcludes := flag.Bool("no-default-excludes", false, "Scan folders that are skipped by default ($RECYCLE.BIN, System Volume Information, RECYCLER, .Trash-*)")
 	noBanner := flag.Bool("no-banner", false, "Suppress banner display")
 	noProgress := flag.Bool("no-progress", false, "Suppress progress display")
+	logLevel := flag.String("log-level", "", "Log to stderr at this level: error, info or debug (default error, or info with consoleSettings.verboseOutput)")
 	useTUIFlag := flag.Bool("tui", false, "Run interactive TUI")
 	showVersion := flag.Bool("version", false, "Show version and exit")
-
-	flag.Parse()
+	profile := flag.String("profile", "", "Write a pprof profile of the scan to the output directory: cpu or mem")
+	bench := flag.Int("bench", 0, "Scan a temporary tree of this many generated files, print items/sec and allocations, and exit")
+	dumpConfig := flag.Bool("dump-config", false, "Print the effective config, after -config and flag overrides, as JSON and exit")
+	configDiff := flag.String("config-diff", "", "Compare this config file with the built-in defaults, list the rules that differ and exit")
+	failOn := flag.String("fail-on", "warning", "Lowest severity that causes a non-zero exit: none, warning, critical")
+	strict := flag.Bool("strict", false, "Exit with the Critical exit code when any Warning or Critical issue is found")
+	failFast := flag.Bool("fail-fast", false, "Stop the scan at the first Critical issue and exit with the Critical exit code")
+
+	var blockExts, allowExts stringListFlag
+	flag.Var(&blockExts, "block-ext", "Extension to flag as blocked for this run (repeatable)")
+	flag.Var(&allowExts, "allow-ext", "Extension to stop flagging as blocked or problematic (repeatable)")
+	var acceptCategories stringListFlag
+	flag.Var(&acceptCategories, "accept-category", "Problematic-file category whose warnings and info are not reported, e.g. Backup (repeatable)")
+	var onlyTypeNames stringListFlag
+	flag.Var(&onlyTypeNames, "only-type", "Only report issues of this type, e.g. InvalidCharacters (repeatable; the exit code still counts every issue)")
+
+	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(exitOK)
+		}
+		os.Exit(exitError)
+	}
 
 	// Show version
 	if *showVersion {
 		fmt.Printf("spready version %s (commit: %s)\n", version, commit)
 		fmt.Println("SharePoint Online Migration Readiness Scanner - Go Edition")
-		os.Exit(0)
+		os.Exit(exitOK)
+	}
+
+	switch *encodingBasis {
+	case "decoded", "encoded":
+	default:
+		fmt.Printf("Error: invalid -encoding-basis value %q (expected decoded or encoded)\n", *encodingBasis)
+		os.Exit(exitError)
+	}
+
+	switch *failOn {
+	case "none", "warning", "critical":
+	default:
+		fmt.Printf("Error: invalid -fail-on value %q (expected none, warning or critical)\n", *failOn)
+		os.Exit(exitError)
+	}
+	if *strict && *failOn != "warning" {
+		fmt.Printf("Error: -strict cannot be combined with -fail-on %s\n", *failOn)
+		os.Exit(exitError)
+	}
+
+	if *pathWarnPercent != 0 && (*pathWarnPercent < 1 || *pathWarnPercent > 99) {
+		fmt.Printf("Error: invalid -path-warn-percent value %d (expected 1-99)\n", *pathWarnPercent)
+		os.Exit(exitError)
+	}
+	if *workers < 0 {
+		fmt.Printf("Error: invalid -workers value %d (expected 1 or more)\n", *workers)
+		os.Exit(exitError)
+	}
+	if *readRetries < 0 {
+		fmt.Printf("Error: invalid -read-retries value %d (expected 0 or more)\n", *readRetries)
+		os.Exit(exitError)
+	}
+	if *retryBackoff <= 0 {
+		fmt.Printf("Error: invalid -retry-backoff value %s (expected more than 0)\n", *retryBackoff)
+		os.Exit(exitError)
+	}
+	// scan.Options treats 0 as the default count
+	readRetryCount := *readRetries
+	if readRetryCount == 0 {
+		readRetryCount = -1
+	}
+	if *progressInterval != 0 && *progressInterval < config.MinProgressUpdateInterval {
+		fmt.Printf("Error: invalid -progress-interval value %d (expected %d or more)\n", *progressInterval, config.MinProgressUpdateInterval)
+		os.Exit(exitError)
+	}
+	if *folderNameWarn < 0 {
+		fmt.Printf("Error: invalid -folder-name-warn-length value %d (expected 1 or more)\n", *folderNameWarn)
+		os.Exit(exitError)
+	}
+	switch *profile {
+	case "", "cpu", "mem":
+	default:
+		fmt.Printf("Error: invalid -profile value %q (expected cpu or mem)\n", *profile)
+		os.Exit(exitError)
+	}
+	if *bench < 0 {
+		fmt.Printf("Error: invalid -bench value %d (expected 1 or more)\n", *bench)
+		os.Exit(exitError)
+	}
+	if *maxPathLength < 0 || *maxNameLength < 0 {
+		fmt.Println("Error: -max-path-length and -max-name-length must be positive")
+		os.Exit(exitError)
+	}
+
+	onlyTypes, badType := parseIssueTypes(onlyTypeNames)
+	if badType != "" {
+		fmt.Printf("Error: invalid -only-type value %q (expected one of %s)\n", badType, issueTypeList())
+		os.Exit(exitError)
+	}
+
+	if *zipBundle && !*bundle {
+		fmt.Println("Error: -zip requires -bundle")
+		os.Exit(exitError)
+	}
+	if *streamCSV && *collapseProblematic {
+		fmt.Println("Error: -stream-csv cannot be combined with -collapse-problematic")
+		os.Exit(exitError)
+	}
+	if *maxIssuesPerFolder < 0 {
+		fmt.Printf("Error: invalid -max-issues-per-folder value %d (expected 0 or more)\n", *maxIssuesPerFolder)
+		os.Exit(exitError)
+	}
+	if *streamCSV && *maxIssuesPerFolder > 0 {
+		fmt.Println("Error: -stream-csv cannot be combined with -max-issues-per-folder")
+		os.Exit(exitError)
+	}
+	// Report formats to write, in order. -format replaces the older
+	// per-format flags, which are kept in step with it below.
+	formatFlagSet := false
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "json", "csv", "html", "xml":
+			formatFlagSet = true
+		}
+	})
+	if *formatList != "" && formatFlagSet {
+		fmt.Println("Error: -format cannot be combined with -json, -csv, -html or -xml")
+		os.Exit(exitError)
+	}
+	formats, err := reportFormats(*formatList, *outputJSON, *outputCSV, *outputHTML, *outputXML)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	*outputJSON = containsFormat(formats, reporter.FormatJSON)
+	*outputCSV = containsFormat(formats, reporter.FormatCSV)
+	*outputHTML = containsFormat(formats, reporter.FormatHTML)
+	*outputXML = containsFormat(formats, reporter.FormatXML)
+
+	if *liveHTML && !*outputHTML {
+		fmt.Println("Error: -live-html cannot be combined with -html=false")
+		os.Exit(exitError)
+	}
+	if (*splitOnly || *splitEmpty) && !*splitBySeverity {
+		fmt.Println("Error: -split-only and -split-empty require -split-by-severity")
+		os.Exit(exitError)
+	}
+	if *splitOnly && (*streamCSV || *liveHTML) {
+		fmt.Println("Error: -split-only cannot be combined with -stream-csv or -live-html")
+		os.Exit(exitError)
+	}
+	switch *sortBy {
+	case reporter.SortSeverity, reporter.SortPath, reporter.SortSize, reporter.SortType:
+	default:
+		fmt.Printf("Error: invalid -sort value %q (expected severity, path, size or type)\n", *sortBy)
+		os.Exit(exitError)
+	}
+	if *streamCSV && *sortBy != reporter.SortSeverity {
+		fmt.Println("Error: -stream-csv writes issues in scan order and cannot be combined with -sort")
+		os.Exit(exitError)
+	}
+
+	switch *logLevel {
+	case "", "error", "info", "debug":
+	default:
+		fmt.Printf("Error: invalid -log-level value %q (expected error, info or debug)\n", *logLevel)
+		os.Exit(exitError)
+	}
+
+	switch *summaryFormat {
+	case "", reporter.SummaryPlain, reporter.SummarySlack, reporter.SummaryTeams:
+	default:
+		fmt.Printf("Error: invalid -summary-format value %q (expected plain, slack or teams)\n", *summaryFormat)
+		os.Exit(exitError)
+	}
+
+	if *explain != "" {
+		cfg, err := loadRuleConfig(*configFile, blockExts, allowExts)
+		if err != nil {
+			ui.ShowError("Failed to load config file", err)
+			os.Exit(exitError)
+		}
+		if !runner.Explain(cfg, *explain) {
+			os.Exit(exitError)
+		}
+		os.Exit(exitOK)
+	}
+
+	if *configDiff != "" {
+		exitOnError(runner.ConfigDiff(*configDiff))
+		os.Exit(exitOK)
+	}
+
+	if *validateName != "" && *validatePath != "" {
+		fmt.Println("Error: -validate-name cannot be combined with -validate-path")
+		os.Exit(exitError)
+	}
+	if *validateName != "" || *validatePath != "" {
+		cfg, err := loadRuleConfig(*configFile, blockExts, allowExts)
+		if err != nil {
+			ui.ShowError("Failed to load config file", err)
+			os.Exit(exitError)
+		}
+		cfg.Settings.PathLengthBasis = *encodingBasis
+		issues := runner.Validate(cfg, *destinationURL, *validateName, *validatePath, *asFolder)
+		os.Exit(issueExitCode(scan.Summarize(issues), *failOn, *strict))
+	}
+
+	// Initialize configuration
+	cfg := config.NewDefaultConfig()
+	if *configFile != "" {
+		loaded, err := config.LoadConfig(*configFile)
+		if err != nil {
+			ui.ShowError("Failed to load config file", err)
+			os.Exit(exitError)
+		}
+		cfg = loaded
+	}
+	cfg.Settings.PathLengthBasis = *encodingBasis
+	if *reportTitle != "" {
+		cfg.Settings.ReportSettings.ReportTitle = *reportTitle
+	}
+	if *companyName != "" {
+		cfg.Settings.ReportSettings.CompanyName = *companyName
+	}
+	if *projectName != "" {
+		cfg.Settings.ReportSettings.ProjectName = *projectName
+	}
+	if *noRemediation {
+		cfg.Settings.ReportSettings.IncludeRemediation = false
+	}
+	if *noTimestamp {
+		cfg.Settings.ReportSettings.IncludeTimestamp = false
+	}
+	if *pathWarnPercent > 0 {
+		cfg.Settings.PathWarningThresholdPercent = *pathWarnPercent
+	}
+	if *maxPathLength > 0 {
+		cfg.SPOLimits.MaxPathLength = *maxPathLength
+	}
+	if *maxNameLength > 0 {
+		cfg.SPOLimits.MaxFileNameLength = *maxNameLength
+	}
+	if *progressInterval != 0 {
+		cfg.Settings.ProgressUpdateInterval = *progressInterval
+	}
+	if sizeWarn.set {
+		if err := cfg.SetFileSizeWarnings(sizeWarn.tiers); err != nil {
+			fmt.Printf("Error: invalid -size-warn value: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+	if problematicMinSize > 0 {
+		cfg.Settings.ProblematicMinSizeBytes = int64(problematicMinSize)
+	}
+	if *folderNameWarn > 0 {
+		cfg.Settings.FolderNameWarningLength = *folderNameWarn
+	}
+	if *syncRoot != "" {
+		cfg.Settings.SyncRoot = *syncRoot
+		*checkSyncPaths = true
+	}
+	if *checkSyncPaths {
+		cfg.Settings.DefaultChecks["SyncPathLength"] = true
+	}
+	if *invalidChars != "" {
+		cfg.SetInvalidCharacters([]rune(*invalidChars))
+	}
+	replacement := cfg.Settings.NameReplacement
+	if nameReplacement != nil {
+		replacement = *nameReplacement
+	}
+	if err := cfg.SetNameReplacement(replacement); err != nil {
+		ui.ShowError("Invalid -name-replacement", err)
+		os.Exit(exitError)
+	}
+	if *lang != "" {
+		if err := cfg.SetLanguage(*lang); err != nil {
+			ui.ShowError("Invalid -lang", err)
+			os.Exit(exitError)
+		}
+	}
+	if *detectCaseConflicts {
+		cfg.Settings.DefaultChecks["CaseConflicts"] = true
+	}
+	if *detectConfusables {
+		cfg.Settings.DefaultChecks["ConfusableNames"] = true
+	}
+	if *checkLocks {
+		cfg.Settings.DefaultChecks["FileLocks"] = true
+	}
+	if *checkStreams {
+		cfg.Settings.DefaultChecks["AlternateStreams"] = true
+	}
+	if *checkShortNames {
+		cfg.Settings.DefaultChecks["ShortNames"] = true
+	}
+	if *sniff {
+		cfg.Settings.DefaultChecks["ExtensionMismatch"] = true
+	}
+	if *secretsOnly {
+		for name := range cfg.Settings.DefaultChecks {
+			cfg.Settings.DefaultChecks[name] = false
+		}
+		cfg.Settings.DefaultChecks["Secrets"] = true
+	}
+	cfg.BlockExtensions(blockExts)
+	cfg.AllowExtensions(allowExts)
+	if err := cfg.AcceptCategories(acceptCategories); err != nil {
+		ui.ShowError("Invalid -accept-category", err)
+		os.Exit(exitError)
+	}
+
+	if *dumpConfig {
+		if err := cfg.WriteJSON(os.Stdout); err != nil {
+			ui.ShowError("Failed to write config", err)
+			os.Exit(exitError)
+		}
+		os.Exit(exitOK)
+	}
+
+	if *bench > 0 {
+		exitOnError(runner.Bench(cfg, *bench, *workers, *profile, *outputDir))
+		os.Exit(exitOK)
+	}
+
+	// Load the earlier report whose issues are re-checked
+	var previous *models.ScanResult
+	if *verifyReport != "" {
+		if *pathsFrom != "" || *incremental {
+			fmt.Println("Error: -verify cannot be combined with -paths-from or -incremental")
+			os.Exit(exitError)
+		}
+		loaded, err := reporter.LoadJSON(*verifyReport)
+		if err != nil {
+			ui.ShowError("Failed to load report to verify", err)
+			os.Exit(exitError)
+		}
+		previous = loaded
 	}
 
 	pathValue := *scanPath
 	destinationValue := *destinationURL
+	if previous != nil {
+		if pathValue == "" {
+			pathValue = previous.ScanPath
+		}
+		if destinationValue == "" {
+			destinationValue = previous.DestinationURL
+		}
+	}
 	outputValue := *outputDir
 	useTUI := *useTUIFlag
 
@@ -58,17 +497,17 @@ func main() {
 		if !isTerminal {
 			fmt.Println("Error: -path is required")
 			flag.Usage()
-			os.Exit(1)
+			os.Exit(exitError)
 		}
 
-		configResult, err := ui.RunConfigTUI("", destinationValue, outputValue)
+		configResult, err := ui.RunConfigTUI("", destinationValue, outputValue, cfg.Settings.DefaultChecks)
 		if err != nil {
 			ui.ShowError("Failed to start interactive setup", err)
-			os.Exit(1)
+			os.Exit(exitError)
 		}
 		if configResult.Canceled {
 			ui.ShowInfo("Scan canceled by user")
-			os.Exit(1)
+			os.Exit(exitInterrupted)
 		}
 
 		pathValue = configResult.Path
@@ -78,6 +517,9 @@ func main() {
 		if configResult.Output != "" {
 			outputValue = configResult.Output
 		}
+		if configResult.Checks != nil {
+			cfg.Settings.DefaultChecks = configResult.Checks
+		}
 		useTUI = true
 	}
 
@@ -85,20 +527,38 @@ func main() {
 	if pathValue == "" {
 		fmt.Println("Error: -path is required")
 		flag.Usage()
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 
 	// Validate path exists
 	if _, err := os.Stat(pathValue); os.IsNotExist(err) {
 		ui.ShowError(fmt.Sprintf("Path does not exist: %s", pathValue), nil)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 
 	// Get absolute path
 	absPath, err := filepath.Abs(pathValue)
 	if err != nil {
 		ui.ShowError("Failed to resolve absolute path", err)
-		os.Exit(1)
+		os.Exit(exitError)
+	}
+
+	// Read the explicit path list for targeted rescans
+	var pathList []string
+	if *pathsFrom != "" {
+		pathList, err = runner.ReadPathList(*pathsFrom)
+		if err != nil {
+			ui.ShowError("Failed to read path list", err)
+			os.Exit(exitError)
+		}
+	}
+
+	// A wrong destination silently skews every path length
+	if warnings := validator.CheckDestination(destinationValue); len(warnings) > 0 {
+		if !confirmDestination(destinationValue, warnings) {
+			ui.ShowInfo("Scan canceled by user")
+			os.Exit(exitInterrupted)
+		}
 	}
 
 	// Show banner
@@ -107,199 +567,196 @@ func main() {
 		fmt.Printf("\n")
 	}
 
-	// Initialize configuration
-	cfg := config.NewDefaultConfig()
+	// The destination is often named after the scan root, which the scan
+	// does not check as an item; the summary repeats this
+	if !useTUI {
+		ui.ShowRootIssues(validator.NewValidator(cfg, destinationValue, cfg.Settings.DefaultChecks).ValidateRoot(absPath))
+	}
 
-	scnr := scanner.NewScanner(absPath, cfg.Settings.DefaultExcludeFolders, *maxItems)
-
-	// Create validator
-	v := validator.NewValidator(cfg, destinationValue, cfg.Settings.DefaultChecks)
-
-	// Setup context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Handle interrupt signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		fmt.Println("\n\n⚠️  Scan interrupted by user. Generating partial results...")
-		cancel()
-	}()
-
-	var (
-		program     *tea.Program
-		programDone chan struct{}
-	)
-
-	if useTUI {
-		program = tea.NewProgram(ui.NewScanModel(absPath, destinationValue), tea.WithAltScreen())
-		programDone = make(chan struct{})
-		go func() {
-			_, _ = program.Run()
-			close(programDone)
-		}()
-		go func() {
-			<-programDone
-			cancel()
-		}()
-	}
-
-	// Start scan
-	startTime := time.Now()
-	itemsChan, progressChan, errChan := scnr.Scan(ctx)
-
-	// Process items and show progress
-	var (
-		totalItems   int64
-		totalFiles   int64
-		totalFolders int64
-		totalSize    int64
-		issues       []models.Issue
-	)
-
-	// Progress update ticker
-	progressTicker := time.NewTicker(500 * time.Millisecond)
-	defer progressTicker.Stop()
-
-	var lastProgress *models.ScanProgress
-
-	done := false
-	for !done {
-		select {
-		case item, ok := <-itemsChan:
-			if !ok {
-				done = true
-				break
-			}
-
-			// Count items
-			totalItems++
-			if item.IsDir {
-				totalFolders++
-			} else {
-				totalFiles++
-				totalSize += item.Size
-			}
-
-			// Validate item
-			itemIssues := v.ValidateItem(item)
-			issues = append(issues, itemIssues...)
-
-		case progress, ok := <-progressChan:
-			if ok {
-				lastProgress = progress
-				if lastProgress != nil {
-					lastProgress.IssuesFound = len(issues)
-				}
-			}
-
-		case <-progressTicker.C:
-			if lastProgress != nil {
-				if useTUI && program != nil {
-					program.Send(ui.ProgressMsg(lastProgress))
-				} else if !*noProgress {
-					ui.ShowStyledProgress(lastProgress, startTime)
-				}
-			}
-
-		case err := <-errChan:
-			if err != nil && err != context.Canceled {
-				if useTUI && program != nil {
-					program.Send(ui.ErrorMsg(err))
-				} else {
-					ui.ShowError("Scan error", err)
-				}
-				cancel()
-			}
-		}
+	// Log to stderr; debug output would interleave with the progress
+	// display, so progress is turned off at that level
+	level := slog.LevelError
+	switch {
+	case *logLevel == "debug":
+		level = slog.LevelDebug
+		*noProgress = true
+		useTUI = false
+	case *logLevel == "info", *logLevel == "" && cfg.Settings.ConsoleSettings.VerboseOutput:
+		level = slog.LevelInfo
 	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
 
-	// Clear progress display
-	if useTUI && program != nil {
-		program.Send(ui.DoneMsg{})
-		<-programDone
-	} else if !*noProgress {
-		ui.ClearStyledProgress()
+	// Scan everything, including recycle bins and trash folders
+	var excludeFolders []string
+	if *noDefaultExcludes {
+		excludeFolders = []string{}
 	}
 
-	// Calculate duration
-	endTime := time.Now()
-	duration := endTime.Sub(startTime)
+	outcome, err := runner.Run(runner.Options{
+		Path:           absPath,
+		Destination:    destinationValue,
+		Config:         cfg,
+		Paths:          pathList,
+		ExcludeFolders: excludeFolders,
+		MaxItems:       *maxItems,
+		Workers:        *workers,
+		ReadRetries:    readRetryCount,
+		RetryBackoff:   *retryBackoff,
+		SniffMaxSize:   int64(sniffMaxSize),
+		Filter: scan.FileFilter{
+			MinSize:        int64(minSize),
+			MaxSize:        int64(maxSize),
+			ModifiedBefore: time.Time(modifiedBefore),
+			ModifiedAfter:  time.Time(modifiedAfter),
+		},
+		CountFiltered:       *countFiltered,
+		MaxMemory:           int64(maxMemory),
+		IgnoreFile:          *ignoreFile,
+		Logger:              logger,
+		Incremental:         *incremental,
+		IndexFile:           *indexFile,
+		Verify:              previous,
+		VerifyReport:        *verifyReport,
+		FailFast:            *failFast,
+		Profile:             *profile,
+		Output:              outputValue,
+		FilenameTemplate:    filenameTemplate,
+		Formats:             formats,
+		Sort:                *sortBy,
+		SplitBySeverity:     *splitBySeverity,
+		SplitOnly:           *splitOnly,
+		SplitEmpty:          *splitEmpty,
+		StreamCSV:           *streamCSV,
+		LiveHTML:            *liveHTML,
+		Manifest:            *outputManifest,
+		StatusFile:          *statusFile,
+		MetricsFile:         *metricsFile,
+		CollapseProblematic: *collapseProblematic,
+		CollapseThreshold:   *collapseThreshold,
+		CollapseList:        *collapseList,
+		MaxIssuesPerFolder:  *maxIssuesPerFolder,
+		OnlyTypes:           onlyTypes,
+		FolderStats:         *folderStats,
+		FolderStatsDepth:    *folderStatsDepth,
+		RenameMap:           *renameMap,
+		Bundle:              *bundle,
+		Zip:                 *zipBundle,
+		SummaryFormat:       *summaryFormat,
+		GitHubAnnotations:   *githubAnnotations,
+		PostURL:             *postURL,
+		PostHeaders:         postHeaders.values(),
+		PostTimeout:         *postTimeout,
+		UseTUI:              useTUI,
+		NoProgress:          *noProgress,
+		Version:             version,
+		Commit:              commit,
+	})
+	exitOnError(err)
 
-	// Build summary
-	summary := models.IssueSummary{
-		ByType:     make(map[models.IssueType]int),
-		BySeverity: make(map[models.Severity]int),
+	// Exit with appropriate code
+	switch {
+	case outcome.ReportPanicked:
+		// The panic has been logged and partial results saved
+		os.Exit(exitPanic)
+	case outcome.Interrupted:
+		ui.ShowWarning(fmt.Sprintf("Scan interrupted; results are partial. Exit code: %d", exitInterrupted))
+		os.Exit(exitInterrupted)
+	case outcome.ScanPanicked:
+		ui.ShowError(fmt.Sprintf("Scan stopped by an internal error; results are partial. Exit code: %d", exitPanic), nil)
+		os.Exit(exitPanic)
+	case outcome.ScanFailed || outcome.ReportFailed:
+		ui.ShowError(fmt.Sprintf("Scan did not complete cleanly. Exit code: %d", exitError), nil)
+		os.Exit(exitError)
+	case outcome.PostFailed:
+		ui.ShowError(fmt.Sprintf("Scan completed but results were not posted. Exit code: %d", exitPostFailed), nil)
+		os.Exit(exitPostFailed)
+	case outcome.FailedFast:
+		ui.ShowWarning(fmt.Sprintf("Scan stopped at the first Critical issue; results are partial. Exit code: %d", exitCritical))
+		os.Exit(exitCritical)
 	}
 
-	for _, issue := range issues {
-		summary.ByType[issue.Type]++
-		summary.BySeverity[issue.Severity]++
+	code := issueExitCode(outcome.Summary, *failOn, *strict)
+	switch {
+	case code == exitCritical && outcome.Summary.BySeverity[models.SeverityCritical] == 0:
+		ui.ShowWarning(fmt.Sprintf("Warnings found; -strict treats them as critical. Exit code: %d", code))
+	case code == exitCritical:
+		ui.ShowWarning(fmt.Sprintf("Critical issues found. Exit code: %d", code))
+	case code == exitWarnings:
+		ui.ShowInfo(fmt.Sprintf("Warnings found. Exit code: %d", code))
+	default:
+		ui.ShowSuccess("Scan completed successfully!")
 	}
+	os.Exit(code)
+}
 
-	// Create scan result
-	result := &models.ScanResult{
-		ScanPath:       absPath,
-		DestinationURL: destinationValue,
-		StartTime:      startTime,
-		EndTime:        endTime,
-		Duration:       duration,
-		TotalItems:     totalItems,
-		TotalFiles:     totalFiles,
-		TotalFolders:   totalFolders,
-		TotalSize:      totalSize,
-		IssuesFound:    len(issues),
-		Issues:         issues,
-		Summary:        summary,
+// exitOnError shows a failure that stopped the run and exits with
+// exitError; it does nothing when err is nil
+func exitOnError(err error) {
+	if err == nil {
+		return
 	}
+	var runErr *runner.Error
+	if errors.As(err, &runErr) {
+		ui.ShowError(runErr.Title, runErr.Err)
+	} else {
+		ui.ShowError("Error", err)
+	}
+	os.Exit(exitError)
+}
 
-	// Show summary
-	ui.ShowStyledSummary(result)
-
-	// Generate reports
-	if *outputJSON || *outputCSV || *outputHTML {
-		fmt.Println("\nGenerating reports...")
-
-		// Ensure output directory exists
-		if err := os.MkdirAll(outputValue, 0755); err != nil {
-			ui.ShowError("Failed to create output directory", err)
-			os.Exit(1)
-		}
-
-		rep := reporter.NewReporter(outputValue)
-
-		if *outputJSON {
-			if err := rep.GenerateJSON(result, ""); err != nil {
-				ui.ShowError("Failed to generate JSON report", err)
-			}
-		}
-
-		if *outputCSV {
-			if err := rep.GenerateCSV(result, ""); err != nil {
-				ui.ShowError("Failed to generate CSV report", err)
-			}
-		}
+// issueExitCode maps the issue summary to an exit code, ignoring
+// severities below the -fail-on threshold. With strict, warnings give the
+// Critical exit code.
+func issueExitCode(summary models.IssueSummary, failOn string, strict bool) int {
+	if failOn == "none" {
+		return exitOK
+	}
+	if summary.BySeverity[models.SeverityCritical] > 0 {
+		return exitCritical
+	}
+	if strict && summary.BySeverity[models.SeverityWarning] > 0 {
+		return exitCritical
+	}
+	if failOn == "warning" && summary.BySeverity[models.SeverityWarning] > 0 {
+		return exitWarnings
+	}
+	return exitOK
+}
 
-		if *outputHTML {
-			if err := rep.GenerateHTML(result, ""); err != nil {
-				ui.ShowError("Failed to generate HTML report", err)
-			}
+// loadRuleConfig builds the rules a scan would use from the config file
+// and the -block-ext and -allow-ext flags
+func loadRuleConfig(configFile string, blockExts, allowExts []string) (*config.Config, error) {
+	cfg := config.NewDefaultConfig()
+	if configFile != "" {
+		loaded, err := config.LoadConfig(configFile)
+		if err != nil {
+			return nil, err
 		}
+		cfg = loaded
+	}
+	cfg.BlockExtensions(blockExts)
+	cfg.AllowExtensions(allowExts)
+	return cfg, nil
+}
 
-		fmt.Println()
+// confirmDestination shows the problems found with the destination URL
+// and, on a terminal, asks whether to scan anyway. Without a terminal the
+// scan continues so scheduled runs do not hang.
+func confirmDestination(destination string, warnings []string) bool {
+	ui.ShowWarning(fmt.Sprintf("The destination %q may not be a SharePoint library URL; path lengths may be wrong:", destination))
+	for _, warning := range warnings {
+		fmt.Printf("        - %s\n", warning)
 	}
 
-	// Exit with appropriate code
-	if summary.BySeverity[models.SeverityCritical] > 0 {
-		ui.ShowWarning("Critical issues found. Exit code: 2")
-		os.Exit(2)
-	} else if summary.BySeverity[models.SeverityWarning] > 0 {
-		ui.ShowInfo("Warnings found. Exit code: 1")
-		os.Exit(1)
+	if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		return true
 	}
 
-	ui.ShowSuccess("Scan completed successfully!")
-	os.Exit(0)
+	fmt.Print("\nContinue anyway? [y/N]: ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
 }
@@ -1,33 +1,130 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/i18n"
 )
 
 // Config holds all SharePoint Online limits and validation rules
 type Config struct {
-	SPOLimits          *SPOLimits
-	BlockedFileTypes   *BlockedFileTypes
-	ProblematicFiles   *ProblematicFiles
-	Settings           *Settings
+	SPOLimits        *SPOLimits
+	BlockedFileTypes *BlockedFileTypes
+	ProblematicFiles *ProblematicFiles
+	Settings         *Settings
+	CustomRules      []CustomRule
+
+	// Messages holds the issue text in Settings.Language
+	Messages *i18n.Catalog `json:"-"`
 }
 
+// DefaultSyncRoot stands in for the folder OneDrive syncs a library to.
+// The placeholders are counted as they are, so set the real folder for an
+// accurate estimate.
+const DefaultSyncRoot = `C:\Users\<user>\<tenant>`
+
 // SPOLimits defines SharePoint Online restrictions
 type SPOLimits struct {
-	MaxPathLength     int
-	MaxFileNameLength int
-	MaxFileSizeBytes  int64
-	InvalidCharacters []rune
-	InvalidCharsSet   map[rune]bool // For O(1) lookup
-	ReservedNames     []string
-	ReservedNamesSet  map[string]bool
-	BlockedPatterns   []string
-	BlockedPrefixes   struct {
-		File   []string
-		Folder []string
+	MaxPathLength            int
+	MaxFileNameLength        int
+	MaxFileSizeBytes         int64
+	InvalidCharacters        Runes
+	InvalidCharsSet          map[rune]bool `json:"-"` // For O(1) lookup
+	InvisibleCharacters      Runes
+	InvisibleCharsSet        map[rune]bool   `json:"-"`
+	ReservedNames            []string        // Device names, reserved with any extension
+	ReservedNamesSet         map[string]bool `json:"-"`
+	BlockedNames             []string        // Whole names, such as desktop.ini
+	BlockedNamesSet          map[string]bool `json:"-"`
+	BlockedPatterns          []string        // Blocked anywhere in a name
+	PrefixRules              []PrefixRule    // Blocked at the start of a name
+	RootLevelBlockedNames    []string        // Folder names blocked at the library root
+	RootLevelBlockedNamesSet map[string]bool `json:"-"`
+}
+
+// PrefixRule reports names that start with Prefix, such as folders
+// starting with ~. The ~$ rule for files marks Office owner files, which
+// are reported apart from the other prefixes.
+type PrefixRule struct {
+	Prefix    string
+	AppliesTo string // "files", "folders" or "both" (default)
+	Severity  string // "Critical", "Warning" (default) or "Info"
+	Message   string // Replaces the catalog message when set
+}
+
+// AppliesToItem reports whether the rule should be evaluated for a file or folder
+func (r *PrefixRule) AppliesToItem(isDir bool) bool {
+	switch r.AppliesTo {
+	case "files":
+		return !isDir
+	case "folders":
+		return isDir
+	default:
+		return true
+	}
+}
+
+// Runes is a list of characters. In a config file it can be written as a
+// string ("#%") or as an array of single-character strings or code points.
+type Runes []rune
+
+// MarshalJSON writes an array of single-character strings, with code
+// points for spaces and invisible characters so they can be read
+func (r Runes) MarshalJSON() ([]byte, error) {
+	items := make([]interface{}, 0, len(r))
+	for _, ch := range r {
+		if unicode.IsGraphic(ch) && !unicode.IsSpace(ch) {
+			items = append(items, string(ch))
+		} else {
+			items = append(items, int(ch))
+		}
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(items); err != nil {
+		return nil, err
 	}
-	RootLevelBlockedNames []string
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// UnmarshalJSON accepts a string or an array of strings and numbers
+func (r *Runes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*r = Runes(s)
+		return nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("expected a string or an array of characters")
+	}
+
+	runes := make(Runes, 0, len(items))
+	for _, item := range items {
+		var code rune
+		if err := json.Unmarshal(item, &code); err == nil {
+			runes = append(runes, code)
+			continue
+		}
+		if err := json.Unmarshal(item, &s); err != nil || utf8.RuneCountInString(s) != 1 {
+			return fmt.Errorf("invalid character %s: expected a single character or a code point", item)
+		}
+		runes = append(runes, []rune(s)[0])
+	}
+
+	*r = runes
+	return nil
 }
 
 // BlockedFileTypes defines file types that are blocked for security
@@ -36,6 +133,7 @@ type BlockedFileTypes struct {
 	Scripts     FileTypeRule
 	System      FileTypeRule
 	Dangerous   FileTypeRule
+	Custom      FileTypeRule
 	NoSync      FilePatternRule
 	Temporary   FilePatternRule
 }
@@ -48,19 +146,20 @@ type ProblematicFiles struct {
 	EmailArchive   ProblematicFileSizeRule
 	LargeMedia     ProblematicFileSizeRule
 	Development    FolderPatternRule
+	VersionControl VersionControlRule
 	Secrets        FilePatternRule
 	LockFiles      FilePatternRule
 	Bluebeam       BluebeamRule
 	VirtualMachine ProblematicFileRule
 	Backup         ProblematicFileSizeRule
 	OneNote        ProblematicFileRule
-	Other          map[string]string
+	Other          map[string]OtherFileRule
 }
 
 // FileTypeRule defines a rule based on file extensions
 type FileTypeRule struct {
 	Extensions    []string
-	ExtensionsSet map[string]bool // For O(1) lookup
+	ExtensionsSet map[string]bool `json:"-"` // For O(1) lookup
 	Severity      string
 	Message       string
 }
@@ -68,8 +167,8 @@ type FileTypeRule struct {
 // FilePatternRule defines a rule based on file name patterns
 type FilePatternRule struct {
 	Patterns    []string
-	PatternsSet map[string]bool
-	Regexes     []*regexp.Regexp
+	PatternsSet map[string]bool  `json:"-"`
+	Regexes     []*regexp.Regexp `json:"-"`
 	Severity    string
 	Message     string
 }
@@ -77,7 +176,7 @@ type FilePatternRule struct {
 // ProblematicFileRule defines a problematic file type
 type ProblematicFileRule struct {
 	Extensions    []string
-	ExtensionsSet map[string]bool
+	ExtensionsSet map[string]bool `json:"-"`
 	Severity      string
 	Category      string
 	Message       string
@@ -90,6 +189,44 @@ type ProblematicFileSizeRule struct {
 	SizeThresholdBytes int64
 }
 
+// OtherFileRule is the advisory for one extension in ProblematicFiles.Other.
+// Files are reported as Info, or at SizeSeverity when larger than
+// SizeThresholdBytes. In a config file a rule without a threshold can be
+// written as just its message.
+type OtherFileRule struct {
+	Message            string
+	SizeThresholdBytes int64  // 0 never escalates
+	SizeSeverity       string // "Critical", "Warning" or "Info"; "" is Warning
+}
+
+// MarshalJSON writes a rule without a threshold as just its message
+func (r OtherFileRule) MarshalJSON() ([]byte, error) {
+	if r.SizeThresholdBytes == 0 && r.SizeSeverity == "" {
+		return json.Marshal(r.Message)
+	}
+	type plain OtherFileRule
+	return json.Marshal(plain(r))
+}
+
+// UnmarshalJSON accepts a message string or a rule object
+func (r *OtherFileRule) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		*r = OtherFileRule{Message: message}
+		return nil
+	}
+
+	type plain OtherFileRule
+	var rule plain
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rule); err != nil {
+		return fmt.Errorf("expected a message or a rule object: %w", err)
+	}
+	*r = OtherFileRule(rule)
+	return nil
+}
+
 // FolderPatternRule defines folder patterns to detect
 type FolderPatternRule struct {
 	Patterns []string
@@ -98,6 +235,16 @@ type FolderPatternRule struct {
 	Message  string
 }
 
+// VersionControlRule defines the metadata folders, such as .git and .svn,
+// that mark a version control working copy
+type VersionControlRule struct {
+	Folders    []string
+	FoldersSet map[string]bool `json:"-"` // Upper-cased names
+	Severity   string
+	Category   string
+	Message    string
+}
+
 // BluebeamRule defines special Bluebeam PDF handling
 type BluebeamRule struct {
 	Extensions          []string
@@ -108,21 +255,75 @@ type BluebeamRule struct {
 	OnlyWarnOnLongPaths bool
 }
 
+// CustomRule defines a user-supplied naming rule matched against item names
+type CustomRule struct {
+	Name        string
+	Pattern     string
+	Severity    string
+	Message     string
+	AppliesTo   string         // "files", "folders" or "both" (default)
+	AutoSkipped bool           // Matches are skipped by migration tools and need no action
+	Regex       *regexp.Regexp `json:"-"`
+}
+
+// AppliesToItem reports whether the rule should be evaluated for a file or folder
+func (r *CustomRule) AppliesToItem(isDir bool) bool {
+	switch r.AppliesTo {
+	case "files":
+		return !isDir
+	case "folders":
+		return isDir
+	default:
+		return true
+	}
+}
+
 // Settings holds scanner configuration
 type Settings struct {
 	PathWarningThresholdPercent int
+	FolderNameWarningLength     int    // Warn on folder names at least this long; 0 turns it off
+	PathLengthBasis             string // "decoded" (SharePoint's formula) or "encoded"
+	NameReplacement             string // Replaces invalid characters in suggested names
+	Language                    string // "en" or the path of a message catalog file
+	SyncRoot                    string // Local folder the library syncs to, for the SyncPathLength check
 	DefaultOutputFormats        []string
 	DefaultChecks               map[string]bool
-	FileSizeWarnings            struct {
-		Large     int64
-		VeryLarge int64
-		Huge      int64
-	}
-	DefaultExcludeFolders   []string
-	MaxItemsToScan          int64
-	ProgressUpdateInterval  int
-	ReportSettings          ReportSettings
-	ConsoleSettings         ConsoleSettings
+
+	// AcceptedCategories lists problematic-file categories, by name (for
+	// example "Backup") or by category ("Backup/Archive"), whose Warning
+	// and Info issues are suppressed. Critical issues are still reported.
+	AcceptedCategories  []string
+	AcceptedCategorySet map[string]bool `json:"-"` // Keyed by category
+
+	// AutoSkippedCodes lists the issue codes, such as "SPO-CHAR-008", of
+	// conditions that migration tools skip on their own. Their issues
+	// are still reported, but apart from those that need action.
+	AutoSkippedCodes []string
+	AutoSkippedSet   map[string]bool `json:"-"` // Upper-cased codes
+
+	// ProblematicMinSizeBytes is the size a file must exceed to be
+	// reported as a problematic file type; 0 reports every size. Secrets
+	// are reported regardless.
+	ProblematicMinSizeBytes int64
+
+	// FileSizeWarnings are the tiers below the upload limit at which large
+	// files are reported, sorted largest first by SetFileSizeWarnings
+	FileSizeWarnings       []FileSizeTier
+	DefaultExcludeFolders  []string
+	MaxItemsToScan         int64
+	ProgressUpdateInterval int // Milliseconds between progress updates
+	ReportSettings         ReportSettings
+	ConsoleSettings        ConsoleSettings
+}
+
+// MinProgressUpdateInterval is the shortest progress update interval, in
+// milliseconds. Redrawing more often costs more than it shows.
+const MinProgressUpdateInterval = 50
+
+// FileSizeTier reports files larger than Bytes at Severity
+type FileSizeTier struct {
+	Bytes    int64
+	Severity string // "Critical", "Warning" or "Info"
 }
 
 // ReportSettings controls report generation
@@ -134,6 +335,36 @@ type ReportSettings struct {
 	IncludeTimestamp   bool
 	CompanyName        string
 	ProjectName        string
+
+	// ReportTitle heads the HTML report; "" uses the generic title
+	ReportTitle string
+
+	// CollapseProblematicThreshold is the number of problematic-file issues
+	// in one category at which -collapse-problematic folds them into a
+	// single summary issue
+	CollapseProblematicThreshold int
+
+	// TopOffenders is the number of entries in each "Top Offenders"
+	// ranking; 0 leaves the section out
+	TopOffenders int
+
+	// ExtensionBreakdownRows is the number of extensions listed in the
+	// extension breakdown before the rest are grouped as "other"
+	ExtensionBreakdownRows int
+
+	// ReadinessWeights sets how much each severity lowers the readiness
+	// score
+	ReadinessWeights ReadinessWeights
+}
+
+// ReadinessWeights is the penalty for each severity in the readiness
+// score. The score is 100 × (1 − Σ weight × fraction of items whose worst
+// issue has that severity), clamped to 0–100, so a weight of 1 means a
+// scan where every item has that severity scores 0.
+type ReadinessWeights struct {
+	Critical float64
+	Warning  float64
+	Info     float64
 }
 
 // ConsoleSettings controls console output
@@ -146,17 +377,57 @@ type ConsoleSettings struct {
 
 // NewDefaultConfig creates a new Config with SharePoint Online defaults
 func NewDefaultConfig() *Config {
-	cfg := &Config{
+	cfg := newConfig()
+
+	// Build lookup sets for O(1) performance
+	if err := cfg.buildLookupSets(); err != nil {
+		panic("invalid default config: " + err.Error())
+	}
+
+	return cfg
+}
+
+// LoadConfig reads a JSON config file and applies it on top of the
+// SharePoint Online defaults. Fields missing from the file keep their
+// default values. Invalid rules are reported here rather than at scan time.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg := newConfig()
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if err := cfg.buildLookupSets(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// WriteJSON writes the config in the format LoadConfig reads. Lookup sets,
+// compiled rules and the message catalog are left out; they are rebuilt
+// when the file is loaded.
+func (c *Config) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c)
+}
+
+func newConfig() *Config {
+	return &Config{
 		SPOLimits:        newSPOLimits(),
 		BlockedFileTypes: newBlockedFileTypes(),
 		ProblematicFiles: newProblematicFiles(),
 		Settings:         newDefaultSettings(),
 	}
-
-	// Build lookup sets for O(1) performance
-	cfg.buildLookupSets()
-
-	return cfg
 }
 
 func newSPOLimits() *SPOLimits {
@@ -165,13 +436,30 @@ func newSPOLimits() *SPOLimits {
 		MaxFileNameLength: 255,
 		MaxFileSizeBytes:  268435456000, // 250 GB
 		InvalidCharacters: []rune{'"', '*', ':', '<', '>', '?', '/', '\\', '|'},
+		InvisibleCharacters: []rune{
+			'\u00A0', // No-break space
+			'\u00AD', // Soft hyphen
+			'\u200B', // Zero-width space
+			'\u200C', // Zero-width non-joiner
+			'\u200D', // Zero-width joiner
+			'\u200E', // Left-to-right mark
+			'\u200F', // Right-to-left mark
+			'\u2060', // Word joiner
+			'\uFEFF', // Zero-width no-break space (BOM)
+			'\t',
+		},
 		ReservedNames: []string{
-			".lock", "CON", "PRN", "AUX", "NUL",
+			"CON", "PRN", "AUX", "NUL",
 			"COM0", "COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
 			"LPT0", "LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
-			"desktop.ini", "_vti_",
 		},
+		BlockedNames:    []string{".lock", "desktop.ini"},
 		BlockedPatterns: []string{"_vti_"},
+		PrefixRules: []PrefixRule{
+			{Prefix: "~$", AppliesTo: "files", Severity: "Info"},
+			{Prefix: "~", AppliesTo: "folders", Severity: "Warning"},
+			{Prefix: "..", AppliesTo: "both", Severity: "Warning"},
+		},
 		RootLevelBlockedNames: []string{"forms"},
 	}
 }
@@ -209,6 +497,11 @@ func newBlockedFileTypes() *BlockedFileTypes {
 			Severity: "Warning",
 			Message:  "This file type may be blocked by SharePoint for security reasons.",
 		},
+		Custom: FileTypeRule{
+			Extensions: []string{},
+			Severity:   "Warning",
+			Message:    "This file type has been blocked for this scan.",
+		},
 		NoSync: FilePatternRule{
 			Patterns: []string{"desktop.ini", ".ds_store", "thumbs.db", ".spotlight-*", ".trashes", ".fseventsd"},
 			Severity: "Info",
@@ -300,6 +593,12 @@ func newProblematicFiles() *ProblematicFiles {
 			Category: "Development",
 			Message:  "Development folders contain many small files that can exceed sync limits (100K files). Exclude from migration.",
 		},
+		VersionControl: VersionControlRule{
+			Folders:  []string{".git", ".svn", ".hg", ".bzr", "CVS"},
+			Severity: "Warning",
+			Category: "Version Control",
+			Message:  "Folder is a version control working copy. Its metadata holds many small files that do not belong in SharePoint.",
+		},
 		Secrets: FilePatternRule{
 			Patterns: []string{
 				".env", ".env.*", "credentials.json", "secrets.json", "secrets.yaml", "secrets.yml",
@@ -353,18 +652,18 @@ func newProblematicFiles() *ProblematicFiles {
 			Category:   "OneNote",
 			Message:    "OneNote section files should be migrated to OneNote Online notebooks instead of raw file migration.",
 		},
-		Other: map[string]string{
-			".lnk":     "Windows shortcuts - paths may break after migration",
-			".url":     "Internet shortcuts - generally work but verify links",
-			".gdoc":    "Google Docs link - just a link file, no actual content",
-			".gsheet":  "Google Sheets link - just a link file, no actual content",
-			".gslides": "Google Slides link - just a link file, no actual content",
-			".numbers": "Apple Numbers - no preview or collaboration in SharePoint",
-			".pages":   "Apple Pages - no preview or collaboration in SharePoint",
-			".key":     "Apple Keynote - no preview or collaboration in SharePoint",
-			".vsdx":    "Visio - limited web viewing, requires Visio license",
-			".mpp":     "MS Project - no web editing, requires Project license",
-			".pub":     "Publisher - no web editing or preview",
+		Other: map[string]OtherFileRule{
+			".lnk":     {Message: "Windows shortcuts - paths may break after migration"},
+			".url":     {Message: "Internet shortcuts - generally work but verify links"},
+			".gdoc":    {Message: "Google Docs link - just a link file, no actual content"},
+			".gsheet":  {Message: "Google Sheets link - just a link file, no actual content"},
+			".gslides": {Message: "Google Slides link - just a link file, no actual content"},
+			".numbers": {Message: "Apple Numbers - no preview or collaboration in SharePoint", SizeThresholdBytes: 104857600}, // 100 MB
+			".pages":   {Message: "Apple Pages - no preview or collaboration in SharePoint", SizeThresholdBytes: 104857600},   // 100 MB
+			".key":     {Message: "Apple Keynote - no preview or collaboration in SharePoint", SizeThresholdBytes: 104857600}, // 100 MB
+			".vsdx":    {Message: "Visio - limited web viewing, requires Visio license", SizeThresholdBytes: 104857600},       // 100 MB
+			".mpp":     {Message: "MS Project - no web editing, requires Project license", SizeThresholdBytes: 104857600},     // 100 MB
+			".pub":     {Message: "Publisher - no web editing or preview", SizeThresholdBytes: 104857600},                     // 100 MB
 		},
 	}
 }
@@ -372,6 +671,9 @@ func newProblematicFiles() *ProblematicFiles {
 func newDefaultSettings() *Settings {
 	s := &Settings{
 		PathWarningThresholdPercent: 80,
+		PathLengthBasis:             "decoded",
+		NameReplacement:             "_",
+		SyncRoot:                    DefaultSyncRoot,
 		DefaultOutputFormats:        []string{"HTML", "CSV"},
 		DefaultChecks: map[string]bool{
 			"PathLength":        true,
@@ -382,16 +684,48 @@ func newDefaultSettings() *Settings {
 			"FileSize":          true,
 			"NameConflicts":     true,
 			"HiddenFiles":       true,
+			"CustomRules":       true,
+			"CaseConflicts":     false,
+			"FileLocks":         false,
+			"ReparsePoints":     true,
+			"ExtensionMismatch": false,
+			"AlternateStreams":  false,
+			"EmptyFolders":      true,
+			"SyncPathLength":    false,
+			"ConfusableNames":   false,
+			"DoubleExtensions":  true,
+			"Secrets":           true,
+			"VersionControl":    true,
+			"ShortNames":        false,
+		},
+		FileSizeWarnings: []FileSizeTier{
+			{Bytes: 16106127360, Severity: "Warning"}, // 15 GB
+			{Bytes: 5368709120, Severity: "Info"},     // 5 GB
+		},
+		AutoSkippedCodes: []string{
+			"SPO-CHAR-008",   // Office owner file of an open document
+			"SPO-CHAR-009",   // Office owner file without a document
+			"SPO-HIDDEN-002", // System file such as Thumbs.db
+			"SPO-STREAM-001", // Mark of the web, dropped on upload
 		},
 		DefaultExcludeFolders:  []string{"$RECYCLE.BIN", "System Volume Information", "RECYCLER", ".Trash-*"},
 		MaxItemsToScan:         0,
-		ProgressUpdateInterval: 100,
+		ProgressUpdateInterval: 500,
 		ReportSettings: ReportSettings{
 			IncludeAllItems:    false,
 			MaxIssuesInSummary: 1000,
 			GroupByFolder:      true,
 			IncludeRemediation: true,
 			IncludeTimestamp:   true,
+
+			CollapseProblematicThreshold: 100,
+			TopOffenders:                 10,
+			ExtensionBreakdownRows:       15,
+			ReadinessWeights: ReadinessWeights{
+				Critical: 1,
+				Warning:  0.4,
+				Info:     0.05,
+			},
 		},
 		ConsoleSettings: ConsoleSettings{
 			UseColors:       true,
@@ -401,34 +735,38 @@ func newDefaultSettings() *Settings {
 		},
 	}
 
-	s.FileSizeWarnings.Large = 1073741824      // 1 GB
-	s.FileSizeWarnings.VeryLarge = 5368709120  // 5 GB
-	s.FileSizeWarnings.Huge = 15728640000      // ~15 GB
-
 	return s
 }
 
-// buildLookupSets creates hash sets for O(1) lookups
-func (c *Config) buildLookupSets() {
+// buildLookupSets creates hash sets for O(1) lookups and compiles custom rules
+func (c *Config) buildLookupSets() error {
 	// SPO Limits
 	c.SPOLimits.InvalidCharsSet = make(map[rune]bool)
 	for _, ch := range c.SPOLimits.InvalidCharacters {
 		c.SPOLimits.InvalidCharsSet[ch] = true
 	}
 
-	c.SPOLimits.ReservedNamesSet = make(map[string]bool)
-	for _, name := range c.SPOLimits.ReservedNames {
-		c.SPOLimits.ReservedNamesSet[strings.ToUpper(name)] = true
+	c.SPOLimits.InvisibleCharsSet = make(map[rune]bool)
+	for _, ch := range c.SPOLimits.InvisibleCharacters {
+		c.SPOLimits.InvisibleCharsSet[ch] = true
 	}
 
-	c.SPOLimits.BlockedPrefixes.File = []string{"~$"}
-	c.SPOLimits.BlockedPrefixes.Folder = []string{"~"}
+	c.SPOLimits.ReservedNamesSet = makeNameSet(c.SPOLimits.ReservedNames)
+	c.SPOLimits.BlockedNamesSet = makeNameSet(c.SPOLimits.BlockedNames)
+	c.SPOLimits.RootLevelBlockedNamesSet = makeNameSet(c.SPOLimits.RootLevelBlockedNames)
+
+	for i := range c.SPOLimits.PrefixRules {
+		if err := c.SPOLimits.PrefixRules[i].compile(); err != nil {
+			return err
+		}
+	}
 
 	// Blocked file types
 	c.BlockedFileTypes.Executables.ExtensionsSet = makeExtSet(c.BlockedFileTypes.Executables.Extensions)
 	c.BlockedFileTypes.Scripts.ExtensionsSet = makeExtSet(c.BlockedFileTypes.Scripts.Extensions)
 	c.BlockedFileTypes.System.ExtensionsSet = makeExtSet(c.BlockedFileTypes.System.Extensions)
 	c.BlockedFileTypes.Dangerous.ExtensionsSet = makeExtSet(c.BlockedFileTypes.Dangerous.Extensions)
+	c.BlockedFileTypes.Custom.ExtensionsSet = makeExtSet(c.BlockedFileTypes.Custom.Extensions)
 
 	c.BlockedFileTypes.NoSync.PatternsSet = makePatternSet(c.BlockedFileTypes.NoSync.Patterns)
 	c.BlockedFileTypes.Temporary.PatternsSet = makePatternSet(c.BlockedFileTypes.Temporary.Patterns)
@@ -443,18 +781,349 @@ func (c *Config) buildLookupSets() {
 	c.ProblematicFiles.Backup.ExtensionsSet = makeExtSet(c.ProblematicFiles.Backup.Extensions)
 	c.ProblematicFiles.OneNote.ExtensionsSet = makeExtSet(c.ProblematicFiles.OneNote.Extensions)
 
+	other := make(map[string]OtherFileRule, len(c.ProblematicFiles.Other))
+	for ext, rule := range c.ProblematicFiles.Other {
+		if rule.SizeThresholdBytes < 0 {
+			return fmt.Errorf("problematicFiles.other %s: sizeThresholdBytes must not be negative", ext)
+		}
+		if rule.SizeSeverity != "" {
+			severity, ok := canonicalSeverity(rule.SizeSeverity)
+			if !ok {
+				return fmt.Errorf("problematicFiles.other %s: invalid sizeSeverity %q (expected Critical, Warning or Info)", ext, rule.SizeSeverity)
+			}
+			rule.SizeSeverity = severity
+		}
+		if ext = NormalizeExtension(ext); ext != "" {
+			other[ext] = rule
+		}
+	}
+	c.ProblematicFiles.Other = other
+
 	c.ProblematicFiles.Secrets.PatternsSet = makePatternSet(c.ProblematicFiles.Secrets.Patterns)
 	c.ProblematicFiles.LockFiles.PatternsSet = makePatternSet(c.ProblematicFiles.LockFiles.Patterns)
+	c.ProblematicFiles.VersionControl.FoldersSet = makeNameSet(c.ProblematicFiles.VersionControl.Folders)
+
+	if err := c.checkNameReplacement(c.Settings.NameReplacement); err != nil {
+		return err
+	}
+
+	if err := c.SetLanguage(c.Settings.Language); err != nil {
+		return err
+	}
+
+	accepted := c.Settings.AcceptedCategories
+	c.Settings.AcceptedCategories = nil
+	c.Settings.AcceptedCategorySet = make(map[string]bool)
+	if err := c.AcceptCategories(accepted); err != nil {
+		return err
+	}
+
+	c.Settings.AutoSkippedSet = makeNameSet(c.Settings.AutoSkippedCodes)
+
+	if err := c.SetFileSizeWarnings(c.Settings.FileSizeWarnings); err != nil {
+		return err
+	}
+
+	if c.Settings.ProblematicMinSizeBytes < 0 {
+		return fmt.Errorf("problematicMinSizeBytes must not be negative")
+	}
+
+	if c.Settings.ProgressUpdateInterval < MinProgressUpdateInterval {
+		return fmt.Errorf("progressUpdateInterval must be at least %d (milliseconds)", MinProgressUpdateInterval)
+	}
+
+	weights := c.Settings.ReportSettings.ReadinessWeights
+	if weights.Critical < 0 || weights.Warning < 0 || weights.Info < 0 {
+		return fmt.Errorf("readinessWeights must not be negative")
+	}
+
+	// Custom rules
+	for i := range c.CustomRules {
+		if err := c.CustomRules[i].compile(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *CustomRule) compile() error {
+	name := r.Name
+	if name == "" {
+		name = r.Pattern
+	}
+
+	switch r.Severity {
+	case "Critical", "Warning", "Info":
+	case "":
+		r.Severity = "Warning"
+	default:
+		return fmt.Errorf("custom rule %q: invalid severity %q (expected Critical, Warning or Info)", name, r.Severity)
+	}
+
+	switch r.AppliesTo {
+	case "files", "folders", "both":
+	case "":
+		r.AppliesTo = "both"
+	default:
+		return fmt.Errorf("custom rule %q: invalid appliesTo %q (expected files, folders or both)", name, r.AppliesTo)
+	}
+
+	if r.Pattern == "" {
+		return fmt.Errorf("custom rule %q: pattern is required", name)
+	}
+
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("custom rule %q: invalid pattern: %w", name, err)
+	}
+	r.Regex = re
+
+	return nil
+}
+
+func (r *PrefixRule) compile() error {
+	if r.Prefix == "" {
+		return fmt.Errorf("prefix rule: prefix is required")
+	}
+
+	switch r.Severity {
+	case "Critical", "Warning", "Info":
+	case "":
+		r.Severity = "Warning"
+	default:
+		return fmt.Errorf("prefix rule %q: invalid severity %q (expected Critical, Warning or Info)", r.Prefix, r.Severity)
+	}
+
+	switch r.AppliesTo {
+	case "files", "folders", "both":
+	case "":
+		r.AppliesTo = "both"
+	default:
+		return fmt.Errorf("prefix rule %q: invalid appliesTo %q (expected files, folders or both)", r.Prefix, r.AppliesTo)
+	}
+
+	return nil
+}
+
+// SetInvalidCharacters replaces the characters that are not allowed in
+// names and rebuilds the lookup set
+func (c *Config) SetInvalidCharacters(chars []rune) {
+	c.SPOLimits.InvalidCharacters = chars
+	c.SPOLimits.InvalidCharsSet = make(map[rune]bool)
+	for _, ch := range chars {
+		c.SPOLimits.InvalidCharsSet[ch] = true
+	}
+}
+
+// SetNameReplacement sets the string that replaces invalid characters in
+// suggested names. It must not itself contain a character that is invalid
+// in SharePoint names.
+func (c *Config) SetNameReplacement(replacement string) error {
+	if err := c.checkNameReplacement(replacement); err != nil {
+		return err
+	}
+	c.Settings.NameReplacement = replacement
+	return nil
+}
+
+func (c *Config) checkNameReplacement(replacement string) error {
+	for _, ch := range replacement {
+		if c.SPOLimits.InvalidCharsSet[ch] || c.SPOLimits.InvisibleCharsSet[ch] {
+			return fmt.Errorf("name replacement %q contains a character that is not allowed in names", replacement)
+		}
+	}
+	return nil
+}
+
+// SetFileSizeWarnings replaces the file size tiers. Severities are
+// matched without regard to case, and the tiers are sorted largest first
+// so the validator can stop at the first one a file exceeds.
+func (c *Config) SetFileSizeWarnings(tiers []FileSizeTier) error {
+	sorted := make([]FileSizeTier, 0, len(tiers))
+	for _, tier := range tiers {
+		if tier.Bytes <= 0 {
+			return fmt.Errorf("file size tier must be larger than 0 bytes")
+		}
+		severity, ok := canonicalSeverity(tier.Severity)
+		if !ok {
+			return fmt.Errorf("file size tier: invalid severity %q (expected Critical, Warning or Info)", tier.Severity)
+		}
+		sorted = append(sorted, FileSizeTier{Bytes: tier.Bytes, Severity: severity})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Bytes > sorted[j].Bytes
+	})
+	c.Settings.FileSizeWarnings = sorted
+	return nil
+}
+
+// canonicalSeverity returns the spelling of a severity used in issues
+func canonicalSeverity(severity string) (string, bool) {
+	for _, s := range []string{"Critical", "Warning", "Info"} {
+		if strings.EqualFold(severity, s) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// SetLanguage loads the message catalog used for issue text: "en" for
+// the built-in English text, or the path of a catalog file
+func (c *Config) SetLanguage(lang string) error {
+	catalog, err := i18n.Load(lang)
+	if err != nil {
+		return err
+	}
+	c.Settings.Language = lang
+	c.Messages = catalog
+	return nil
+}
+
+// BlockExtensions adds extensions to the runtime blocked set. The
+// Custom extension list is kept in step so the config still says the
+// same thing when written out.
+func (c *Config) BlockExtensions(exts []string) {
+	custom := &c.BlockedFileTypes.Custom
+	for _, ext := range exts {
+		ext = NormalizeExtension(ext)
+		if ext == "" || custom.ExtensionsSet[ext] {
+			continue
+		}
+		custom.ExtensionsSet[ext] = true
+		custom.Extensions = append(custom.Extensions, ext)
+	}
+}
+
+// AllowExtensions removes extensions from every blocked and problematic
+// set, including the Custom set that BlockExtensions and the config file
+// add to, and from the matching extension lists
+func (c *Config) AllowExtensions(exts []string) {
+	type extRule struct {
+		list *[]string
+		set  map[string]bool
+	}
+	b, p := c.BlockedFileTypes, c.ProblematicFiles
+	rules := []extRule{
+		{&b.Custom.Extensions, b.Custom.ExtensionsSet},
+		{&b.Executables.Extensions, b.Executables.ExtensionsSet},
+		{&b.Scripts.Extensions, b.Scripts.ExtensionsSet},
+		{&b.System.Extensions, b.System.ExtensionsSet},
+		{&b.Dangerous.Extensions, b.Dangerous.ExtensionsSet},
+		{&p.CAD.Extensions, p.CAD.ExtensionsSet},
+		{&p.Adobe.Extensions, p.Adobe.ExtensionsSet},
+		{&p.Database.Extensions, p.Database.ExtensionsSet},
+		{&p.EmailArchive.Extensions, p.EmailArchive.ExtensionsSet},
+		{&p.LargeMedia.Extensions, p.LargeMedia.ExtensionsSet},
+		{&p.VirtualMachine.Extensions, p.VirtualMachine.ExtensionsSet},
+		{&p.Backup.Extensions, p.Backup.ExtensionsSet},
+		{&p.OneNote.Extensions, p.OneNote.ExtensionsSet},
+	}
+
+	for _, ext := range exts {
+		ext = NormalizeExtension(ext)
+		if ext == "" {
+			continue
+		}
+		for _, rule := range rules {
+			delete(rule.set, ext)
+			kept := (*rule.list)[:0]
+			for _, listed := range *rule.list {
+				if NormalizeExtension(listed) != ext {
+					kept = append(kept, listed)
+				}
+			}
+			*rule.list = kept
+		}
+		delete(c.ProblematicFiles.Other, ext)
+	}
+}
+
+// AcceptCategories suppresses Warning and Info issues from the named
+// problematic-file categories. Names match a ProblematicFiles field such
+// as "LargeMedia" or a category such as "Large Media", ignoring case.
+func (c *Config) AcceptCategories(names []string) error {
+	categories := c.problematicCategories()
+	for _, name := range names {
+		category, ok := categories[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return fmt.Errorf("unknown problematic-file category %q", name)
+		}
+		if !c.Settings.AcceptedCategorySet[category] {
+			c.Settings.AcceptedCategorySet[category] = true
+			c.Settings.AcceptedCategories = append(c.Settings.AcceptedCategories, category)
+		}
+	}
+	return nil
+}
+
+// problematicCategories maps lower-case ProblematicFiles field names and
+// category names to the category reported on issues
+func (c *Config) problematicCategories() map[string]string {
+	p := c.ProblematicFiles
+	fields := map[string]string{
+		"cad":            p.CAD.Category,
+		"adobe":          p.Adobe.Category,
+		"database":       p.Database.Category,
+		"emailarchive":   p.EmailArchive.Category,
+		"largemedia":     p.LargeMedia.Category,
+		"virtualmachine": p.VirtualMachine.Category,
+		"backup":         p.Backup.Category,
+		"onenote":        p.OneNote.Category,
+		"versioncontrol": p.VersionControl.Category,
+		"other":          "Other",
+		"secrets":        "Security",
+	}
+
+	categories := make(map[string]string)
+	for field, category := range fields {
+		if category == "" {
+			continue
+		}
+		categories[field] = category
+		categories[strings.ToLower(category)] = category
+	}
+	return categories
+}
+
+// NormalizeExtension converts user input such as "pdf", ".PDF" or "*.pdf" to ".pdf"
+func NormalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	ext = strings.TrimPrefix(ext, "*")
+	if ext == "" || ext == "." {
+		return ""
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
 }
 
+// makeExtSet builds a set of normalized extensions, so ".EXE", "exe" and
+// "*.exe" in a config file all match the lower-cased extension the
+// validator looks up
 func makeExtSet(exts []string) map[string]bool {
 	set := make(map[string]bool)
 	for _, ext := range exts {
-		set[strings.ToLower(ext)] = true
+		if ext = NormalizeExtension(ext); ext != "" {
+			set[ext] = true
+		}
+	}
+	return set
+}
+
+// makeNameSet builds a set of upper-cased names for case-insensitive
+// lookups
+func makeNameSet(names []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range names {
+		set[strings.ToUpper(name)] = true
 	}
 	return set
 }
 
+// makePatternSet builds a set of lower-cased name patterns; names are
+// lower-cased before they are matched
 func makePatternSet(patterns []string) map[string]bool {
 	set := make(map[string]bool)
 	for _, pattern := range patterns {
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAllowExtensionsRemovesCustom(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.BlockExtensions([]string{".iso", "exe"})
+	cfg.AllowExtensions([]string{"ISO", ".exe"})
+
+	for _, ext := range []string{".iso", ".exe"} {
+		if cfg.BlockedFileTypes.Custom.ExtensionsSet[ext] {
+			t.Errorf("%s still in the custom blocked set", ext)
+		}
+		if cfg.BlockedFileTypes.Executables.ExtensionsSet[ext] {
+			t.Errorf("%s still in the executables set", ext)
+		}
+	}
+	for _, listed := range cfg.BlockedFileTypes.Custom.Extensions {
+		if listed == ".iso" || listed == ".exe" {
+			t.Errorf("%s still in the custom extension list", listed)
+		}
+	}
+}
+
+// writeConfig writes a config file into a temporary folder and returns
+// its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigCompilesCustomRules(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, `{
+  "customRules": [
+    {"name": "ProjectCode", "pattern": "PRJ-\\d{4}", "appliesTo": "folders"}
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule := cfg.CustomRules[0]
+	if rule.Regex == nil || !rule.Regex.MatchString("Archive PRJ-1234") {
+		t.Fatalf("rule was not compiled: %+v", rule)
+	}
+	if rule.Severity != "Warning" {
+		t.Errorf("default severity = %q, want Warning", rule.Severity)
+	}
+	if rule.AppliesToItem(false) || !rule.AppliesToItem(true) {
+		t.Error("a folders rule should apply to folders only")
+	}
+}
+
+func TestLoadConfigRejectsInvalidCustomRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		want string
+	}{
+		{"bad pattern", `{"name": "Broken", "pattern": "PRJ-(\\d"}`, `custom rule "Broken": invalid pattern`},
+		{"no pattern", `{"name": "Empty"}`, `custom rule "Empty": pattern is required`},
+		{"bad severity", `{"name": "Loud", "pattern": "x", "severity": "Error"}`, `invalid severity "Error"`},
+		{"bad appliesTo", `{"name": "Odd", "pattern": "x", "appliesTo": "links"}`, `invalid appliesTo "links"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, `{"customRules": [`+tt.rule+`]}`))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("LoadConfig error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetFileSizeWarnings(t *testing.T) {
+	cfg := NewDefaultConfig()
+	err := cfg.SetFileSizeWarnings([]FileSizeTier{
+		{Bytes: 100, Severity: "info"},
+		{Bytes: 300, Severity: "CRITICAL"},
+		{Bytes: 200, Severity: "Warning"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FileSizeTier{{300, "Critical"}, {200, "Warning"}, {100, "Info"}}
+	if got := cfg.Settings.FileSizeWarnings; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("tiers = %v, want %v", got, want)
+	}
+
+	for _, tiers := range [][]FileSizeTier{
+		{{Bytes: 0, Severity: "Info"}},
+		{{Bytes: 100, Severity: ""}},
+		{{Bytes: 100, Severity: "Urgent"}},
+	} {
+		if err := cfg.SetFileSizeWarnings(tiers); err == nil {
+			t.Errorf("SetFileSizeWarnings(%v) succeeded, want an error", tiers)
+		}
+	}
+}
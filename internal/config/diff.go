@@ -0,0 +1,178 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of Difference
+const (
+	DiffAdded   = "added"   // In the base config only
+	DiffRemoved = "removed" // In the compared config only
+	DiffChanged = "changed" // Set in both to different values
+)
+
+// Difference is one way a config differs from a base config, such as an
+// extension missing from a blocked list or a changed limit
+type Difference struct {
+	Path  string // Field path, such as "BlockedFileTypes.Executables.Extensions"
+	Kind  string // DiffAdded, DiffRemoved or DiffChanged
+	Value string // The list entry added or removed, or the compared config's value
+	Base  string // The base config's value, for DiffChanged
+}
+
+// runeFields are the Runes fields, whose code points are shown as U+XXXX
+var runeFields = map[string]bool{
+	"SPOLimits.InvalidCharacters":   true,
+	"SPOLimits.InvisibleCharacters": true,
+}
+
+// Diff compares a config with a base config, such as the built-in
+// defaults, field by field as WriteJSON writes them. Lists, such as
+// extensions or reserved names, are compared as sets and maps entry by
+// entry; other fields are compared by value. Differences are sorted by
+// path.
+func Diff(c, base *Config) ([]Difference, error) {
+	value, err := configTree(c)
+	if err != nil {
+		return nil, err
+	}
+	baseValue, err := configTree(base)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []Difference
+	diffValues("", value, baseValue, &diffs)
+
+	kindOrder := map[string]int{DiffChanged: 0, DiffAdded: 1, DiffRemoved: 2}
+	sort.SliceStable(diffs, func(i, j int) bool {
+		if diffs[i].Path != diffs[j].Path {
+			return diffs[i].Path < diffs[j].Path
+		}
+		return kindOrder[diffs[i].Kind] < kindOrder[diffs[j].Kind]
+	})
+	return diffs, nil
+}
+
+// configTree returns a config as decoded JSON, with numbers kept as
+// written
+func configTree(c *Config) (interface{}, error) {
+	var buf bytes.Buffer
+	if err := c.WriteJSON(&buf); err != nil {
+		return nil, err
+	}
+
+	var tree interface{}
+	decoder := json.NewDecoder(&buf)
+	decoder.UseNumber()
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func diffValues(path string, value, base interface{}, diffs *[]Difference) {
+	switch b := base.(type) {
+	case map[string]interface{}:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(b)+len(v))
+		for key := range b {
+			keys = append(keys, key)
+		}
+		for key := range v {
+			if _, ok := b[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fieldPath := key
+			switch {
+			case strings.Contains(key, "."):
+				// Map keys such as the extensions in ProblematicFiles.Other
+				fieldPath = path + "[" + key + "]"
+			case path != "":
+				fieldPath = path + "." + key
+			}
+			bv, inBase := b[key]
+			vv, inValue := v[key]
+			switch {
+			case inBase && inValue:
+				diffValues(fieldPath, vv, bv, diffs)
+			case inBase:
+				*diffs = append(*diffs, Difference{Path: fieldPath, Kind: DiffAdded, Value: formatDiffValue(path, bv)})
+			default:
+				*diffs = append(*diffs, Difference{Path: fieldPath, Kind: DiffRemoved, Value: formatDiffValue(path, vv)})
+			}
+		}
+		return
+
+	case []interface{}, nil:
+		v, ok := value.([]interface{})
+		if !ok && value != nil {
+			break
+		}
+		list, _ := base.([]interface{})
+		inBase := make(map[string]bool, len(list))
+		for _, entry := range list {
+			inBase[formatDiffValue(path, entry)] = true
+		}
+		inValue := make(map[string]bool, len(v))
+		for _, entry := range v {
+			inValue[formatDiffValue(path, entry)] = true
+		}
+		for _, entry := range list {
+			if s := formatDiffValue(path, entry); !inValue[s] {
+				inValue[s] = true
+				*diffs = append(*diffs, Difference{Path: path, Kind: DiffAdded, Value: s})
+			}
+		}
+		for _, entry := range v {
+			if s := formatDiffValue(path, entry); !inBase[s] {
+				inBase[s] = true
+				*diffs = append(*diffs, Difference{Path: path, Kind: DiffRemoved, Value: s})
+			}
+		}
+		return
+	}
+
+	if s, bs := formatDiffValue(path, value), formatDiffValue(path, base); s != bs {
+		*diffs = append(*diffs, Difference{Path: path, Kind: DiffChanged, Value: s, Base: bs})
+	}
+}
+
+// formatDiffValue writes a value of the field at path for display:
+// strings as they are, unless empty, and anything else as compact JSON
+func formatDiffValue(path string, value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return `""`
+		}
+		return v
+	case json.Number:
+		if runeFields[path] {
+			if code, err := strconv.ParseInt(string(v), 10, 32); err == nil {
+				return fmt.Sprintf("U+%04X", code)
+			}
+		}
+		return string(v)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(bytes.TrimSpace(buf.Bytes()))
+}
@@ -0,0 +1,109 @@
+// Package i18n holds the text of validation issues, keyed by stable
+// message IDs, so reports can be produced in other languages.
+package i18n
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultLanguage is the language of the built-in catalog
+const DefaultLanguage = "en"
+
+//go:embed en.json
+var englishJSON []byte
+
+var english = mustParse(englishJSON)
+
+// Message is the text of one issue condition. Fields may hold %d and %s
+// placeholders filled in by the validator: all %d values are filled first,
+// in order, then all %s values, so a translation may move placeholders of
+// different kinds but must keep each kind in its original order. An empty
+// field falls back to the English text.
+type Message struct {
+	Message string `json:"message,omitempty"`
+	Details string `json:"details,omitempty"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// Catalog maps message IDs to their text in one language
+type Catalog struct {
+	Language string             `json:"language"`
+	Messages map[string]Message `json:"messages"`
+}
+
+// English returns the built-in English catalog
+func English() *Catalog {
+	return english
+}
+
+// Load returns the catalog for lang: "" or "en" for the built-in English
+// catalog, or the path of a JSON catalog file laid out like en.json.
+func Load(lang string) (*Catalog, error) {
+	if lang == "" || strings.EqualFold(lang, DefaultLanguage) {
+		return english, nil
+	}
+
+	data, err := os.ReadFile(lang)
+	if err != nil {
+		if os.IsNotExist(err) && !strings.ContainsAny(lang, `./\`) {
+			return nil, fmt.Errorf("unknown language %q (expected %s or the path of a catalog file)", lang, DefaultLanguage)
+		}
+		return nil, fmt.Errorf("failed to read message catalog: %w", err)
+	}
+
+	catalog, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message catalog %s: %w", lang, err)
+	}
+	for id := range catalog.Messages {
+		if _, ok := english.Messages[id]; !ok {
+			return nil, fmt.Errorf("message catalog %s: unknown message ID %q", lang, id)
+		}
+	}
+
+	return catalog, nil
+}
+
+// Lookup returns the text for a message ID, taking each empty field from
+// the English catalog
+func (c *Catalog) Lookup(id string) Message {
+	fallback := english.Messages[id]
+	if c == nil || c == english {
+		return fallback
+	}
+
+	msg := c.Messages[id]
+	if msg.Message == "" {
+		msg.Message = fallback.Message
+	}
+	if msg.Details == "" {
+		msg.Details = fallback.Details
+	}
+	if msg.Hint == "" {
+		msg.Hint = fallback.Hint
+	}
+	return msg
+}
+
+func parse(data []byte) (*Catalog, error) {
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, err
+	}
+	if catalog.Messages == nil {
+		catalog.Messages = make(map[string]Message)
+	}
+	return &catalog, nil
+}
+
+func mustParse(data []byte) *Catalog {
+	catalog, err := parse(data)
+	if err != nil {
+		panic("invalid built-in message catalog: " + err.Error())
+	}
+	return catalog
+}
@@ -11,6 +11,9 @@ const (
 	SeverityInfo     Severity = "Info"
 )
 
+// Severities lists every severity from most to least severe
+var Severities = []Severity{SeverityCritical, SeverityWarning, SeverityInfo}
+
 // IssueType represents the category of the issue
 type IssueType string
 
@@ -24,41 +27,146 @@ const (
 	IssueNameConflict      IssueType = "NameConflict"
 	IssueHiddenFile        IssueType = "HiddenFile"
 	IssueSystemFile        IssueType = "SystemFile"
+	IssueCustomRule        IssueType = "CustomRule"
+	IssueCaseConflict      IssueType = "CaseConflict"
+	IssueFileLocked        IssueType = "FileLocked"
+	IssueReparsePoint      IssueType = "ReparsePoint"
+	IssueExtensionMismatch IssueType = "ExtensionMismatch"
+	IssueAlternateStream   IssueType = "AlternateStream"
+	IssueEmptyFolder       IssueType = "EmptyFolder"
+	IssueConfusableName    IssueType = "ConfusableName"
 )
 
+// IssueTypes lists every issue type in the order summaries show them
+var IssueTypes = []IssueType{
+	IssuePathLength,
+	IssueInvalidCharacters,
+	IssueReservedName,
+	IssueBlockedFileType,
+	IssueProblematicFile,
+	IssueFileSize,
+	IssueNameConflict,
+	IssueHiddenFile,
+	IssueSystemFile,
+	IssueCustomRule,
+	IssueCaseConflict,
+	IssueFileLocked,
+	IssueReparsePoint,
+	IssueExtensionMismatch,
+	IssueAlternateStream,
+	IssueEmptyFolder,
+	IssueConfusableName,
+}
+
 // Issue represents a validation problem found during scanning
 type Issue struct {
 	Path            string    `json:"path"`
 	Type            IssueType `json:"type"`
+	Code            string    `json:"code,omitempty"`
 	Severity        Severity  `json:"severity"`
 	Message         string    `json:"message"`
+	MessageID       string    `json:"messageId,omitempty"`
 	Details         string    `json:"details,omitempty"`
 	Category        string    `json:"category,omitempty"`
 	Size            int64     `json:"size,omitempty"`
+	Count           int       `json:"count,omitempty"`
+	CurrentLength   int       `json:"currentLength,omitempty"`
+	LimitPercent    float64   `json:"limitPercent,omitempty"`
 	IsDirectory     bool      `json:"isDirectory"`
 	RemediationHint string    `json:"remediationHint,omitempty"`
+
+	// ActionRequired is false for conditions that migration tools skip on
+	// their own, such as Office owner files, which need no user action
+	ActionRequired bool `json:"actionRequired"`
 }
 
+// SchemaVersion identifies the shape of the JSON report. Bump the minor
+// version when fields are added and the major version when fields are
+// removed, renamed or change meaning. See schema/scan-result.schema.json.
+const SchemaVersion = "2.19"
+
 // ScanResult represents the complete scan output
 type ScanResult struct {
-	ScanPath      string        `json:"scanPath"`
-	DestinationURL string       `json:"destinationUrl,omitempty"`
-	StartTime     time.Time     `json:"startTime"`
-	EndTime       time.Time     `json:"endTime"`
-	Duration      time.Duration `json:"duration"`
-	TotalItems    int64         `json:"totalItems"`
-	TotalFiles    int64         `json:"totalFiles"`
-	TotalFolders  int64         `json:"totalFolders"`
-	TotalSize     int64         `json:"totalSize"`
-	IssuesFound   int           `json:"issuesFound"`
-	Issues        []Issue       `json:"issues"`
-	Summary       IssueSummary  `json:"summary"`
+	SchemaVersion      string          `json:"schemaVersion"`
+	ScanPath           string          `json:"scanPath"`
+	DestinationURL     string          `json:"destinationUrl,omitempty"`
+	StartTime          time.Time       `json:"startTime"`
+	EndTime            time.Time       `json:"endTime"`
+	Duration           time.Duration   `json:"duration"` // Nanoseconds; deprecated, kept for consumers from before 2.11
+	DurationSeconds    float64         `json:"durationSeconds"`
+	DurationISO        string          `json:"durationIso"` // ISO 8601, e.g. PT1M30.25S
+	TotalItems         int64           `json:"totalItems"`
+	TotalFiles         int64           `json:"totalFiles"`
+	TotalFolders       int64           `json:"totalFolders"`
+	TotalSize          int64           `json:"totalSize"`
+	SizesSkipped       bool            `json:"sizesSkipped,omitempty"` // Sizes were only read for items with issues, so TotalSize is 0
+	IssuesFound        int             `json:"issuesFound"`
+	Truncated          bool            `json:"truncated,omitempty"` // The scan stopped at ItemLimit with items left unscanned
+	ItemLimit          int64           `json:"itemLimit,omitempty"`
+	ReadinessScore     int             `json:"readinessScore"`
+	Suppressed         int             `json:"suppressed,omitempty"`
+	Spilled            int             `json:"spilled,omitempty"` // Issues left out of Issues to stay within a memory limit
+	AcceptedCategories []string        `json:"acceptedCategories,omitempty"`
+	OnlyTypes          []IssueType     `json:"onlyTypes,omitempty"`
+	Issues             []Issue         `json:"issues"`
+	Summary            IssueSummary    `json:"summary"`
+	Errors             []ScanError     `json:"errors,omitempty"`
+	TopOffenders       *TopOffenders   `json:"topOffenders,omitempty"`
+	ByExtension        []ExtensionStat `json:"byExtension,omitempty"`
+
+	// PotentialSecrets repeats the issues in CategorySecrets so they can
+	// be reviewed on their own
+	PotentialSecrets []Issue `json:"potentialSecrets,omitempty"`
+
+	// RootIssues are the problems with the scan root's own name, which
+	// the destination is often named after. They are not counted in
+	// IssuesFound or Summary.
+	RootIssues []Issue `json:"rootIssues,omitempty"`
+}
+
+// ExtensionStat counts the files with issues that share an extension
+type ExtensionStat struct {
+	Extension  string `json:"extension"`
+	Count      int    `json:"count"`
+	TotalBytes int64  `json:"totalBytes"`
+}
+
+// TopOffenders ranks the worst items found during the scan, highest
+// value first and ties broken by path
+type TopOffenders struct {
+	LongestPaths   []RankedItem `json:"longestPaths"`
+	LargestFiles   []RankedItem `json:"largestFiles"`
+	DeepestFolders []RankedItem `json:"deepestFolders"`
+}
+
+// RankedItem is one entry in a TopOffenders ranking. Value is the path
+// length in characters, the size in bytes or the folder depth.
+type RankedItem struct {
+	Path  string `json:"path"`
+	Value int64  `json:"value"`
+}
+
+// ScanError records a path that could not be scanned
+type ScanError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	Retries int    `json:"retries,omitempty"` // Times a read that failed with a transient error was repeated
 }
 
 // IssueSummary provides a count of issues by type and severity
 type IssueSummary struct {
-	ByType     map[IssueType]int `json:"byType"`
-	BySeverity map[Severity]int  `json:"bySeverity"`
+	ByType         map[IssueType]int `json:"byType"`
+	BySeverity     map[Severity]int  `json:"bySeverity"`
+	ActionRequired int               `json:"actionRequired"`
+	AutoSkipped    int               `json:"autoSkipped"` // Issues migration tools skip without user action
+}
+
+// FolderContents records which folders the scanner did not fully list, so
+// whole-tree checks know a folder may hold more than the items sent for it
+type FolderContents struct {
+	Excluded map[string]bool // Folders holding excluded folders or filtered files
+	Unread   map[string]bool // Folders with unreadable entries, or not scanned through
+	Complete bool            // False when the walk stopped early or only listed paths
 }
 
 // ScanProgress represents the current scan progress
@@ -69,16 +177,58 @@ type ScanProgress struct {
 	BytesScanned int64
 	IssuesFound  int
 	CurrentPath  string
+
+	// IssuesByType counts the issues found so far by type. Each update
+	// has its own copy, so it can be kept or sent to another goroutine.
+	IssuesByType map[IssueType]int
 }
 
+// CategorySecrets is the category of ProblematicFile issues for files
+// whose names suggest keys or credentials
+const CategorySecrets = "Security"
+
+// Reparse point classes reported in FileSystemItem.ReparseType
+const (
+	ReparseSymlink    = "Symlink"
+	ReparseMountPoint = "MountPoint" // Volume mount points and junctions
+	ReparseDedup      = "Dedup"
+	ReparseCloud      = "Cloud" // OneDrive and other cloud file placeholders
+	ReparseOther      = "Other"
+)
+
+// File formats recognized by content sniffing, reported in
+// FileSystemItem.ContentType
+const (
+	ContentTypePDF        = "PDF"
+	ContentTypePNG        = "PNG"
+	ContentTypeJPEG       = "JPEG"
+	ContentTypeZIP        = "ZIP"
+	ContentTypeWord       = "Word (OOXML)"
+	ContentTypeExcel      = "Excel (OOXML)"
+	ContentTypePowerPoint = "PowerPoint (OOXML)"
+	ContentTypeDWG        = "DWG"
+)
+
 // FileSystemItem represents a file or folder being scanned
 type FileSystemItem struct {
-	Path        string
-	Name        string
-	IsDir       bool
-	Size        int64
-	ModTime     time.Time
-	IsHidden    bool
-	IsSystem    bool
+	Path         string
+	Name         string
+	IsDir        bool
+	Size         int64
+	ModTime      time.Time
+	IsHidden     bool
+	IsSystem     bool
+	IsLocked     bool
+	ReparseType  string   // One of the Reparse* constants, or "" for ordinary items
+	ContentType  string   // One of the ContentType* constants when sniffed, or ""
+	Streams      []string // Alternate data stream names, when listed
+	ShortName    string   // Windows 8.3 short name, when looked up and different from Name
 	RelativePath string
+
+	// Issues found when the scanner validates items on discovery
+	Issues []Issue
+
+	// Filtered is set on files excluded by the size or date filters that
+	// are only sent so they count toward totals; they are not validated
+	Filtered bool
 }
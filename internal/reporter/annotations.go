@@ -0,0 +1,111 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// GitHubAnnotationLimit is the number of annotations of each level GitHub
+// Actions shows for a step; any beyond it are dropped
+const GitHubAnnotationLimit = 10
+
+// githubAnnotationLevels maps issue severities to workflow command names
+var githubAnnotationLevels = map[models.Severity]string{
+	models.SeverityCritical: "error",
+	models.SeverityWarning:  "warning",
+	models.SeverityInfo:     "notice",
+}
+
+// WriteGitHubAnnotations writes issues as GitHub Actions workflow commands
+// (::error::, ::warning:: and ::notice::), most severe first. When a level
+// has more issues than GitHub shows, the last annotation of that level
+// counts the rest instead. Issues under workspace, usually
+// $GITHUB_WORKSPACE, are annotated on the file; others name the path in
+// the message.
+func WriteGitHubAnnotations(w io.Writer, issues []models.Issue, workspace string) error {
+	sorted := make([]models.Issue, len(issues))
+	copy(sorted, issues)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Severity != sorted[j].Severity {
+			return severityRank(sorted[i].Severity) < severityRank(sorted[j].Severity)
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	total := make(map[string]int)
+	for _, issue := range sorted {
+		if level, ok := githubAnnotationLevels[issue.Severity]; ok {
+			total[level]++
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	written := make(map[string]int)
+	for _, issue := range sorted {
+		level, ok := githubAnnotationLevels[issue.Severity]
+		if !ok {
+			continue
+		}
+		if total[level] > GitHubAnnotationLimit && written[level] == GitHubAnnotationLimit-1 {
+			rest := total[level] - written[level]
+			fmt.Fprintf(bw, "::%s title=%s::%s\n", level,
+				escapeAnnotationProperty(fmt.Sprintf("%d more %s issues", rest, issue.Severity)),
+				escapeAnnotationData(fmt.Sprintf("%d more %s issues are not shown here; see the scan reports for the full list.", rest, issue.Severity)))
+			written[level]++
+		}
+		if written[level] >= GitHubAnnotationLimit {
+			continue
+		}
+
+		title := string(issue.Type)
+		if issue.Code != "" {
+			title += " " + issue.Code
+		}
+		properties := "title=" + escapeAnnotationProperty(title)
+		message := issue.Message
+		if rel, ok := workspacePath(issue.Path, workspace); ok {
+			properties = "file=" + escapeAnnotationProperty(rel) + "," + properties
+		} else {
+			message = issue.Path + ": " + message
+		}
+		if issue.Details != "" {
+			message += " (" + issue.Details + ")"
+		}
+		fmt.Fprintf(bw, "::%s %s::%s\n", level, properties, escapeAnnotationData(message))
+		written[level]++
+	}
+	return bw.Flush()
+}
+
+// workspacePath returns path relative to workspace with forward slashes,
+// and whether path is inside it
+func workspacePath(path, workspace string) (string, bool) {
+	if workspace == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(workspace, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// escapeAnnotationData escapes the message of a workflow command
+func escapeAnnotationData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeAnnotationProperty escapes a property value of a workflow command
+func escapeAnnotationProperty(s string) string {
+	s = escapeAnnotationData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,165 @@
+package reporter
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RunInfoFilename is the metadata file written into every bundle
+const RunInfoFilename = "run.json"
+
+// RunInfo describes the run that produced a bundle, so the reports in it
+// can be associated with a scan without opening them
+type RunInfo struct {
+	Tool           string    `json:"tool"`
+	Version        string    `json:"version"`
+	Commit         string    `json:"commit,omitempty"`
+	SchemaVersion  string    `json:"schemaVersion"`
+	ScanPath       string    `json:"scanPath"`
+	DestinationURL string    `json:"destinationUrl,omitempty"`
+	StartTime      time.Time `json:"startTime"`
+	EndTime        time.Time `json:"endTime"`
+	TotalItems     int64     `json:"totalItems"`
+	IssuesFound    int       `json:"issuesFound"`
+	Interrupted    bool      `json:"interrupted"`
+	Truncated      bool      `json:"truncated,omitempty"` // Stopped at -max-items
+	Files          []string  `json:"files"`
+}
+
+// CreateBundleDir creates a new <timestamp>-<root> directory under
+// outputDir and returns its path. The directory is created with os.Mkdir
+// so that two runs can never share one; if the name is taken a numeric
+// suffix is added.
+func CreateBundleDir(outputDir, scanRoot string, now time.Time) (string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	name := now.Format("20060102-150405")
+	root := sanitizeFilename(filepath.Base(scanRoot))
+	if root != "" {
+		name += "-" + root
+	}
+
+	for i := 1; ; i++ {
+		candidate := name
+		if i > 1 {
+			candidate += "-" + strconv.Itoa(i)
+		}
+		dir := filepath.Join(outputDir, candidate)
+
+		// Skip names whose archive is left over from an earlier run
+		if _, err := os.Stat(dir + ".zip"); err == nil {
+			continue
+		}
+
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create bundle directory: %w", err)
+		}
+	}
+}
+
+// WriteRunInfo lists the files in the bundle directory into info.Files
+// and writes it to run.json in the same directory
+func WriteRunInfo(dir string, info RunInfo) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle directory: %w", err)
+	}
+
+	info.Files = []string{}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && entry.Name() != RunInfoFilename {
+			info.Files = append(info.Files, entry.Name())
+		}
+	}
+	sort.Strings(info.Files)
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run metadata: %w", err)
+	}
+
+	outputPath := filepath.Join(dir, RunInfoFilename)
+	if err := os.WriteFile(outputPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write run metadata: %w", err)
+	}
+
+	fmt.Printf("Run metadata saved: %s\n", outputPath)
+	return nil
+}
+
+// ZipBundle archives the bundle directory into <dir>.zip next to it. The
+// files are stored under the directory name so the archive extracts into
+// a folder of the same name.
+func ZipBundle(dir string) (string, error) {
+	outputPath := dir + ".zip"
+
+	file, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create bundle archive: %w", err)
+	}
+	defer file.Close()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bundle directory: %w", err)
+	}
+
+	archive := zip.NewWriter(file)
+	base := filepath.Base(dir)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := addZipFile(archive, filepath.Join(dir, entry.Name()), base+"/"+entry.Name()); err != nil {
+			archive.Close()
+			return "", fmt.Errorf("failed to write bundle archive: %w", err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return "", fmt.Errorf("failed to write bundle archive: %w", err)
+	}
+
+	fmt.Printf("Bundle archive saved: %s\n", outputPath)
+	return outputPath, nil
+}
+
+func addZipFile(archive *zip.Writer, path, name string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	dst, err := archive.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
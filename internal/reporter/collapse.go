@@ -0,0 +1,217 @@
+package reporter
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// CollapseProblematic replaces per-file problematic-file issues with a
+// single summary issue per category once a category reaches threshold
+// issues. It returns the resulting issue list and the individual issues
+// that were folded into summaries, so callers can write them to a
+// sidecar list. Categories below the threshold are left untouched, and
+// potential secrets are never folded, since each needs its own review.
+// Folder issues, such as version control working copies, are left as
+// they are.
+func CollapseProblematic(issues []models.Issue, threshold int, scanRoot string, listFile string) ([]models.Issue, []models.Issue) {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	byCategory := make(map[string][]models.Issue)
+	for _, issue := range issues {
+		if issue.Type == models.IssueProblematicFile && !issue.IsDirectory && !isSecretsIssue(issue) {
+			byCategory[issue.Category] = append(byCategory[issue.Category], issue)
+		}
+	}
+
+	collapse := make(map[string]bool)
+	for category, members := range byCategory {
+		if len(members) >= threshold {
+			collapse[category] = true
+		}
+	}
+	if len(collapse) == 0 {
+		return issues, nil
+	}
+
+	var (
+		kept   []models.Issue
+		folded []models.Issue
+	)
+	for _, issue := range issues {
+		if issue.Type == models.IssueProblematicFile && collapse[issue.Category] {
+			folded = append(folded, issue)
+			continue
+		}
+		kept = append(kept, issue)
+	}
+
+	categories := make([]string, 0, len(collapse))
+	for category := range collapse {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		members := byCategory[category]
+
+		var totalSize int64
+		severity := models.SeverityInfo
+		for _, member := range members {
+			totalSize += member.Size
+			if severityRank(member.Severity) < severityRank(severity) {
+				severity = member.Severity
+			}
+		}
+
+		label := category
+		if label == "" {
+			label = "problematic"
+		}
+
+		message := fmt.Sprintf("%s %s files detected", formatCount(len(members)), label)
+		if listFile != "" {
+			message += fmt.Sprintf(" - see %s", filepath.Base(listFile))
+		}
+
+		kept = append(kept, models.Issue{
+			Path:            scanRoot,
+			Type:            models.IssueProblematicFile,
+			Code:            members[0].Code,
+			Severity:        severity,
+			Message:         message,
+			Details:         members[0].Message,
+			Category:        category,
+			Size:            totalSize,
+			Count:           len(members),
+			IsDirectory:     true,
+			RemediationHint: members[0].RemediationHint,
+			ActionRequired:  members[0].ActionRequired,
+		})
+	}
+
+	return kept, folded
+}
+
+// CapIssuesPerFolder keeps the first limit issues in each folder, in the
+// order given, and replaces the rest of a folder's issues with one
+// summary issue saying how many more there are. An issue belongs to the
+// folder that holds its item, so a folder's own issues count against its
+// parent. The summary takes the most common type among the issues it
+// replaces, their worst severity, and a breakdown by type in Details.
+// Summary issues made by CollapseProblematic are never dropped.
+func CapIssuesPerFolder(issues []models.Issue, limit int) []models.Issue {
+	if limit < 1 {
+		return issues
+	}
+
+	kept := make([]models.Issue, 0, len(issues))
+	counts := make(map[string]int)
+	dropped := make(map[string][]models.Issue)
+	var folders []string
+	for _, issue := range issues {
+		folder := filepath.Dir(issue.Path)
+		if issue.Count > 0 || counts[folder] < limit {
+			if issue.Count == 0 {
+				counts[folder]++
+			}
+			kept = append(kept, issue)
+			continue
+		}
+		if len(dropped[folder]) == 0 {
+			folders = append(folders, folder)
+		}
+		dropped[folder] = append(dropped[folder], issue)
+	}
+	sort.Strings(folders)
+
+	for _, folder := range folders {
+		members := dropped[folder]
+
+		byType := make(map[models.IssueType]int)
+		severity := models.SeverityInfo
+		actionRequired := false
+		for _, member := range members {
+			byType[member.Type]++
+			if severityRank(member.Severity) < severityRank(severity) {
+				severity = member.Severity
+			}
+			actionRequired = actionRequired || member.ActionRequired
+		}
+
+		types := sortedIssueTypes(byType)
+		sort.SliceStable(types, func(i, j int) bool { return byType[types[i]] > byType[types[j]] })
+		var breakdown []string
+		for _, issueType := range types {
+			breakdown = append(breakdown, fmt.Sprintf("%s: %s", issueType, formatCount(byType[issueType])))
+		}
+
+		kept = append(kept, models.Issue{
+			Path:            folder,
+			Type:            types[0],
+			Severity:        severity,
+			Message:         fmt.Sprintf("And %s more issues in this folder (-max-issues-per-folder)", formatCount(len(members))),
+			Details:         strings.Join(breakdown, "; "),
+			Count:           len(members),
+			IsDirectory:     true,
+			RemediationHint: "Scan this folder on its own to list every issue in it.",
+			ActionRequired:  actionRequired,
+		})
+	}
+
+	return kept
+}
+
+// GenerateCollapsedListCSV writes the individual issues folded by
+// CollapseProblematic to a sidecar CSV file
+func (r *Reporter) GenerateCollapsedListCSV(issues []models.Issue, filename string) error {
+	if filename == "" {
+		filename = r.CollapsedListFilename()
+	}
+
+	outputPath := filepath.Join(r.outputDir, filename)
+
+	sorted := make([]models.Issue, len(issues))
+	copy(sorted, issues)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Category != sorted[j].Category {
+			return sorted[i].Category < sorted[j].Category
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	if err := writeIssuesCSV(outputPath, sorted); err != nil {
+		return err
+	}
+
+	fmt.Printf("Problematic file list saved: %s\n", outputPath)
+	return nil
+}
+
+// CollapsedListFilename returns the default name of the collapsed-issue
+// sidecar file
+func (r *Reporter) CollapsedListFilename() string {
+	return r.defaultFilename("-problematic-files", ".csv")
+}
+
+// formatCount formats an integer with thousands separators
+func formatCount(n int) string {
+	str := fmt.Sprintf("%d", n)
+	if n < 1000 {
+		return str
+	}
+
+	result := ""
+	for i, c := range str {
+		if i > 0 && (len(str)-i)%3 == 0 {
+			result += ","
+		}
+		result += string(c)
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package reporter
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// OtherExtensions is the extension bucket that holds the extensions beyond
+// the breakdown limit
+const OtherExtensions = "other"
+
+// ExtensionBreakdown counts the files with issues, and their total size, by
+// extension. Only issues that carry a Size are counted and each file is
+// counted once however many issues it has. The result is sorted by count
+// descending; when there are more than limit extensions the remainder is
+// summed into a final OtherExtensions entry.
+func ExtensionBreakdown(issues []models.Issue, limit int) []models.ExtensionStat {
+	seen := make(map[string]bool)
+	byExt := make(map[string]*models.ExtensionStat)
+
+	for _, issue := range issues {
+		if issue.Size == 0 || issue.IsDirectory || issue.Count > 0 || seen[issue.Path] {
+			continue
+		}
+		seen[issue.Path] = true
+
+		ext := strings.ToLower(filepath.Ext(issue.Path))
+		if ext == "" {
+			ext = "(none)"
+		}
+
+		stat, ok := byExt[ext]
+		if !ok {
+			stat = &models.ExtensionStat{Extension: ext}
+			byExt[ext] = stat
+		}
+		stat.Count++
+		stat.TotalBytes += issue.Size
+	}
+
+	stats := make([]models.ExtensionStat, 0, len(byExt))
+	for _, stat := range byExt {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		if stats[i].TotalBytes != stats[j].TotalBytes {
+			return stats[i].TotalBytes > stats[j].TotalBytes
+		}
+		return stats[i].Extension < stats[j].Extension
+	})
+
+	if limit <= 0 || len(stats) <= limit {
+		return stats
+	}
+
+	other := models.ExtensionStat{Extension: OtherExtensions}
+	for _, stat := range stats[limit:] {
+		other.Count += stat.Count
+		other.TotalBytes += stat.TotalBytes
+	}
+	return append(stats[:limit], other)
+}
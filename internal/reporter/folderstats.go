@@ -0,0 +1,137 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// FolderStat is the rollup of everything below one folder
+type FolderStat struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Files   int64  `json:"files"`
+	Folders int64  `json:"folders"`
+	Issues  int    `json:"issues"`
+}
+
+// FolderStats rolls items up into the folders depth levels below the scan
+// root as they are scanned, so no item list is retained. Items closer to
+// the root than depth are counted under the root itself ("."). It is not
+// safe for concurrent use.
+type FolderStats struct {
+	depth   int
+	folders map[string]*FolderStat
+}
+
+// NewFolderStats creates an accumulator for folders at the given depth;
+// depth 1 rolls up each top-level folder
+func NewFolderStats(depth int) *FolderStats {
+	if depth < 1 {
+		depth = 1
+	}
+	return &FolderStats{depth: depth, folders: make(map[string]*FolderStat)}
+}
+
+// Add counts an item toward the folder that contains it
+func (f *FolderStats) Add(item *models.FileSystemItem) {
+	parts := strings.Split(filepath.ToSlash(item.RelativePath), "/")
+
+	// A folder at exactly the rollup depth is the bucket itself
+	key := "."
+	if len(parts) > f.depth || (item.IsDir && len(parts) == f.depth) {
+		key = strings.Join(parts[:f.depth], "/")
+	}
+
+	stat, ok := f.folders[key]
+	if !ok {
+		stat = &FolderStat{Path: key}
+		f.folders[key] = stat
+	}
+
+	if item.IsDir {
+		if key != strings.Join(parts, "/") {
+			stat.Folders++
+		}
+	} else {
+		stat.Files++
+		stat.Size += item.Size
+	}
+	stat.Issues += len(item.Issues)
+}
+
+// Stats returns the folder rollups sorted by size, largest first
+func (f *FolderStats) Stats() []FolderStat {
+	stats := make([]FolderStat, 0, len(f.folders))
+	for _, stat := range f.folders {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Size != stats[j].Size {
+			return stats[i].Size > stats[j].Size
+		}
+		return stats[i].Path < stats[j].Path
+	})
+	return stats
+}
+
+// FolderStatsFilename returns the default folder stats filename for an
+// extension such as ".csv" or ".json"
+func (r *Reporter) FolderStatsFilename(ext string) string {
+	return r.defaultFilename("-folders", ext)
+}
+
+// GenerateFolderStats writes the folder rollups to a CSV file, or to a
+// JSON file when filename ends in .json
+func (r *Reporter) GenerateFolderStats(stats *FolderStats, filename string) error {
+	if filename == "" {
+		filename = r.FolderStatsFilename(".csv")
+	}
+
+	outputPath := filepath.Join(r.outputDir, filename)
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create folder stats file: %w", err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(stats.Stats()); err != nil {
+			return fmt.Errorf("failed to encode folder stats: %w", err)
+		}
+	} else {
+		writer := csv.NewWriter(file)
+		if err := writer.Write([]string{"Path", "SizeBytes", "Files", "Folders", "Issues"}); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		for _, stat := range stats.Stats() {
+			row := []string{
+				stat.Path,
+				strconv.FormatInt(stat.Size, 10),
+				strconv.FormatInt(stat.Files, 10),
+				strconv.FormatInt(stat.Folders, 10),
+				strconv.Itoa(stat.Issues),
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	fmt.Printf("Folder stats saved: %s\n", outputPath)
+	return nil
+}
@@ -0,0 +1,148 @@
+package reporter
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// liveWriteInterval is how often the live HTML report is rewritten while
+// the scan runs unless SetInterval changes it
+const liveWriteInterval = 10 * time.Second
+
+// liveRefreshSeconds is how often a browser showing the live report
+// reloads it
+const liveRefreshSeconds = 10
+
+// LiveHTML rewrites the HTML report during a scan with the totals and the
+// issues found so far, so a long scan can be watched in a browser. The
+// page reloads itself until the report written after the scan, under the
+// same name, replaces it. It implements scan.ProgressObserver.
+type LiveHTML struct {
+	reporter  *Reporter
+	filename  string
+	result    models.ScanResult
+	score     func(issues []models.Issue, totalItems int64) int
+	interval  time.Duration
+	lastWrite time.Time
+	err       error
+}
+
+// NewLiveHTML writes a first, empty live report, so an unwritable output
+// directory is reported before the scan starts. score gives the readiness
+// score of the issues found so far.
+func (r *Reporter) NewLiveHTML(scanPath, destination string, startTime time.Time, score func(issues []models.Issue, totalItems int64) int) (*LiveHTML, error) {
+	l := &LiveHTML{
+		reporter: r,
+		filename: r.defaultFilename("", ".html"),
+		result: models.ScanResult{
+			SchemaVersion:  models.SchemaVersion,
+			ScanPath:       scanPath,
+			DestinationURL: destination,
+			StartTime:      startTime,
+			Issues:         []models.Issue{},
+		},
+		score:    score,
+		interval: liveWriteInterval,
+	}
+	if err := l.write(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// SetInterval sets the minimum time between rewrites. Durations of 0 or
+// less keep the default of 10s.
+func (l *LiveHTML) SetInterval(d time.Duration) {
+	if d > 0 {
+		l.interval = d
+	}
+}
+
+// Filename returns the name of the report, for the final GenerateHTML
+func (l *LiveHTML) Filename() string {
+	return l.filename
+}
+
+// Path returns where the report is written
+func (l *LiveHTML) Path() string {
+	return filepath.Join(l.reporter.outputDir, l.filename)
+}
+
+// OnProgress records the totals and rewrites the report when the interval
+// has passed
+func (l *LiveHTML) OnProgress(progress *models.ScanProgress) {
+	l.result.TotalItems = progress.ItemsScanned
+	l.result.TotalFiles = progress.FilesScanned
+	l.result.TotalFolders = progress.DirsScanned
+	l.result.TotalSize = progress.BytesScanned
+	if time.Since(l.lastWrite) < l.interval {
+		return
+	}
+	l.update()
+}
+
+// OnIssue records an issue for the next rewrite
+func (l *LiveHTML) OnIssue(issue models.Issue) {
+	l.result.Issues = append(l.result.Issues, issue)
+}
+
+// OnDone does nothing; the complete report is written once the results
+// have been post-processed
+func (l *LiveHTML) OnDone(*models.ScanResult) {}
+
+// Err returns the first error writing the report, if any. Writes carry on
+// after an error, so a file that was briefly locked catches up.
+func (l *LiveHTML) Err() error {
+	return l.err
+}
+
+func (l *LiveHTML) update() {
+	if err := l.write(); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+func (l *LiveHTML) write() error {
+	now := time.Now()
+	l.lastWrite = now
+
+	result := &l.result
+	result.EndTime = now
+	result.Duration = now.Sub(result.StartTime)
+	result.DurationSeconds = math.Round(result.Duration.Seconds()*1000) / 1000
+	result.IssuesFound = len(result.Issues)
+	result.Summary = models.IssueSummary{
+		ByType:     make(map[models.IssueType]int),
+		BySeverity: make(map[models.Severity]int),
+	}
+	for _, issue := range result.Issues {
+		result.Summary.ByType[issue.Type]++
+		result.Summary.BySeverity[issue.Severity]++
+		if issue.ActionRequired {
+			result.Summary.ActionRequired++
+		} else {
+			result.Summary.AutoSkipped++
+		}
+	}
+	result.ReadinessScore = l.score(result.Issues, result.TotalItems)
+
+	r := l.reporter
+	html := generateHTMLContent(result, r.title, r.companyName, r.projectName, r.sortBy, r.remediation, r.includeTimestamp, liveRefreshSeconds)
+
+	// Replace the file in one step so a reload never shows a partial page
+	path := l.Path()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(html), 0644); err != nil {
+		return fmt.Errorf("failed to write live HTML report: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write live HTML report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// WriteMetrics writes the scan totals to path in the Prometheus text
+// exposition format, for the node_exporter textfile collector or any
+// scraper that reads files. Every metric carries the scan path as a label,
+// so scans of several shares can write side by side. Like the status
+// file, path is replaced in one step, so the collector never reads a
+// partial file.
+func WriteMetrics(path string, result *models.ScanResult) error {
+	var b strings.Builder
+	label := `path="` + escapeLabelValue(result.ScanPath) + `"`
+
+	gauge := func(name, help string, value interface{}) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		fmt.Fprintf(&b, "%s{%s} %v\n", name, label, value)
+	}
+
+	gauge("spready_items_total", "Files and folders scanned.", result.TotalItems)
+	gauge("spready_files_total", "Files scanned.", result.TotalFiles)
+	gauge("spready_folders_total", "Folders scanned.", result.TotalFolders)
+	if !result.SizesSkipped {
+		gauge("spready_bytes_total", "Total size of the files scanned, in bytes.", result.TotalSize)
+	}
+	gauge("spready_scan_duration_seconds", "Time the scan took.", result.Duration.Seconds())
+	gauge("spready_scan_end_timestamp_seconds", "When the scan finished, as a Unix time.", result.EndTime.Unix())
+	gauge("spready_readiness_score", "Migration readiness score, 0 to 100.", result.ReadinessScore)
+	gauge("spready_scan_errors_total", "Files and folders that could not be read.", len(result.Errors))
+	truncated := 0
+	if result.Truncated {
+		truncated = 1
+	}
+	gauge("spready_scan_truncated", "1 if the scan stopped at -max-items with items left unscanned.", truncated)
+
+	b.WriteString("# HELP spready_issues_total Issues found, by severity.\n# TYPE spready_issues_total gauge\n")
+	for _, severity := range models.Severities {
+		fmt.Fprintf(&b, "spready_issues_total{%s,severity=\"%s\"} %d\n", label, strings.ToLower(string(severity)), result.Summary.BySeverity[severity])
+	}
+
+	b.WriteString("# HELP spready_issues_by_type_total Issues found, by issue type.\n# TYPE spready_issues_by_type_total gauge\n")
+	for _, issueType := range sortedIssueTypes(result.Summary.ByType) {
+		fmt.Fprintf(&b, "spready_issues_by_type_total{%s,type=\"%s\"} %d\n", label, escapeLabelValue(string(issueType)), result.Summary.ByType[issueType])
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+
+	fmt.Printf("Metrics saved: %s\n", path)
+	return nil
+}
+
+// escapeLabelValue escapes a label value for the Prometheus text format,
+// in which backslashes, double quotes and line feeds must be escaped, such
+// as the backslashes of a Windows path
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,86 @@
+package reporter
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// OffenderTracker keeps the N longest paths, largest files and deepest
+// folders seen during a scan without retaining every item. It is not safe
+// for concurrent use; feed it from the goroutine that consumes scan results.
+type OffenderTracker struct {
+	limit          int
+	longestPaths   []models.RankedItem
+	largestFiles   []models.RankedItem
+	deepestFolders []models.RankedItem
+}
+
+// NewOffenderTracker creates a tracker that keeps the top limit items in
+// each ranking
+func NewOffenderTracker(limit int) *OffenderTracker {
+	return &OffenderTracker{limit: limit}
+}
+
+// Add records an item in each ranking it qualifies for
+func (t *OffenderTracker) Add(item *models.FileSystemItem) {
+	if t.limit <= 0 || item.RelativePath == "." {
+		return
+	}
+
+	t.longestPaths = t.insert(t.longestPaths, models.RankedItem{
+		Path:  item.Path,
+		Value: int64(utf8.RuneCountInString(item.RelativePath)),
+	})
+
+	if item.IsDir {
+		depth := strings.Count(item.RelativePath, string(os.PathSeparator)) + 1
+		t.deepestFolders = t.insert(t.deepestFolders, models.RankedItem{
+			Path:  item.Path,
+			Value: int64(depth),
+		})
+	} else {
+		t.largestFiles = t.insert(t.largestFiles, models.RankedItem{
+			Path:  item.Path,
+			Value: item.Size,
+		})
+	}
+}
+
+// Result returns the rankings collected so far
+func (t *OffenderTracker) Result() *models.TopOffenders {
+	return &models.TopOffenders{
+		LongestPaths:   t.longestPaths,
+		LargestFiles:   t.largestFiles,
+		DeepestFolders: t.deepestFolders,
+	}
+}
+
+// insert adds entry to a ranking sorted by value descending, then path
+// ascending, keeping at most limit entries
+func (t *OffenderTracker) insert(ranking []models.RankedItem, entry models.RankedItem) []models.RankedItem {
+	if len(ranking) == t.limit && !rankedBefore(entry, ranking[len(ranking)-1]) {
+		return ranking
+	}
+
+	if len(ranking) < t.limit {
+		ranking = append(ranking, entry)
+	} else {
+		ranking[len(ranking)-1] = entry
+	}
+
+	for i := len(ranking) - 1; i > 0 && rankedBefore(ranking[i], ranking[i-1]); i-- {
+		ranking[i], ranking[i-1] = ranking[i-1], ranking[i]
+	}
+
+	return ranking
+}
+
+func rankedBefore(a, b models.RankedItem) bool {
+	if a.Value != b.Value {
+		return a.Value > b.Value
+	}
+	return a.Path < b.Path
+}
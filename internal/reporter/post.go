@@ -0,0 +1,79 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// postAttempts is the number of times PostJSON tries to deliver a result
+const postAttempts = 4
+
+// PostJSON sends the result to url as a JSON POST body in the same format
+// as the JSON report. Network errors, 429 and 5xx responses are retried
+// with exponential backoff; timeout bounds the whole operation, including
+// retries.
+func PostJSON(ctx context.Context, url string, result *models.ScanResult, headers map[string]string, timeout time.Duration) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		retry, err := postOnce(ctx, url, body, headers)
+		if err == nil {
+			fmt.Printf("Results posted: %s\n", url)
+			return nil
+		}
+		if !retry || attempt == postAttempts {
+			return fmt.Errorf("failed to post results: %w", err)
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return fmt.Errorf("failed to post results: %w", err)
+		}
+	}
+}
+
+// postOnce makes a single POST request and reports whether a failure is
+// worth retrying
+func postOnce(ctx context.Context, url string, body []byte, headers map[string]string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("server returned %s", resp.Status)
+}
@@ -0,0 +1,151 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// remediationHintsShown is how many of the most common fixes are listed
+// for each issue type in Recommended Actions
+const remediationHintsShown = 3
+
+// remediationEffort estimates the work to fix one item of each issue type
+var remediationEffort = map[models.IssueType]string{
+	models.IssuePathLength:        "Medium: shorten names or flatten folders; renaming a folder moves everything inside it",
+	models.IssueInvalidCharacters: "Low: bulk rename, for example from a -rename-map review",
+	models.IssueReservedName:      "Low: rename each item",
+	models.IssueBlockedFileType:   "Medium: remove, zip or keep the files elsewhere",
+	models.IssueProblematicFile:   "Medium: review each file with its owner",
+	models.IssueFileSize:          "High: split, compress or move to other storage",
+	models.IssueNameConflict:      "Low: rename or merge one item of each pair",
+	models.IssueHiddenFile:        "Low: usually excluded from the migration",
+	models.IssueSystemFile:        "Low: usually excluded from the migration",
+	models.IssueCustomRule:        "Varies: see the rule's fix",
+	models.IssueCaseConflict:      "Low: rename or merge one item of each pair",
+	models.IssueFileLocked:        "Low: close the file or rescan later",
+	models.IssueReparsePoint:      "Medium: replace links with their content or a shortcut",
+	models.IssueExtensionMismatch: "Low: rename with the correct extension",
+	models.IssueAlternateStream:   "Low: copy out any stream data that must be kept",
+	models.IssueEmptyFolder:       "Low: delete or keep as needed",
+	models.IssueConfusableName:    "Low: rename one of the look-alike items",
+}
+
+// remediationAction is one issue type in Recommended Actions
+type remediationAction struct {
+	issueType models.IssueType
+	severity  models.Severity
+	count     int
+	hints     []hintCount
+}
+
+type hintCount struct {
+	hint  string
+	count int
+}
+
+// remediationPlan groups the distinct remediation hints by issue type,
+// counted from the per-type summary, worst severity first and then most
+// affected items first. Within a type, the most common hints come first.
+func remediationPlan(result *models.ScanResult) []remediationAction {
+	byType := make(map[models.IssueType]*remediationAction)
+	hints := make(map[models.IssueType]map[string]int)
+	for issueType, count := range result.Summary.ByType {
+		byType[issueType] = &remediationAction{issueType: issueType, severity: models.SeverityInfo, count: count}
+		hints[issueType] = make(map[string]int)
+	}
+
+	for _, issue := range result.Issues {
+		action, ok := byType[issue.Type]
+		if !ok {
+			continue
+		}
+		if severityRank(issue.Severity) < severityRank(action.severity) {
+			action.severity = issue.Severity
+		}
+		if issue.RemediationHint != "" {
+			hints[issue.Type][issue.RemediationHint]++
+		}
+	}
+
+	plan := make([]remediationAction, 0, len(byType))
+	for issueType, action := range byType {
+		for hint, count := range hints[issueType] {
+			action.hints = append(action.hints, hintCount{hint: hint, count: count})
+		}
+		sort.Slice(action.hints, func(i, j int) bool {
+			if action.hints[i].count != action.hints[j].count {
+				return action.hints[i].count > action.hints[j].count
+			}
+			return action.hints[i].hint < action.hints[j].hint
+		})
+		plan = append(plan, *action)
+	}
+
+	sort.Slice(plan, func(i, j int) bool {
+		if plan[i].severity != plan[j].severity {
+			return severityRank(plan[i].severity) < severityRank(plan[j].severity)
+		}
+		if plan[i].count != plan[j].count {
+			return plan[i].count > plan[j].count
+		}
+		return plan[i].issueType < plan[j].issueType
+	})
+	return plan
+}
+
+// generateRemediationHTML renders Recommended Actions, or nothing when
+// there are no issues
+func generateRemediationHTML(result *models.ScanResult) string {
+	plan := remediationPlan(result)
+	if len(plan) == 0 {
+		return ""
+	}
+
+	html := `
+        <h2>Recommended Actions</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>#</th>
+                    <th>Severity</th>
+                    <th>Type</th>
+                    <th>Affected</th>
+                    <th>Estimated Effort</th>
+                    <th>Fix</th>
+                </tr>
+            </thead>
+            <tbody>
+`
+	for i, action := range plan {
+		var fixes []string
+		for k, h := range action.hints {
+			if k == remediationHintsShown {
+				fixes = append(fixes, fmt.Sprintf("<small>and %d other fixes</small>", len(action.hints)-k))
+				break
+			}
+			fix := escapeHTML(h.hint)
+			if len(action.hints) > 1 {
+				fix += fmt.Sprintf(" <small>(%s)</small>", formatCount(h.count))
+			}
+			fixes = append(fixes, fix)
+		}
+
+		html += `                <tr>
+                    <td>` + fmt.Sprintf("%d", i+1) + `</td>
+                    <td><span class="severity-badge ` + string(action.severity) + `">` + string(action.severity) + `</span></td>
+                    <td>` + string(action.issueType) + `</td>
+                    <td>` + formatCount(action.count) + `</td>
+                    <td>` + escapeHTML(remediationEffort[action.issueType]) + `</td>
+                    <td>` + strings.Join(fixes, "<br>") + `</td>
+                </tr>
+`
+	}
+	html += `            </tbody>
+        </table>
+`
+
+	return html
+}
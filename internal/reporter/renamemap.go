@@ -0,0 +1,150 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// renameEntry is one row of the rename map
+type renameEntry struct {
+	path    string
+	isDir   bool
+	reasons []string
+}
+
+// GenerateRenameMap writes a CSV of OriginalPath,SuggestedName,Reason rows
+// for the items whose issues a rename fixes, as reported by renamable, to
+// be reviewed, edited and applied by other migration tools. Names come
+// from suggest. A suggestion that would collide with another suggestion
+// or an existing item in the same folder gets a numeric suffix, such as
+// "Report (2).docx". Rows are ordered deepest first, so renaming them in
+// order never changes a path further down the list. SuggestedName is
+// empty when no name can be derived and one must be chosen by hand.
+func (r *Reporter) GenerateRenameMap(result *models.ScanResult, renamable func(messageID string) bool, suggest func(name string, isDir bool) string, filename string) error {
+	if filename == "" {
+		filename = r.defaultFilename("-rename-map", ".csv")
+	}
+
+	outputPath := filepath.Join(r.outputDir, filename)
+
+	entries := make(map[string]*renameEntry)
+	for _, issue := range result.Issues {
+		if !issue.ActionRequired || !renamable(issue.MessageID) {
+			continue
+		}
+		entry, ok := entries[issue.Path]
+		if !ok {
+			entry = &renameEntry{path: issue.Path, isDir: issue.IsDirectory}
+			entries[issue.Path] = entry
+		}
+		if !containsString(entry.reasons, issue.Message) {
+			entry.reasons = append(entry.reasons, issue.Message)
+		}
+	}
+
+	sorted := make([]*renameEntry, 0, len(entries))
+	for _, entry := range entries {
+		sorted = append(sorted, entry)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		di, dj := pathDepth(sorted[i].path), pathDepth(sorted[j].path)
+		if di != dj {
+			return di > dj
+		}
+		return sorted[i].path < sorted[j].path
+	})
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create rename map file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"OriginalPath", "SuggestedName", "Reason"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	// Names suggested so far in each folder, lowercased
+	taken := make(map[string]map[string]bool)
+	for _, entry := range sorted {
+		name := filepath.Base(entry.path)
+		suggested := suggest(name, entry.isDir)
+		if suggested == name {
+			suggested = ""
+		}
+		if suggested != "" {
+			folder := filepath.Dir(entry.path)
+			if taken[folder] == nil {
+				taken[folder] = make(map[string]bool)
+			}
+			suggested = uniqueName(folder, name, suggested, entry.isDir, taken[folder])
+			taken[folder][strings.ToLower(suggested)] = true
+		}
+
+		row := []string{entry.path, suggested, strings.Join(entry.reasons, "; ")}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write rename map: %w", err)
+	}
+
+	fmt.Printf("Rename map saved: %s\n", outputPath)
+	return nil
+}
+
+// uniqueName returns suggested, or suggested with the first numeric suffix
+// that is neither taken nor the name of another item in folder. SharePoint
+// names are case-insensitive, so the item's own name in another case does
+// not count.
+func uniqueName(folder, original, suggested string, isDir bool, taken map[string]bool) string {
+	base, ext := suggested, ""
+	if !isDir {
+		ext = filepath.Ext(suggested)
+		base = strings.TrimSuffix(suggested, ext)
+	}
+
+	candidate := suggested
+	for n := 2; ; n++ {
+		if !taken[strings.ToLower(candidate)] && (strings.EqualFold(candidate, original) || !exists(filepath.Join(folder, candidate))) {
+			return candidate
+		}
+		candidate = base + " (" + strconv.Itoa(n) + ")" + ext
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// pathDepth counts the separators in a path
+func pathDepth(path string) int {
+	depth := 0
+	for i := 0; i < len(path); i++ {
+		if isPathSeparator(path[i]) {
+			depth++
+		}
+	}
+	return depth
+}
+
+func containsString(list []string, s string) bool {
+	for _, entry := range list {
+		if entry == s {
+			return true
+		}
+	}
+	return false
+}
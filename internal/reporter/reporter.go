@@ -4,69 +4,267 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"html"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
 )
 
+// DefaultFilenameTemplate names reports when no template is configured
+const DefaultFilenameTemplate = "sp-readiness-{timestamp}"
+
+// DefaultReportTitle heads the HTML report when no title is configured
+const DefaultReportTitle = "SharePoint Readiness Report"
+
 // Reporter generates reports from scan results
 type Reporter struct {
-	outputDir string
+	outputDir        string
+	filenameTemplate string
+	title            string
+	companyName      string
+	projectName      string
+	scanRoot         string
+	sortBy           string
+	remediation      bool
+	includeTimestamp bool
+	timestamp        time.Time
 }
 
+// Issue orders for SetSort
+const (
+	SortSeverity = "severity" // Worst first, then by path
+	SortPath     = "path"     // By path, so each folder's issues are together
+	SortSize     = "size"     // Largest first, then by severity and path
+	SortType     = "type"     // By issue type, then by severity and path
+)
+
 // NewReporter creates a new Reporter instance
 func NewReporter(outputDir string) *Reporter {
 	return &Reporter{
-		outputDir: outputDir,
+		outputDir:        outputDir,
+		filenameTemplate: DefaultFilenameTemplate,
+		title:            DefaultReportTitle,
+		sortBy:           SortSeverity,
+		remediation:      true,
+		includeTimestamp: true,
+		timestamp:        time.Now(),
 	}
 }
 
-// GenerateJSON creates a JSON report file
-func (r *Reporter) GenerateJSON(result *models.ScanResult, filename string) error {
-	if filename == "" {
-		filename = fmt.Sprintf("sp-readiness-%s.json", time.Now().Format("20060102-150405"))
+// SetFilenameTemplate configures how default report filenames are built.
+// Supported tokens are {timestamp}, {company}, {project} and {root}
+// (the base name of the scanned folder).
+func (r *Reporter) SetFilenameTemplate(template, companyName, projectName, scanRoot string) {
+	if template != "" {
+		r.filenameTemplate = template
 	}
+	r.companyName = companyName
+	r.projectName = projectName
+	r.scanRoot = scanRoot
+}
 
-	outputPath := filepath.Join(r.outputDir, filename)
+// SetTitle sets the title that heads the HTML report. The company and
+// project names given to SetFilenameTemplate are shown beneath it.
+func (r *Reporter) SetTitle(title string) {
+	if title != "" {
+		r.title = title
+	}
+}
 
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create JSON file: %w", err)
+// SetSort sets the order of issues in the CSV and HTML reports, one of
+// the Sort* keys. Issues that need action always come before those migration
+// tools skip. "" keeps SortSeverity.
+func (r *Reporter) SetSort(key string) {
+	if key != "" {
+		r.sortBy = key
 	}
-	defer file.Close()
+}
+
+// SetRemediation sets whether the HTML report starts with Recommended
+// Actions, the remediation hints grouped by issue type
+func (r *Reporter) SetRemediation(include bool) {
+	r.remediation = include
+}
+
+// SetTimestamp sets whether report filenames and the HTML report carry
+// the time of the run. Without it, filenames are fixed and rescanning an
+// unchanged tree writes the same CSV and HTML reports, so successive
+// reports can be compared with diff.
+func (r *Reporter) SetTimestamp(include bool) {
+	r.includeTimestamp = include
+}
+
+// defaultFilename expands the filename template and appends suffix and ext
+func (r *Reporter) defaultFilename(suffix, ext string) string {
+	root := filepath.Base(r.scanRoot)
+	if r.scanRoot == "" || root == "." || root == string(filepath.Separator) {
+		root = ""
+	}
+
+	template, fallback := r.filenameTemplate, DefaultFilenameTemplate
+	if !r.includeTimestamp {
+		template, fallback = withoutTimestamp(template), withoutTimestamp(fallback)
+	}
+
+	name := strings.NewReplacer(
+		"{timestamp}", r.timestamp.Format("20060102-150405"),
+		"{company}", r.companyName,
+		"{project}", r.projectName,
+		"{root}", root,
+	).Replace(template)
+
+	name = sanitizeFilename(name)
+	if name == "" {
+		name = sanitizeFilename(strings.ReplaceAll(fallback, "{timestamp}", r.timestamp.Format("20060102-150405")))
+	}
+
+	return name + suffix + ext
+}
+
+// SeverityFilename returns the default filename of a report limited to
+// one severity, such as "sp-readiness-20250101-120000-critical.csv". The
+// timestamp is added when the filename template leaves it out, so each
+// run writes new files, unless SetTimestamp turned timestamps off.
+func (r *Reporter) SeverityFilename(severity models.Severity, ext string) string {
+	suffix := "-" + strings.ToLower(string(severity))
+	if r.includeTimestamp && !strings.Contains(r.filenameTemplate, "{timestamp}") {
+		suffix += "-" + r.timestamp.Format("20060102-150405")
+	}
+	return r.defaultFilename(suffix, ext)
+}
+
+// withoutTimestamp removes the {timestamp} token from a filename template,
+// along with a separator next to it, so "sp-readiness-{timestamp}"
+// becomes "sp-readiness"
+func withoutTimestamp(template string) string {
+	for _, sep := range []string{"-", "_", ".", " "} {
+		template = strings.ReplaceAll(template, sep+"{timestamp}", "")
+		template = strings.ReplaceAll(template, "{timestamp}"+sep, "")
+	}
+	return strings.ReplaceAll(template, "{timestamp}", "")
+}
+
+// sanitizeFilename removes path separators and characters that are not
+// valid in file names on Windows, and trims trailing dots and spaces.
+func sanitizeFilename(name string) string {
+	var b strings.Builder
+	for _, ch := range name {
+		switch {
+		case ch < 0x20:
+			continue
+		case strings.ContainsRune(`<>:"/\|?*`, ch):
+			b.WriteRune('-')
+		default:
+			b.WriteRune(ch)
+		}
+	}
+	return strings.Trim(b.String(), " .-")
+}
 
-	encoder := json.NewEncoder(file)
+// GenerateJSON creates a JSON report file
+func (r *Reporter) GenerateJSON(result *models.ScanResult, filename string) error {
+	return r.Generate(FormatJSON, result, filename)
+}
+
+// jsonWriter writes the scan result as indented JSON
+type jsonWriter struct{}
+
+func (jsonWriter) Extension() string { return ".json" }
+
+func (jsonWriter) Write(result *models.ScanResult, w io.Writer) error {
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 
 	if err := encoder.Encode(result); err != nil {
 		return fmt.Errorf("failed to encode JSON: %w", err)
 	}
-
-	fmt.Printf("JSON report saved: %s\n", outputPath)
 	return nil
 }
 
-// GenerateCSV creates a CSV report file
+// GenerateCSV creates a CSV report file, sorted as set by SetSort
 func (r *Reporter) GenerateCSV(result *models.ScanResult, filename string) error {
-	if filename == "" {
-		filename = fmt.Sprintf("sp-readiness-%s.csv", time.Now().Format("20060102-150405"))
+	return r.Generate(FormatCSV, result, filename)
+}
+
+// csvWriter writes one row per issue. The sort orders indexes into
+// result.Issues rather than a copy of it, so large results are not held
+// in memory twice.
+type csvWriter struct {
+	sortBy string
+}
+
+func (csvWriter) Extension() string { return ".csv" }
+
+func (c csvWriter) Write(result *models.ScanResult, w io.Writer) error {
+	issues := result.Issues
+	order := sortIssues(issues, c.sortBy)
+
+	stream, err := newCSVStream(w)
+	if err != nil {
+		return err
 	}
+	for _, i := range order {
+		stream.writeIssue(&issues[i])
+	}
+	return stream.finish()
+}
 
-	outputPath := filepath.Join(r.outputDir, filename)
+// writeIssuesCSV writes issues, in the given order, to a CSV file
+func writeIssuesCSV(outputPath string, issues []models.Issue) error {
+	stream, err := openCSVStream(outputPath)
+	if err != nil {
+		return err
+	}
+	stream.Write(issues)
+	return stream.finish()
+}
 
+// CSVStream writes the CSV report as issues arrive, in the order they are
+// given, instead of sorting the full result at the end. Write errors are
+// held until Close.
+type CSVStream struct {
+	path   string
+	file   *os.File
+	writer *csv.Writer
+	err    error
+}
+
+// StreamCSV creates the CSV report file and writes its header. Rows are
+// added with Write and the file is completed by Close.
+func (r *Reporter) StreamCSV(filename string) (*CSVStream, error) {
+	if filename == "" {
+		filename = r.defaultFilename("", ".csv")
+	}
+	return openCSVStream(filepath.Join(r.outputDir, filename))
+}
+
+func openCSVStream(outputPath string) (*CSVStream, error) {
 	file, err := os.Create(outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to create CSV file: %w", err)
+		return nil, fmt.Errorf("failed to create CSV file: %w", err)
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	stream, err := newCSVStream(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	stream.path = outputPath
+	stream.file = file
+	return stream, nil
+}
+
+// newCSVStream writes the CSV header to w and returns a stream for the
+// rows. finish flushes it without closing w.
+func newCSVStream(w io.Writer) (*CSVStream, error) {
+	stream := &CSVStream{writer: csv.NewWriter(w)}
 
-	// Write header
 	header := []string{
 		"Path",
 		"Type",
@@ -77,66 +275,245 @@ func (r *Reporter) GenerateCSV(result *models.ScanResult, filename string) error
 		"Size",
 		"IsDirectory",
 		"RemediationHint",
+		"CurrentLength",
+		"LimitPercent",
+		"Code",
+		"ActionRequired",
 	}
-	if err := writer.Write(header); err != nil {
-		return fmt.Errorf("failed to write CSV header: %w", err)
+	if err := stream.writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
-	// Sort issues by severity and type
-	sortedIssues := make([]models.Issue, len(result.Issues))
-	copy(sortedIssues, result.Issues)
-	sort.Slice(sortedIssues, func(i, j int) bool {
-		if sortedIssues[i].Severity != sortedIssues[j].Severity {
-			return severityRank(sortedIssues[i].Severity) < severityRank(sortedIssues[j].Severity)
-		}
-		return sortedIssues[i].Path < sortedIssues[j].Path
+	return stream, nil
+}
+
+// Write appends one row per issue
+func (s *CSVStream) Write(issues []models.Issue) {
+	for i := range issues {
+		s.writeIssue(&issues[i])
+	}
+}
+
+func (s *CSVStream) writeIssue(issue *models.Issue) {
+	if s.err != nil {
+		return
+	}
+	s.err = s.writer.Write([]string{
+		issue.Path,
+		string(issue.Type),
+		string(issue.Severity),
+		issue.Message,
+		issue.Details,
+		issue.Category,
+		formatBytes(issue.Size),
+		formatBool(issue.IsDirectory),
+		issue.RemediationHint,
+		formatOptionalInt(issue.CurrentLength),
+		formatOptionalPercent(issue.LimitPercent),
+		issue.Code,
+		formatBool(issue.ActionRequired),
 	})
+}
 
-	// Write data rows
-	for _, issue := range sortedIssues {
-		row := []string{
-			issue.Path,
-			string(issue.Type),
-			string(issue.Severity),
-			issue.Message,
-			issue.Details,
-			issue.Category,
-			formatBytes(issue.Size),
-			formatBool(issue.IsDirectory),
-			issue.RemediationHint,
-		}
-		if err := writer.Write(row); err != nil {
-			return fmt.Errorf("failed to write CSV row: %w", err)
-		}
+// Flush writes the rows buffered so far to the file
+func (s *CSVStream) Flush() error {
+	if s.err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", s.err)
+	}
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil {
+		s.err = err
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the file and reports the first write error
+func (s *CSVStream) Close() error {
+	if err := s.finish(); err != nil {
+		return err
 	}
 
-	fmt.Printf("CSV report saved: %s\n", outputPath)
+	fmt.Printf("CSV report saved: %s\n", s.path)
 	return nil
 }
 
-// GenerateHTML creates an HTML report file
-func (r *Reporter) GenerateHTML(result *models.ScanResult, filename string) error {
+func (s *CSVStream) finish() error {
+	s.writer.Flush()
+	if s.err == nil {
+		s.err = s.writer.Error()
+	}
+	var closeErr error
+	if s.file != nil {
+		closeErr = s.file.Close()
+	}
+
+	if s.err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", s.err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write CSV file: %w", closeErr)
+	}
+	return nil
+}
+
+// GenerateManifestCSV streams every scanned item to a CSV file as it is
+// received, so the full item list never has to be held in memory. It
+// returns once items is closed. On a write error the channel is still
+// drained so the sender never blocks.
+func (r *Reporter) GenerateManifestCSV(items <-chan *models.FileSystemItem, filename string) error {
 	if filename == "" {
-		filename = fmt.Sprintf("sp-readiness-%s.html", time.Now().Format("20060102-150405"))
+		filename = r.defaultFilename("-manifest", ".csv")
 	}
 
 	outputPath := filepath.Join(r.outputDir, filename)
 
 	file, err := os.Create(outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to create HTML file: %w", err)
+		for range items {
+		}
+		return fmt.Errorf("failed to create manifest file: %w", err)
 	}
 	defer file.Close()
 
-	html := generateHTMLContent(result)
-	if _, err := file.WriteString(html); err != nil {
-		return fmt.Errorf("failed to write HTML content: %w", err)
+	writer := csv.NewWriter(file)
+
+	header := []string{
+		"Path",
+		"RelativePath",
+		"Name",
+		"Type",
+		"SizeBytes",
+		"Modified",
+		"IsHidden",
+		"IsSystem",
+	}
+	writeErr := writer.Write(header)
+
+	for item := range items {
+		if writeErr != nil {
+			continue
+		}
+
+		itemType := "File"
+		if item.IsDir {
+			itemType = "Folder"
+		}
+
+		writeErr = writer.Write([]string{
+			item.Path,
+			item.RelativePath,
+			item.Name,
+			itemType,
+			strconv.FormatInt(item.Size, 10),
+			item.ModTime.Format(time.RFC3339),
+			formatBool(item.IsHidden),
+			formatBool(item.IsSystem),
+		})
+	}
+
+	if writeErr != nil {
+		return fmt.Errorf("failed to write manifest row: %w", writeErr)
 	}
 
-	fmt.Printf("HTML report saved: %s\n", outputPath)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	fmt.Printf("Manifest saved: %s\n", outputPath)
 	return nil
 }
 
+// GenerateHTML creates an HTML report file
+func (r *Reporter) GenerateHTML(result *models.ScanResult, filename string) error {
+	return r.Generate(FormatHTML, result, filename)
+}
+
+// htmlWriter writes the HTML report with the Reporter's title, names,
+// sort and sections
+type htmlWriter struct {
+	title       string
+	companyName string
+	projectName string
+	sortBy      string
+	remediation bool
+	timestamp   bool
+}
+
+func (htmlWriter) Extension() string { return ".html" }
+
+func (h htmlWriter) Write(result *models.ScanResult, w io.Writer) error {
+	html := generateHTMLContent(result, h.title, h.companyName, h.projectName, h.sortBy, h.remediation, h.timestamp, 0)
+	if _, err := io.WriteString(w, html); err != nil {
+		return fmt.Errorf("failed to write HTML content: %w", err)
+	}
+	return nil
+}
+
+// sortIssues returns the indexes of issues in report order: those that
+// need action first, then by the sort key, with ties broken by severity,
+// path and code so the order does not depend on the scan
+func sortIssues(issues []models.Issue, key string) []int {
+	order := make([]int, len(issues))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		x, y := &issues[order[a]], &issues[order[b]]
+		if x.ActionRequired != y.ActionRequired {
+			return x.ActionRequired
+		}
+
+		switch key {
+		case SortPath:
+			if x.Path != y.Path {
+				return pathLess(x.Path, y.Path)
+			}
+		case SortSize:
+			if x.Size != y.Size {
+				return x.Size > y.Size
+			}
+		case SortType:
+			if x.Type != y.Type {
+				return x.Type < y.Type
+			}
+		}
+
+		if x.Severity != y.Severity {
+			return severityRank(x.Severity) < severityRank(y.Severity)
+		}
+		if x.Path != y.Path {
+			return pathLess(x.Path, y.Path)
+		}
+		return x.Code < y.Code
+	})
+	return order
+}
+
+// pathLess orders paths folder by folder, so a folder is followed by
+// everything below it before a sibling such as "Folder 2" that sorts
+// between them character by character
+func pathLess(a, b string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		if isPathSeparator(a[i]) != isPathSeparator(b[i]) {
+			return isPathSeparator(a[i])
+		}
+		return a[i] < b[i]
+	}
+	return len(a) < len(b)
+}
+
+// isPathSeparator reports whether c separates names in a path on this
+// system. On Linux and macOS a backslash is part of a name.
+func isPathSeparator(c byte) bool {
+	return c == '/' || c == os.PathSeparator
+}
+
 func severityRank(severity models.Severity) int {
 	switch severity {
 	case models.SeverityCritical:
@@ -169,6 +546,18 @@ func formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// totalSizeText formats the total size of a scan, which is not measured
+// when the walk skipped reading sizes
+func totalSizeText(result *models.ScanResult) string {
+	if result.SizesSkipped {
+		return "not measured"
+	}
+	if size := formatBytes(result.TotalSize); size != "" {
+		return size
+	}
+	return "0 B"
+}
+
 func formatBool(b bool) string {
 	if b {
 		return "Yes"
@@ -176,6 +565,22 @@ func formatBool(b bool) string {
 	return "No"
 }
 
+// formatOptionalInt leaves zero values blank so numeric CSV columns only
+// hold values for the issues they apply to
+func formatOptionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func formatOptionalPercent(p float64) string {
+	if p == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(p, 'f', 1, 64)
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Second {
 		return fmt.Sprintf("%dms", d.Milliseconds())
@@ -189,23 +594,237 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%.1fh", d.Hours())
 }
 
-func generateHTMLContent(result *models.ScanResult) string {
-	// Sort issues by severity
-	sortedIssues := make([]models.Issue, len(result.Issues))
-	copy(sortedIssues, result.Issues)
-	sort.Slice(sortedIssues, func(i, j int) bool {
-		if sortedIssues[i].Severity != sortedIssues[j].Severity {
-			return severityRank(sortedIssues[i].Severity) < severityRank(sortedIssues[j].Severity)
-		}
-		return sortedIssues[i].Path < sortedIssues[j].Path
+// generateExtensionBreakdownHTML renders the per-extension counts, or
+// nothing when no issues carried a size
+func generateExtensionBreakdownHTML(stats []models.ExtensionStat) string {
+	if len(stats) == 0 {
+		return ""
+	}
+
+	html := `
+        <h2>Issues by Extension</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>Extension</th>
+                    <th>Files</th>
+                    <th>Total Size</th>
+                </tr>
+            </thead>
+            <tbody>
+`
+	for _, stat := range stats {
+		html += `                <tr>
+                    <td>` + escapeHTML(stat.Extension) + `</td>
+                    <td>` + fmt.Sprintf("%d", stat.Count) + `</td>
+                    <td>` + formatBytes(stat.TotalBytes) + `</td>
+                </tr>
+`
+	}
+	html += `            </tbody>
+        </table>
+`
+
+	return html
+}
+
+// generateTopOffendersHTML renders the Top Offenders section, or nothing
+// when no rankings were collected
+func generateTopOffendersHTML(offenders *models.TopOffenders) string {
+	if offenders == nil {
+		return ""
+	}
+
+	html := `
+        <h2>Top Offenders</h2>
+`
+	html += generateRankingHTML("Longest Paths", "Characters", offenders.LongestPaths, func(v int64) string {
+		return fmt.Sprintf("%d", v)
+	})
+	html += generateRankingHTML("Largest Files", "Size", offenders.LargestFiles, formatBytes)
+	html += generateRankingHTML("Deepest Folders", "Depth", offenders.DeepestFolders, func(v int64) string {
+		return fmt.Sprintf("%d", v)
 	})
 
+	return html
+}
+
+func generateRankingHTML(title, valueHeading string, ranking []models.RankedItem, formatValue func(int64) string) string {
+	if len(ranking) == 0 {
+		return ""
+	}
+
+	html := `        <h3>` + title + `</h3>
+        <table>
+            <thead>
+                <tr>
+                    <th>#</th>
+                    <th>Path</th>
+                    <th>` + valueHeading + `</th>
+                </tr>
+            </thead>
+            <tbody>
+`
+	for i, entry := range ranking {
+		html += `                <tr>
+                    <td>` + fmt.Sprintf("%d", i+1) + `</td>
+                    <td class="path">` + escapeHTML(entry.Path) + `</td>
+                    <td>` + formatValue(entry.Value) + `</td>
+                </tr>
+`
+	}
+	html += `            </tbody>
+        </table>
+`
+
+	return html
+}
+
+// readinessColor returns the gauge color for a readiness score: green from
+// 80, orange from 50 and red below
+func readinessColor(score int) string {
+	switch {
+	case score >= 80:
+		return "#107c10"
+	case score >= 50:
+		return "#ff8c00"
+	default:
+		return "#d13438"
+	}
+}
+
+// formatIssueCode renders an issue code below the issue type
+func formatIssueCode(code string) string {
+	if code == "" {
+		return ""
+	}
+	return `<br><small class="code">` + escapeHTML(code) + `</small>`
+}
+
+// escapeHTML escapes s for use as HTML text. Control characters such as
+// newlines in file names are replaced with their Unicode control pictures
+// (U+2400 block) so they stay visible instead of breaking the layout.
+func escapeHTML(s string) string {
+	return html.EscapeString(replaceControlCharacters(s))
+}
+
+// replaceControlCharacters replaces C0 control characters and DEL with
+// the matching Unicode control picture
+func replaceControlCharacters(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20:
+			return 0x2400 + r
+		case r == 0x7F:
+			return 0x2421
+		}
+		return r
+	}, s)
+}
+
+// htmlSubtitle joins the company and project names shown under the
+// report title, skipping either when unset
+func htmlSubtitle(companyName, projectName string) string {
+	var parts []string
+	for _, part := range []string{companyName, projectName} {
+		if part != "" {
+			parts = append(parts, escapeHTML(part))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return `
+        <div class="subtitle">` + strings.Join(parts, " &middot; ") + `</div>`
+}
+
+// sortedIssueTypes returns the keys of a per-type count by name
+func sortedIssueTypes(counts map[models.IssueType]int) []models.IssueType {
+	types := make([]models.IssueType, 0, len(counts))
+	for issueType := range counts {
+		types = append(types, issueType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// htmlGenerated shows when the report was generated, or nothing when
+// timestamps are turned off
+func htmlGenerated(result *models.ScanResult, timestamp bool) string {
+	if !timestamp {
+		return ""
+	}
+	return `
+        <div class="timestamp">Generated: ` + result.EndTime.Format("2006-01-02 15:04:05") + `</div>`
+}
+
+// htmlTruncatedNotice warns that a scan stopped at -max-items, so a
+// capped scan is not mistaken for a complete one
+func htmlTruncatedNotice(result *models.ScanResult) string {
+	if !result.Truncated {
+		return ""
+	}
+	return `
+        <div class="truncated">Results truncated at ` + fmt.Sprintf("%d", result.ItemLimit) + ` items (-max-items). Items beyond the limit were not scanned, so this is not a complete scan.</div>`
+}
+
+// htmlRootNotice warns about problems with the scan root's own name,
+// which are not among the issues below
+func htmlRootNotice(result *models.ScanResult) string {
+	html := ""
+	for _, issue := range result.RootIssues {
+		text := issue.Message
+		if issue.Details != "" {
+			text += " (" + issue.Details + ")"
+		}
+		html += `
+        <div class="root-name">Scan root name: ` + escapeHTML(text) + `. Rename it before naming the destination library or folder after it.</div>`
+	}
+	return html
+}
+
+// htmlLiveNotice marks a report rewritten while the scan runs, which
+// reloads itself every refresh seconds
+func htmlLiveNotice(result *models.ScanResult, refresh int) string {
+	if refresh <= 0 {
+		return ""
+	}
+	return `
+        <div class="live">Scan in progress: ` + formatCount(int(result.TotalItems)) + ` items scanned so far. This page reloads every ` + fmt.Sprintf("%d", refresh) + ` seconds, and the complete report replaces it when the scan finishes.</div>`
+}
+
+// htmlIssueGroupRow heads the issues that need action, or those that
+// migration tools skip on their own
+func htmlIssueGroupRow(actionRequired bool, summary models.IssueSummary) string {
+	label := fmt.Sprintf("Action Required (%d)", summary.ActionRequired)
+	if !actionRequired {
+		label = fmt.Sprintf("Will Be Skipped Automatically (%d)", summary.AutoSkipped)
+	}
+	return `                <tr class="group"><th colspan="5">` + label + `</th></tr>
+`
+}
+
+// generateHTMLContent builds the HTML report. With refresh above 0 it is
+// a live report that reloads itself every refresh seconds.
+func generateHTMLContent(result *models.ScanResult, title, companyName, projectName, sortBy string, remediation, timestamp bool, refresh int) string {
+	refreshMeta := ""
+	if refresh > 0 {
+		refreshMeta = `
+    <meta http-equiv="refresh" content="` + fmt.Sprintf("%d", refresh) + `">`
+	}
+
+	// Sort issues by whether they need action, then by the sort key
+	sortedIssues := make([]models.Issue, len(result.Issues))
+	for k, i := range sortIssues(result.Issues, sortBy) {
+		sortedIssues[k] = result.Issues[i]
+	}
+
 	html := `<!DOCTYPE html>
 <html lang="en">
 <head>
     <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>SharePoint Readiness Report</title>
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">` + refreshMeta + `
+    <title>` + escapeHTML(title) + `</title>
     <style>
         * { margin: 0; padding: 0; box-sizing: border-box; }
         body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; padding: 20px; background: #f5f5f5; }
@@ -227,29 +846,50 @@ func generateHTMLContent(result *models.ScanResult) string {
         table { width: 100%; border-collapse: collapse; margin: 20px 0; }
         th, td { padding: 12px; text-align: left; border-bottom: 1px solid #ddd; }
         th { background: #0078d4; color: white; font-weight: 600; position: sticky; top: 0; }
+        tr.group th { background: #edebe9; color: #323130; position: static; }
         tr:hover { background: #f9f9f9; }
         .severity-badge { display: inline-block; padding: 4px 12px; border-radius: 4px; font-size: 12px; font-weight: 600; text-transform: uppercase; }
         .severity-badge.critical { background: #d13438; color: white; }
         .severity-badge.warning { background: #ff8c00; color: white; }
         .severity-badge.info { background: #0078d4; color: white; }
         .path { font-family: 'Consolas', 'Courier New', monospace; font-size: 12px; word-break: break-all; }
+        .code { font-family: 'Consolas', 'Courier New', monospace; color: #666; white-space: nowrap; }
         .filter-bar { margin: 20px 0; padding: 15px; background: #f9f9f9; border-radius: 6px; display: flex; gap: 15px; flex-wrap: wrap; align-items: center; }
         .filter-bar input { padding: 8px 12px; border: 1px solid #ddd; border-radius: 4px; flex: 1; min-width: 200px; }
         .filter-bar select { padding: 8px 12px; border: 1px solid #ddd; border-radius: 4px; background: white; }
+        .subtitle { color: #333; font-size: 18px; margin-bottom: 5px; }
         .timestamp { color: #666; font-size: 14px; margin-bottom: 20px; }
+        .secrets { background: #fde7e9; border-left: 4px solid #d13438; padding: 12px 15px; border-radius: 4px; font-weight: 600; }
+        .live { background: #deecf9; border-left: 4px solid #0078d4; padding: 12px 15px; border-radius: 4px; margin-bottom: 20px; font-weight: 600; }
+        .truncated, .root-name { background: #fff4ce; border-left: 4px solid #ff8c00; padding: 12px 15px; border-radius: 4px; margin-bottom: 20px; font-weight: 600; }
+        .readiness { display: flex; align-items: center; gap: 15px; margin-bottom: 20px; }
+        .readiness .label { font-size: 14px; color: #666; text-transform: uppercase; }
+        .readiness .gauge { flex: 1; max-width: 400px; height: 16px; background: #e5e5e5; border-radius: 8px; overflow: hidden; }
+        .readiness .gauge-fill { height: 100%; }
+        .readiness .score { font-size: 28px; font-weight: bold; }
         @media print { .filter-bar { display: none; } }
     </style>
 </head>
 <body>
     <div class="container">
-        <h1>SharePoint Readiness Report</h1>
-        <div class="timestamp">Generated: ` + result.EndTime.Format("2006-01-02 15:04:05") + `</div>
+        <h1>` + escapeHTML(title) + `</h1>` + htmlSubtitle(companyName, projectName) + htmlGenerated(result, timestamp) + htmlLiveNotice(result, refresh) + htmlTruncatedNotice(result) + htmlRootNotice(result) + `
+        <div class="readiness">
+            <span class="label">Readiness</span>
+            <div class="gauge"><div class="gauge-fill" style="width: ` + fmt.Sprintf("%d", result.ReadinessScore) + `%; background: ` + readinessColor(result.ReadinessScore) + `;"></div></div>
+            <span class="score" style="color: ` + readinessColor(result.ReadinessScore) + `;">` + fmt.Sprintf("%d", result.ReadinessScore) + `/100</span>
+        </div>
+`
+	if remediation {
+		html += generateRemediationHTML(result)
+	}
+
+	html += `
 
         <h2>Scan Summary</h2>
         <div class="summary">
             <div class="summary-card">
                 <h3>Scan Path</h3>
-                <div class="value" style="font-size: 16px;">` + result.ScanPath + `</div>
+                <div class="value" style="font-size: 16px;">` + escapeHTML(result.ScanPath) + `</div>
             </div>
             <div class="summary-card">
                 <h3>Total Items</h3>
@@ -265,13 +905,17 @@ func generateHTMLContent(result *models.ScanResult) string {
             </div>
             <div class="summary-card">
                 <h3>Total Size</h3>
-                <div class="value" style="font-size: 20px;">` + formatBytes(result.TotalSize) + `</div>
+                <div class="value" style="font-size: 20px;">` + totalSizeText(result) + `</div>
             </div>
-            <div class="summary-card">
+`
+	if timestamp {
+		html += `            <div class="summary-card">
                 <h3>Scan Duration</h3>
                 <div class="value" style="font-size: 20px;">` + formatDuration(result.Duration) + `</div>
             </div>
-        </div>
+`
+	}
+	html += `        </div>
 
         <h2>Issues Found: ` + fmt.Sprintf("%d", result.IssuesFound) + `</h2>
         <div class="severity-summary">
@@ -288,22 +932,48 @@ func generateHTMLContent(result *models.ScanResult) string {
                 <span class="label">Info</span>
             </div>
         </div>
+`
+	if result.Summary.AutoSkipped > 0 {
+		html += `        <div class="timestamp">` + fmt.Sprintf("%d", result.Summary.ActionRequired) + ` require action; ` + fmt.Sprintf("%d", result.Summary.AutoSkipped) + ` will be skipped automatically by migration tools</div>
+`
+	}
+	if result.Suppressed > 0 {
+		html += `        <div class="timestamp">` + fmt.Sprintf("%d", result.Suppressed) + ` more issues were suppressed by the ignore file</div>
+`
+	}
+	if result.Spilled > 0 {
+		html += `        <div class="timestamp">` + fmt.Sprintf("%d", result.Spilled) + ` of the issues are only in the streamed CSV report; they were dropped from memory to stay within -max-memory</div>
+`
+	}
+	if len(result.AcceptedCategories) > 0 {
+		html += `        <div class="timestamp">Accepted categories (warnings and info not reported): ` + escapeHTML(strings.Join(result.AcceptedCategories, ", ")) + `</div>
+`
+	}
 
+	html += generatePotentialSecretsHTML(result.PotentialSecrets)
+
+	html += `
         <h2>Issues by Type</h2>
         <div class="summary">
 `
 
-	// Add issue type summary
-	for issueType, count := range result.Summary.ByType {
+	// Add issue type summary, in a fixed order so reports of the same
+	// results are identical
+	for _, issueType := range sortedIssueTypes(result.Summary.ByType) {
 		html += `            <div class="summary-card">
                 <h3>` + string(issueType) + `</h3>
-                <div class="value">` + fmt.Sprintf("%d", count) + `</div>
+                <div class="value">` + fmt.Sprintf("%d", result.Summary.ByType[issueType]) + `</div>
             </div>
 `
 	}
 
 	html += `        </div>
+`
 
+	html += generateExtensionBreakdownHTML(result.ByExtension)
+	html += generateTopOffendersHTML(result.TopOffenders)
+
+	html += `
         <h2>Issue Details</h2>
         <div class="filter-bar">
             <input type="text" id="searchBox" placeholder="Search paths..." onkeyup="filterTable()">
@@ -318,11 +988,11 @@ func generateHTMLContent(result *models.ScanResult) string {
 `
 
 	// Add unique issue types to filter
-	typeSet := make(map[models.IssueType]bool)
+	typeSet := make(map[models.IssueType]int)
 	for _, issue := range sortedIssues {
-		typeSet[issue.Type] = true
+		typeSet[issue.Type]++
 	}
-	for issueType := range typeSet {
+	for _, issueType := range sortedIssueTypes(typeSet) {
 		html += `                <option value="` + string(issueType) + `">` + string(issueType) + `</option>
 `
 	}
@@ -343,27 +1013,60 @@ func generateHTMLContent(result *models.ScanResult) string {
             <tbody>
 `
 
-	// Add issue rows
-	for _, issue := range sortedIssues {
+	// Add issue rows. They are collected separately, since appending each
+	// row to html would copy the whole report so far every time.
+	var rows strings.Builder
+	for i, issue := range sortedIssues {
+		if i == 0 || issue.ActionRequired != sortedIssues[i-1].ActionRequired {
+			rows.WriteString(htmlIssueGroupRow(issue.ActionRequired, result.Summary))
+		}
 		severityClass := string(issue.Severity)
 		severityClass = severityClass[:1] + string(severityClass[1:])[:]
-		html += `                <tr>
+		rows.WriteString(`                <tr>
                     <td><span class="severity-badge ` + string(issue.Severity) + `">` + string(issue.Severity) + `</span></td>
-                    <td>` + string(issue.Type) + `</td>
-                    <td class="path">` + issue.Path + `</td>
-                    <td>` + issue.Message + `</td>
-                    <td>` + issue.Details
+                    <td>` + string(issue.Type) + formatIssueCode(issue.Code) + `</td>
+                    <td class="path">` + escapeHTML(issue.Path) + `</td>
+                    <td>` + escapeHTML(issue.Message) + `</td>
+                    <td>` + escapeHTML(issue.Details))
 		if issue.RemediationHint != "" {
-			html += `<br><small><strong>Fix:</strong> ` + issue.RemediationHint + `</small>`
+			rows.WriteString(`<br><small><strong>Fix:</strong> ` + escapeHTML(issue.RemediationHint) + `</small>`)
 		}
-		html += `</td>
+		rows.WriteString(`</td>
                 </tr>
-`
+`)
 	}
+	html += rows.String()
 
 	html += `            </tbody>
         </table>
-    </div>
+`
+
+	// Add paths that could not be scanned
+	if len(result.Errors) > 0 {
+		html += `
+        <h2>Scan Errors: ` + fmt.Sprintf("%d", len(result.Errors)) + `</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>Path</th>
+                    <th>Error</th>
+                </tr>
+            </thead>
+            <tbody>
+`
+		for _, scanErr := range result.Errors {
+			html += `                <tr>
+                    <td class="path">` + escapeHTML(scanErr.Path) + `</td>
+                    <td>` + escapeHTML(scanErr.Message) + `</td>
+                </tr>
+`
+		}
+		html += `            </tbody>
+        </table>
+`
+	}
+
+	html += `    </div>
 
     <script>
         function filterTable() {
@@ -375,6 +1078,9 @@ func generateHTMLContent(result *models.ScanResult) string {
 
             for (let i = 1; i < rows.length; i++) {
                 const row = rows[i];
+                if (row.classList.contains('group')) {
+                    continue;
+                }
                 const severity = row.cells[0].textContent.trim();
                 const type = row.cells[1].textContent;
                 const path = row.cells[2].textContent.toLowerCase();
@@ -0,0 +1,175 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// benchIssues is the number of issues the CSV benchmarks write
+const benchIssues = 200000
+
+// benchIssue returns the i-th synthetic issue, spread over severities and
+// folders so sorting has work to do
+func benchIssue(i int) models.Issue {
+	return models.Issue{
+		Path:     fmt.Sprintf("/share/Department %03d/Quarterly report %06d.docx", i%997, i),
+		Type:     models.IssuePathLength,
+		Severity: models.Severities[i%len(models.Severities)],
+		Message:  "Path is approaching the 400-character limit",
+		Details:  "Path is 372 of 400 characters",
+		Size:     int64(i),
+	}
+}
+
+// peakHeap samples the live heap while fn runs and returns the largest
+// value seen, in bytes
+func peakHeap(fn func()) uint64 {
+	runtime.GC()
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	var peak uint64
+	read := func() {
+		metrics.Read(sample)
+		if v := sample[0].Value.Uint64(); v > peak {
+			peak = v
+		}
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				read()
+			}
+		}
+	}()
+
+	fn()
+	close(stop)
+	wg.Wait()
+	read()
+	return peak
+}
+
+// BenchmarkCSVSorted writes the CSV report the way GenerateCSV does: the
+// whole result is held in memory and written in sorted order
+func BenchmarkCSVSorted(b *testing.B) {
+	b.ReportAllocs()
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		p := peakHeap(func() {
+			result := &models.ScanResult{Issues: make([]models.Issue, 0, benchIssues)}
+			for j := 0; j < benchIssues; j++ {
+				result.Issues = append(result.Issues, benchIssue(j))
+			}
+			if err := (csvWriter{sortBy: SortSeverity}).Write(result, io.Discard); err != nil {
+				b.Fatal(err)
+			}
+		})
+		if p > peak {
+			peak = p
+		}
+	}
+	b.ReportMetric(float64(peak)/(1<<20), "peak-MB")
+}
+
+// BenchmarkCSVStream writes the same issues through a CSVStream in
+// batches as they are produced, the way -stream-csv does, so only one
+// batch is held at a time
+func BenchmarkCSVStream(b *testing.B) {
+	b.ReportAllocs()
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		p := peakHeap(func() {
+			stream, err := newCSVStream(io.Discard)
+			if err != nil {
+				b.Fatal(err)
+			}
+			batch := make([]models.Issue, 0, 1000)
+			for j := 0; j < benchIssues; j++ {
+				batch = append(batch, benchIssue(j))
+				if len(batch) == cap(batch) {
+					stream.Write(batch)
+					batch = batch[:0]
+				}
+			}
+			stream.Write(batch)
+			if err := stream.finish(); err != nil {
+				b.Fatal(err)
+			}
+		})
+		if p > peak {
+			peak = p
+		}
+	}
+	b.ReportMetric(float64(peak)/(1<<20), "peak-MB")
+}
+
+func TestSortIssues(t *testing.T) {
+	// Message labels each issue. G matches A on every sort field, so it
+	// must stay after A, and F needs no action, so it always comes last.
+	issues := []models.Issue{
+		{Message: "A", Path: "/share/b.txt", Type: models.IssueInvalidCharacters, Severity: models.SeverityCritical, Size: 10, Code: "SPO-CHAR-001", ActionRequired: true},
+		{Message: "B", Path: "/share/a/z.txt", Type: models.IssuePathLength, Severity: models.SeverityWarning, Size: 500, Code: "SPO-PATH-003", ActionRequired: true},
+		{Message: "C", Path: "/share/a/z.txt", Type: models.IssueFileSize, Severity: models.SeverityCritical, Size: 500, Code: "SPO-SIZE-001", ActionRequired: true},
+		{Message: "D", Path: "/share/a 2.txt", Type: models.IssueInvalidCharacters, Severity: models.SeverityInfo, Code: "SPO-CHAR-002", ActionRequired: true},
+		{Message: "E", Path: "/share/a/z.txt", Type: models.IssueInvalidCharacters, Severity: models.SeverityCritical, Size: 500, Code: "SPO-CHAR-001", ActionRequired: true},
+		{Message: "F", Path: "/share/0.txt", Type: models.IssuePathLength, Severity: models.SeverityCritical, Size: 900, Code: "SPO-PATH-001"},
+		{Message: "G", Path: "/share/b.txt", Type: models.IssueInvalidCharacters, Severity: models.SeverityCritical, Size: 10, Code: "SPO-CHAR-001", ActionRequired: true},
+	}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		// Critical first, then by path folder by folder, then by code
+		{SortSeverity, "ECAGBDF"},
+		// a/z.txt sorts before "a 2.txt", as its folder comes first
+		{SortPath, "ECBDAGF"},
+		// Largest first, ties by severity, path and code
+		{SortSize, "ECBAGDF"},
+		// FileSize, InvalidCharacters, PathLength, ties by severity and path
+		{SortType, "CEAGDBF"},
+	}
+	for _, tt := range tests {
+		var got strings.Builder
+		for _, i := range sortIssues(issues, tt.key) {
+			got.WriteString(issues[i].Message)
+		}
+		if got.String() != tt.want {
+			t.Errorf("sort by %s = %s, want %s", tt.key, got.String(), tt.want)
+		}
+	}
+
+	// The order does not depend on the order issues were found in
+	reversed := make([]models.Issue, len(issues))
+	for i, issue := range issues {
+		reversed[len(issues)-1-i] = issue
+	}
+	for _, tt := range tests {
+		var got strings.Builder
+		for _, i := range sortIssues(reversed, tt.key) {
+			got.WriteString(reversed[i].Message)
+		}
+		// Only A and G, which tie on every key, swap
+		want := strings.NewReplacer("AG", "GA").Replace(tt.want)
+		if got.String() != want {
+			t.Errorf("sort by %s of reversed issues = %s, want %s", tt.key, got.String(), want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package reporter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/reporter"
+	"github.com/ajoshuasmith/sharepoint-prescan/scan"
+)
+
+// TestJSONReportMatchesSchema scans a small tree with a variety of issues
+// and checks the JSON report against schema/scan-result.schema.json. Only
+// the keywords the schema uses are supported. Properties the report emits
+// but the schema does not declare are reported too, so new fields cannot
+// go undocumented.
+func TestJSONReportMatchesSchema(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"bad:name.txt",
+		"CON.txt",
+		"setup.exe",
+		"id_rsa",
+		"~$port.docx",
+		".hidden",
+		"Report.txt",
+		"report.txt",
+		filepath.Join("folder", "nested", "file.pst"),
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(root, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	offenders := reporter.NewOffenderTracker(5)
+	result, err := scan.Run(context.Background(), scan.Options{
+		Path:        root,
+		Destination: "https://contoso.sharepoint.com/sites/IT/Shared Documents",
+		Checks:      map[string]bool{"CaseConflicts": true},
+		OnItem:      offenders.Add,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) == 0 {
+		t.Fatal("scan found no issues to validate")
+	}
+	result.TopOffenders = offenders.Result()
+	result.ByExtension = reporter.ExtensionBreakdown(result.Issues, 10)
+	result.PotentialSecrets = reporter.PotentialSecrets(result.Issues)
+	result.Errors = []models.ScanError{{Path: filepath.Join(root, "locked"), Message: "permission denied"}}
+
+	writer, err := reporter.NewReporter(t.TempDir()).Writer(reporter.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writer.Write(result, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var report interface{}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	schema := loadSchema(t)
+	for _, problem := range validateSchema(schema, schema, report, "$") {
+		t.Error(problem)
+	}
+}
+
+func loadSchema(t *testing.T) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "schema", "scan-result.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	return schema
+}
+
+// validateSchema returns the places where value does not match schema.
+// root resolves "#/$defs/..." references.
+func validateSchema(root, schema map[string]interface{}, value interface{}, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		def := root
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			def, _ = def[part].(map[string]interface{})
+		}
+		if def == nil {
+			return []string{fmt.Sprintf("%s: unresolved $ref %s", at, ref)}
+		}
+		return validateSchema(root, def, value, at)
+	}
+
+	if types, ok := schema["type"]; ok && !matchesType(types, value) {
+		return []string{fmt.Sprintf("%s: %v is not of type %v", at, value, types)}
+	}
+
+	var problems []string
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if allowed == value {
+				found = true
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", at, value, enum))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(v) {
+			problems = append(problems, fmt.Sprintf("%s: %q does not match %s", at, v, pattern))
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %q is not a date-time", at, v))
+			}
+		}
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok && v < minimum {
+			problems = append(problems, fmt.Sprintf("%s: %v is below the minimum %v", at, v, minimum))
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && v > maximum {
+			problems = append(problems, fmt.Sprintf("%s: %v is above the maximum %v", at, v, maximum))
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				problems = append(problems, validateSchema(root, items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := v[name.(string)]; !ok {
+					problems = append(problems, fmt.Sprintf("%s: missing required property %s", at, name))
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, hasAdditional := schema["additionalProperties"].(map[string]interface{})
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			path := at + "." + name
+			if property, ok := properties[name].(map[string]interface{}); ok {
+				problems = append(problems, validateSchema(root, property, v[name], path)...)
+			} else if hasAdditional {
+				problems = append(problems, validateSchema(root, additional, v[name], path)...)
+			} else if properties != nil {
+				problems = append(problems, fmt.Sprintf("%s: property is not declared in the schema", path))
+			}
+		}
+	}
+	return problems
+}
+
+func matchesType(types interface{}, value interface{}) bool {
+	list, ok := types.([]interface{})
+	if !ok {
+		list = []interface{}{types}
+	}
+	for _, name := range list {
+		switch name {
+		case "object":
+			if _, ok := value.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]interface{}); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "number":
+			if _, ok := value.(float64); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := value.(float64); ok && n == float64(int64(n)) {
+				return true
+			}
+		case "null":
+			if value == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
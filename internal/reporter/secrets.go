@@ -0,0 +1,61 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// PotentialSecrets returns the issues for files whose names suggest keys
+// or credentials, sorted by path, so they can be listed apart from the
+// rest of the scan
+func PotentialSecrets(issues []models.Issue) []models.Issue {
+	var secrets []models.Issue
+	for _, issue := range issues {
+		if isSecretsIssue(issue) {
+			secrets = append(secrets, issue)
+		}
+	}
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return secrets[i].Path < secrets[j].Path
+	})
+	return secrets
+}
+
+func isSecretsIssue(issue models.Issue) bool {
+	return issue.Type == models.IssueProblematicFile && issue.Category == models.CategorySecrets
+}
+
+// generatePotentialSecretsHTML renders the Potential Secrets section, or
+// nothing when no names matched
+func generatePotentialSecretsHTML(secrets []models.Issue) string {
+	if len(secrets) == 0 {
+		return ""
+	}
+
+	html := `
+        <h2>Potential Secrets: ` + fmt.Sprintf("%d", len(secrets)) + `</h2>
+        <div class="secrets">These files have names used for keys, certificates and credentials. Review each one before migrating: move real secrets to a vault or delete them, and rotate any that were shared. Only names are matched; file contents were not read.</div>
+        <table>
+            <thead>
+                <tr>
+                    <th>Path</th>
+                    <th>Size</th>
+                </tr>
+            </thead>
+            <tbody>
+`
+	for _, issue := range secrets {
+		html += `                <tr>
+                    <td class="path">` + escapeHTML(issue.Path) + `</td>
+                    <td>` + formatBytes(issue.Size) + `</td>
+                </tr>
+`
+	}
+	html += `            </tbody>
+        </table>
+`
+
+	return html
+}
@@ -0,0 +1,130 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// statusWriteInterval is how often the status file is rewritten while
+// the scan runs unless SetInterval changes it; updates in between are
+// dropped
+const statusWriteInterval = 500 * time.Millisecond
+
+// Status is the content of a status file
+type Status struct {
+	StartTime      time.Time `json:"startTime"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	ElapsedSeconds float64   `json:"elapsedSeconds"`
+	ItemsScanned   int64     `json:"itemsScanned"`
+	FilesScanned   int64     `json:"filesScanned"`
+	FoldersScanned int64     `json:"foldersScanned"`
+	BytesScanned   int64     `json:"bytesScanned"`
+	IssuesFound    int       `json:"issuesFound"`
+	CurrentPath    string    `json:"currentPath,omitempty"`
+	Done           bool      `json:"done"`
+}
+
+// StatusFile keeps a JSON status file up to date with the latest progress
+// update, for unattended scans that are watched by polling a file. Each
+// write replaces the file whole, so readers never see a partial one. It
+// implements scan.ProgressObserver.
+type StatusFile struct {
+	path      string
+	status    Status
+	interval  time.Duration
+	lastWrite time.Time
+	err       error
+}
+
+// NewStatusFile writes an initial status to path, so an unwritable path
+// is reported before the scan starts
+func NewStatusFile(path string, startTime time.Time) (*StatusFile, error) {
+	s := &StatusFile{
+		path:     path,
+		status:   Status{StartTime: startTime},
+		interval: statusWriteInterval,
+	}
+	if err := s.write(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetInterval sets the minimum time between writes while the scan runs.
+// Durations of 0 or less keep the default of 500ms.
+func (s *StatusFile) SetInterval(d time.Duration) {
+	if d > 0 {
+		s.interval = d
+	}
+}
+
+// OnProgress writes the update
+func (s *StatusFile) OnProgress(progress *models.ScanProgress) {
+	if time.Since(s.lastWrite) < s.interval {
+		return
+	}
+	s.status.ItemsScanned = progress.ItemsScanned
+	s.status.FilesScanned = progress.FilesScanned
+	s.status.FoldersScanned = progress.DirsScanned
+	s.status.BytesScanned = progress.BytesScanned
+	s.status.IssuesFound = progress.IssuesFound
+	s.status.CurrentPath = progress.CurrentPath
+	s.update()
+}
+
+// OnIssue does nothing; the count comes with each progress update
+func (s *StatusFile) OnIssue(models.Issue) {}
+
+// OnDone writes the final totals with Done set
+func (s *StatusFile) OnDone(result *models.ScanResult) {
+	if result != nil {
+		s.status.ItemsScanned = result.TotalItems
+		s.status.FilesScanned = result.TotalFiles
+		s.status.FoldersScanned = result.TotalFolders
+		s.status.BytesScanned = result.TotalSize
+		s.status.IssuesFound = result.IssuesFound
+	}
+	s.status.CurrentPath = ""
+	s.status.Done = true
+	s.update()
+}
+
+// Err returns the first error writing the file, if any. Writes carry on
+// after an error, so a file that was briefly locked catches up.
+func (s *StatusFile) Err() error {
+	return s.err
+}
+
+func (s *StatusFile) update() {
+	if err := s.write(); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *StatusFile) write() error {
+	now := time.Now()
+	s.lastWrite = now
+	s.status.UpdatedAt = now
+	s.status.ElapsedSeconds = math.Round(now.Sub(s.status.StartTime).Seconds()*1000) / 1000
+
+	data, err := json.MarshalIndent(s.status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+
+	// Replace the file in one step so pollers never read a partial status
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write status file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write status file: %w", err)
+	}
+	return nil
+}
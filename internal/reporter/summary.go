@@ -0,0 +1,133 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// Summary formats accepted by FormatSummary
+const (
+	SummaryPlain = "plain"
+	SummarySlack = "slack"
+	SummaryTeams = "teams"
+)
+
+// FormatSummary renders a short, uncolored scan summary for chat
+// notifications. plain is text for pasting into a message, slack is a
+// Block Kit payload and teams is a MessageCard payload.
+func FormatSummary(result *models.ScanResult, format string) (string, error) {
+	critical := result.Summary.BySeverity[models.SeverityCritical]
+	warning := result.Summary.BySeverity[models.SeverityWarning]
+	info := result.Summary.BySeverity[models.SeverityInfo]
+
+	status := "✅ Ready"
+	color := "2EB886"
+	if critical > 0 {
+		status = "🔴 Not ready"
+		color = "D13438"
+	} else if warning > 0 {
+		status = "🟠 Review needed"
+		color = "FF8C00"
+	}
+
+	topType := "none"
+	if issueType, count := topIssueType(result.Summary); count > 0 {
+		topType = fmt.Sprintf("%s (%s)", issueType, formatCount(count))
+	}
+
+	size := totalSizeText(result)
+
+	items := fmt.Sprintf("%s (%s)", formatCount(int(result.TotalItems)), size)
+	if result.Truncated {
+		items += fmt.Sprintf(", truncated at %s items", formatCount(int(result.ItemLimit)))
+	}
+
+	facts := [][2]string{
+		{"Path", replaceControlCharacters(result.ScanPath)},
+		{"Readiness", fmt.Sprintf("%d/100", result.ReadinessScore)},
+		{"Items", items},
+		{"Issues", fmt.Sprintf("🔴 %s critical · 🟠 %s warning · 🔵 %s info", formatCount(critical), formatCount(warning), formatCount(info))},
+		{"Top issue", topType},
+	}
+	if n := len(result.PotentialSecrets); n > 0 {
+		facts = append(facts, [2]string{"Potential secrets", formatCount(n)})
+	}
+	title := "SharePoint readiness: " + status
+
+	switch format {
+	case SummaryPlain:
+		lines := []string{title}
+		for _, fact := range facts {
+			lines = append(lines, fact[0]+": "+fact[1])
+		}
+		return strings.Join(lines, "\n") + "\n", nil
+
+	case SummarySlack:
+		fields := make([]map[string]string, 0, len(facts))
+		for _, fact := range facts {
+			fields = append(fields, map[string]string{"type": "mrkdwn", "text": "*" + fact[0] + "*\n" + fact[1]})
+		}
+		return marshalSummary(map[string]interface{}{
+			"text": title,
+			"blocks": []interface{}{
+				map[string]interface{}{
+					"type": "header",
+					"text": map[string]string{"type": "plain_text", "text": title},
+				},
+				map[string]interface{}{
+					"type":   "section",
+					"fields": fields,
+				},
+			},
+		})
+
+	case SummaryTeams:
+		teamsFacts := make([]map[string]string, 0, len(facts))
+		for _, fact := range facts {
+			teamsFacts = append(teamsFacts, map[string]string{"name": fact[0], "value": fact[1]})
+		}
+		return marshalSummary(map[string]interface{}{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    title,
+			"themeColor": color,
+			"title":      title,
+			"sections": []interface{}{
+				map[string]interface{}{"facts": teamsFacts},
+			},
+		})
+	}
+
+	return "", fmt.Errorf("unknown summary format %q (expected plain, slack or teams)", format)
+}
+
+func marshalSummary(payload interface{}) (string, error) {
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode summary: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// topIssueType returns the most frequent issue type, breaking ties by name
+func topIssueType(summary models.IssueSummary) (models.IssueType, int) {
+	types := make([]models.IssueType, 0, len(summary.ByType))
+	for issueType := range summary.ByType {
+		types = append(types, issueType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if summary.ByType[types[i]] != summary.ByType[types[j]] {
+			return summary.ByType[types[i]] > summary.ByType[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	if len(types) == 0 {
+		return "", 0
+	}
+	return types[0], summary.ByType[types[0]]
+}
@@ -0,0 +1,153 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// Verification statuses, in the order they are listed
+const (
+	VerifyPresent    = "Still present"
+	VerifyNew        = "New"
+	VerifyResolved   = "Resolved"
+	VerifyUnverified = "Not verified"
+)
+
+var verifyStatusOrder = map[string]int{
+	VerifyPresent:    0,
+	VerifyNew:        1,
+	VerifyResolved:   2,
+	VerifyUnverified: 3,
+}
+
+// VerifiedIssue is an issue from an earlier report, or one found since at
+// the same paths, with whether the rescan still finds it
+type VerifiedIssue struct {
+	Status      string
+	CurrentPath string // Where the item is now, or "" when it is gone or was not re-checked
+	Issue       models.Issue
+}
+
+// LoadJSON reads a JSON report written by GenerateJSON
+func LoadJSON(path string) (*models.ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.ScanResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON report %s: %w", path, err)
+	}
+	if result.ScanPath == "" {
+		return nil, fmt.Errorf("%s is not a scan report: no scanPath", path)
+	}
+	return &result, nil
+}
+
+// VerifyIssues compares the issues of an earlier report with the issues
+// found by re-checking its paths. current maps each earlier path that was
+// re-checked to where it was found, its renamed equivalent, or "" when it
+// no longer exists. Issues on paths missing from current, and those skip
+// returns true for, are Not verified; found issues skip returns true for
+// are left out. Issues match on path, type and code.
+func VerifyIssues(previous, found []models.Issue, current map[string]string, skip func(models.Issue) bool) []VerifiedIssue {
+	type key struct {
+		path      string
+		issueType models.IssueType
+		code      string
+	}
+
+	remaining := make(map[key]int)
+	for _, issue := range found {
+		remaining[key{issue.Path, issue.Type, issue.Code}]++
+	}
+
+	var verified []VerifiedIssue
+	for _, issue := range previous {
+		path, ok := current[issue.Path]
+		switch {
+		case !ok || skip(issue):
+			verified = append(verified, VerifiedIssue{Status: VerifyUnverified, Issue: issue})
+		case path != "" && remaining[key{path, issue.Type, issue.Code}] > 0:
+			remaining[key{path, issue.Type, issue.Code}]--
+			verified = append(verified, VerifiedIssue{Status: VerifyPresent, CurrentPath: path, Issue: issue})
+		default:
+			verified = append(verified, VerifiedIssue{Status: VerifyResolved, CurrentPath: path, Issue: issue})
+		}
+	}
+
+	for _, issue := range found {
+		k := key{issue.Path, issue.Type, issue.Code}
+		if remaining[k] > 0 && !skip(issue) {
+			remaining[k]--
+			verified = append(verified, VerifiedIssue{Status: VerifyNew, CurrentPath: issue.Path, Issue: issue})
+		}
+	}
+
+	sort.SliceStable(verified, func(i, j int) bool {
+		if verified[i].Status != verified[j].Status {
+			return verifyStatusOrder[verified[i].Status] < verifyStatusOrder[verified[j].Status]
+		}
+		return verified[i].Issue.Path < verified[j].Issue.Path
+	})
+	return verified
+}
+
+// CountVerified counts verified issues by status
+func CountVerified(verified []VerifiedIssue) map[string]int {
+	counts := make(map[string]int)
+	for _, v := range verified {
+		counts[v.Status]++
+	}
+	return counts
+}
+
+// GenerateVerifyCSV writes the outcome of each verified issue to a CSV
+// file, still present and new issues first
+func (r *Reporter) GenerateVerifyCSV(verified []VerifiedIssue, filename string) error {
+	if filename == "" {
+		filename = r.defaultFilename("-verify", ".csv")
+	}
+
+	outputPath := filepath.Join(r.outputDir, filename)
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create verification file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"Status", "Path", "CurrentPath", "Type", "Severity", "Code", "Message", "Details"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, v := range verified {
+		row := []string{
+			v.Status,
+			v.Issue.Path,
+			v.CurrentPath,
+			string(v.Issue.Type),
+			string(v.Issue.Severity),
+			v.Issue.Code,
+			v.Issue.Message,
+			v.Issue.Details,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	fmt.Printf("Verification report saved: %s\n", outputPath)
+	return nil
+}
@@ -0,0 +1,104 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// Built-in report formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatHTML = "html"
+	FormatXML  = "xml"
+)
+
+// ReportWriter writes a scan result in one report format
+type ReportWriter interface {
+	// Write writes the whole report for result to w
+	Write(result *models.ScanResult, w io.Writer) error
+
+	// Extension is the file extension of the report, such as ".json"
+	Extension() string
+}
+
+// formats builds the writer for each format name from the Reporter's
+// settings, such as the sort order and the HTML title
+var formats = map[string]func(r *Reporter) ReportWriter{
+	FormatJSON: func(*Reporter) ReportWriter { return jsonWriter{} },
+	FormatCSV:  func(r *Reporter) ReportWriter { return csvWriter{sortBy: r.sortBy} },
+	FormatHTML: func(r *Reporter) ReportWriter {
+		return htmlWriter{
+			title:       r.title,
+			companyName: r.companyName,
+			projectName: r.projectName,
+			sortBy:      r.sortBy,
+			remediation: r.remediation,
+			timestamp:   r.includeTimestamp,
+		}
+	},
+	FormatXML: func(*Reporter) ReportWriter { return xmlWriter{} },
+}
+
+// RegisterFormat adds a report format under a lowercase name, or replaces
+// the one already there. Call it from an init function; the registry is
+// not safe for concurrent use.
+func RegisterFormat(name string, newWriter func(r *Reporter) ReportWriter) {
+	formats[strings.ToLower(name)] = newWriter
+}
+
+// Formats returns the names of the registered report formats, sorted
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Writer returns the writer for a format, set up with the Reporter's
+// settings. Format names ignore case.
+func (r *Reporter) Writer(format string) (ReportWriter, error) {
+	newWriter, ok := formats[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown report format %q (expected one of %s)", format, strings.Join(Formats(), ", "))
+	}
+	return newWriter(r), nil
+}
+
+// Generate creates a report file in the given format. An empty filename
+// uses the default name with the format's extension.
+func (r *Reporter) Generate(format string, result *models.ScanResult, filename string) error {
+	writer, err := r.Writer(format)
+	if err != nil {
+		return err
+	}
+	if filename == "" {
+		filename = r.defaultFilename("", writer.Extension())
+	}
+
+	outputPath := filepath.Join(r.outputDir, filename)
+	label := strings.ToUpper(format)
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s file: %w", label, err)
+	}
+	if err := writer.Write(result, file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s file: %w", label, err)
+	}
+
+	fmt.Printf("%s report saved: %s\n", label, outputPath)
+	return nil
+}
@@ -0,0 +1,240 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// xmlReport is the XML form of a ScanResult. Element names follow the
+// JSON field names. Maps become one element per entry, sorted by key, and
+// the duration is written in seconds and ISO 8601 rather than nanoseconds.
+type xmlReport struct {
+	XMLName            xml.Name           `xml:"scanResult"`
+	SchemaVersion      string             `xml:"schemaVersion,attr"`
+	ScanPath           string             `xml:"scanPath"`
+	DestinationURL     string             `xml:"destinationUrl,omitempty"`
+	StartTime          time.Time          `xml:"startTime"`
+	EndTime            time.Time          `xml:"endTime"`
+	DurationSeconds    float64            `xml:"durationSeconds"`
+	DurationISO        string             `xml:"durationIso"`
+	TotalItems         int64              `xml:"totalItems"`
+	TotalFiles         int64              `xml:"totalFiles"`
+	TotalFolders       int64              `xml:"totalFolders"`
+	TotalSize          int64              `xml:"totalSize"`
+	SizesSkipped       bool               `xml:"sizesSkipped,omitempty"`
+	IssuesFound        int                `xml:"issuesFound"`
+	Truncated          bool               `xml:"truncated,omitempty"`
+	ItemLimit          int64              `xml:"itemLimit,omitempty"`
+	ReadinessScore     int                `xml:"readinessScore"`
+	Suppressed         int                `xml:"suppressed,omitempty"`
+	Spilled            int                `xml:"spilled,omitempty"`
+	AcceptedCategories *xmlCategories     `xml:"acceptedCategories,omitempty"`
+	OnlyTypes          *xmlTypes          `xml:"onlyTypes,omitempty"`
+	Summary            xmlSummary         `xml:"summary"`
+	Issues             []xmlIssue         `xml:"issues>issue"`
+	Errors             *xmlScanErrors     `xml:"errors,omitempty"`
+	TopOffenders       *xmlTopOffenders   `xml:"topOffenders,omitempty"`
+	ByExtension        *xmlExtensionStats `xml:"byExtension,omitempty"`
+	PotentialSecrets   *xmlIssueList      `xml:"potentialSecrets,omitempty"`
+	RootIssues         *xmlIssueList      `xml:"rootIssues,omitempty"`
+}
+
+// The optional lists are wrapped in structs behind pointers, because
+// encoding/xml writes the parent of an "a>b" path even when the list is
+// empty
+
+type xmlCategories struct {
+	Categories []string `xml:"category"`
+}
+
+type xmlTypes struct {
+	Types []models.IssueType `xml:"type"`
+}
+
+type xmlScanErrors struct {
+	Errors []xmlScanError `xml:"error"`
+}
+
+type xmlExtensionStats struct {
+	Extensions []xmlExtensionStat `xml:"extension"`
+}
+
+type xmlIssueList struct {
+	Issues []xmlIssue `xml:"issue"`
+}
+
+type xmlSummary struct {
+	ByType         []xmlCount `xml:"byType>count"`
+	BySeverity     []xmlCount `xml:"bySeverity>count"`
+	ActionRequired int        `xml:"actionRequired"`
+	AutoSkipped    int        `xml:"autoSkipped"`
+}
+
+// xmlCount is one entry of a summary map, <count key="...">n</count>
+type xmlCount struct {
+	Key   string `xml:"key,attr"`
+	Value int    `xml:",chardata"`
+}
+
+type xmlIssue struct {
+	Path            string           `xml:"path"`
+	Type            models.IssueType `xml:"type"`
+	Code            string           `xml:"code,omitempty"`
+	Severity        models.Severity  `xml:"severity"`
+	Message         string           `xml:"message"`
+	MessageID       string           `xml:"messageId,omitempty"`
+	Details         string           `xml:"details,omitempty"`
+	Category        string           `xml:"category,omitempty"`
+	Size            int64            `xml:"size,omitempty"`
+	Count           int              `xml:"count,omitempty"`
+	CurrentLength   int              `xml:"currentLength,omitempty"`
+	LimitPercent    float64          `xml:"limitPercent,omitempty"`
+	IsDirectory     bool             `xml:"isDirectory"`
+	RemediationHint string           `xml:"remediationHint,omitempty"`
+	ActionRequired  bool             `xml:"actionRequired"`
+}
+
+type xmlScanError struct {
+	Path    string `xml:"path"`
+	Message string `xml:"message"`
+	Retries int    `xml:"retries,omitempty"`
+}
+
+type xmlTopOffenders struct {
+	LongestPaths   []xmlRankedItem `xml:"longestPaths>item"`
+	LargestFiles   []xmlRankedItem `xml:"largestFiles>item"`
+	DeepestFolders []xmlRankedItem `xml:"deepestFolders>item"`
+}
+
+type xmlRankedItem struct {
+	Path  string `xml:"path"`
+	Value int64  `xml:"value"`
+}
+
+type xmlExtensionStat struct {
+	Extension  string `xml:"name,attr"`
+	Count      int    `xml:"count"`
+	TotalBytes int64  `xml:"totalBytes"`
+}
+
+// GenerateXML creates an XML report file with the same content as the
+// JSON report, for tools that only ingest XML
+func (r *Reporter) GenerateXML(result *models.ScanResult, filename string) error {
+	return r.Generate(FormatXML, result, filename)
+}
+
+// xmlWriter writes the XML report
+type xmlWriter struct{}
+
+func (xmlWriter) Extension() string { return ".xml" }
+
+func (xmlWriter) Write(result *models.ScanResult, w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write XML file: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(newXMLReport(result)); err != nil {
+		return fmt.Errorf("failed to encode XML: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write XML file: %w", err)
+	}
+	return nil
+}
+
+func newXMLReport(result *models.ScanResult) *xmlReport {
+	report := &xmlReport{
+		SchemaVersion:   result.SchemaVersion,
+		ScanPath:        result.ScanPath,
+		DestinationURL:  result.DestinationURL,
+		StartTime:       result.StartTime,
+		EndTime:         result.EndTime,
+		DurationSeconds: result.DurationSeconds,
+		DurationISO:     result.DurationISO,
+		TotalItems:      result.TotalItems,
+		TotalFiles:      result.TotalFiles,
+		TotalFolders:    result.TotalFolders,
+		TotalSize:       result.TotalSize,
+		SizesSkipped:    result.SizesSkipped,
+		IssuesFound:     result.IssuesFound,
+		Truncated:       result.Truncated,
+		ItemLimit:       result.ItemLimit,
+		ReadinessScore:  result.ReadinessScore,
+		Suppressed:      result.Suppressed,
+		Spilled:         result.Spilled,
+		Issues:          xmlIssues(result.Issues),
+	}
+	if len(result.AcceptedCategories) > 0 {
+		report.AcceptedCategories = &xmlCategories{Categories: result.AcceptedCategories}
+	}
+	if len(result.OnlyTypes) > 0 {
+		report.OnlyTypes = &xmlTypes{Types: result.OnlyTypes}
+	}
+	if len(result.PotentialSecrets) > 0 {
+		report.PotentialSecrets = &xmlIssueList{Issues: xmlIssues(result.PotentialSecrets)}
+	}
+	if len(result.RootIssues) > 0 {
+		report.RootIssues = &xmlIssueList{Issues: xmlIssues(result.RootIssues)}
+	}
+
+	for issueType, n := range result.Summary.ByType {
+		report.Summary.ByType = append(report.Summary.ByType, xmlCount{Key: string(issueType), Value: n})
+	}
+	for severity, n := range result.Summary.BySeverity {
+		report.Summary.BySeverity = append(report.Summary.BySeverity, xmlCount{Key: string(severity), Value: n})
+	}
+	report.Summary.ActionRequired = result.Summary.ActionRequired
+	report.Summary.AutoSkipped = result.Summary.AutoSkipped
+	sortXMLCounts(report.Summary.ByType)
+	sortXMLCounts(report.Summary.BySeverity)
+
+	if t := result.TopOffenders; t != nil {
+		report.TopOffenders = &xmlTopOffenders{
+			LongestPaths:   xmlRankedItems(t.LongestPaths),
+			LargestFiles:   xmlRankedItems(t.LargestFiles),
+			DeepestFolders: xmlRankedItems(t.DeepestFolders),
+		}
+	}
+	if len(result.Errors) > 0 {
+		report.Errors = &xmlScanErrors{}
+		for _, e := range result.Errors {
+			report.Errors.Errors = append(report.Errors.Errors, xmlScanError(e))
+		}
+	}
+	if len(result.ByExtension) > 0 {
+		report.ByExtension = &xmlExtensionStats{}
+		for _, stat := range result.ByExtension {
+			report.ByExtension.Extensions = append(report.ByExtension.Extensions, xmlExtensionStat(stat))
+		}
+	}
+	return report
+}
+
+func xmlIssues(issues []models.Issue) []xmlIssue {
+	if len(issues) == 0 {
+		return nil
+	}
+	out := make([]xmlIssue, len(issues))
+	for i, issue := range issues {
+		out[i] = xmlIssue(issue)
+	}
+	return out
+}
+
+func xmlRankedItems(items []models.RankedItem) []xmlRankedItem {
+	out := make([]xmlRankedItem, len(items))
+	for i, item := range items {
+		out[i] = xmlRankedItem(item)
+	}
+	return out
+}
+
+func sortXMLCounts(counts []xmlCount) {
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Key < counts[j].Key })
+}
@@ -0,0 +1,109 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+func TestXMLReportRoundTrip(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	result := &models.ScanResult{
+		SchemaVersion:   "2.11",
+		ScanPath:        `\\fileserver\R&D <archive>`,
+		DestinationURL:  "https://contoso.sharepoint.com/sites/RD/Shared Documents",
+		StartTime:       start,
+		EndTime:         start.Add(90250 * time.Millisecond),
+		DurationSeconds: 90.25,
+		DurationISO:     "PT1M30.25S",
+		TotalItems:      12,
+		TotalFiles:      10,
+		TotalFolders:    2,
+		TotalSize:       4096,
+		IssuesFound:     3,
+		ReadinessScore:  75,
+		Issues: []models.Issue{
+			{Path: `\\fileserver\R&D <archive>\a|b.txt`, Type: models.IssueInvalidCharacters, Code: "SPO-CHAR-001", Severity: models.SeverityCritical, Message: `Name contains "|"`, ActionRequired: true},
+			{Path: `\\fileserver\R&D <archive>\bell` + "\a" + `.txt`, Type: models.IssueInvalidCharacters, Severity: models.SeverityWarning, Message: "Name contains a control character"},
+			{Path: `\\fileserver\R&D <archive>\setup.exe`, Type: models.IssueBlockedFileType, Severity: models.SeverityCritical, Message: "Blocked file type", Size: 2048},
+		},
+		Summary: models.IssueSummary{
+			ByType:         map[models.IssueType]int{models.IssueInvalidCharacters: 2, models.IssueBlockedFileType: 1},
+			BySeverity:     map[models.Severity]int{models.SeverityCritical: 2, models.SeverityWarning: 1},
+			ActionRequired: 1,
+			AutoSkipped:    2,
+		},
+		Errors: []models.ScanError{{Path: `\\fileserver\R&D <archive>\locked`, Message: "access denied"}},
+	}
+
+	var buf bytes.Buffer
+	if err := (xmlWriter{}).Write(result, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Error("report does not start with the XML declaration")
+	}
+
+	// Well-formed: every token decodes, down to the end of the document
+	decoder := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("report is not well-formed: %v\n%s", err, buf.String())
+		}
+	}
+
+	var got xmlReport
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.SchemaVersion != result.SchemaVersion || got.ScanPath != result.ScanPath || got.DestinationURL != result.DestinationURL {
+		t.Errorf("header = %q, %q, %q; want %q, %q, %q", got.SchemaVersion, got.ScanPath, got.DestinationURL,
+			result.SchemaVersion, result.ScanPath, result.DestinationURL)
+	}
+	if !got.StartTime.Equal(result.StartTime) || !got.EndTime.Equal(result.EndTime) || got.DurationSeconds != 90.25 || got.DurationISO != "PT1M30.25S" {
+		t.Errorf("times = %v to %v, %vs, %s", got.StartTime, got.EndTime, got.DurationSeconds, got.DurationISO)
+	}
+	if got.TotalItems != 12 || got.TotalFiles != 10 || got.TotalFolders != 2 || got.TotalSize != 4096 || got.IssuesFound != 3 || got.ReadinessScore != 75 {
+		t.Errorf("totals = %+v", got)
+	}
+
+	wantSummary := xmlSummary{
+		ByType:         []xmlCount{{string(models.IssueBlockedFileType), 1}, {string(models.IssueInvalidCharacters), 2}},
+		BySeverity:     []xmlCount{{string(models.SeverityCritical), 2}, {string(models.SeverityWarning), 1}},
+		ActionRequired: 1,
+		AutoSkipped:    2,
+	}
+	sortXMLCounts(wantSummary.ByType)
+	sortXMLCounts(wantSummary.BySeverity)
+	if !reflect.DeepEqual(got.Summary, wantSummary) {
+		t.Errorf("summary = %+v, want %+v", got.Summary, wantSummary)
+	}
+
+	if len(got.Issues) != len(result.Issues) {
+		t.Fatalf("got %d issues, want %d", len(got.Issues), len(result.Issues))
+	}
+	for i, issue := range got.Issues {
+		want := xmlIssue(result.Issues[i])
+		if i == 1 {
+			// XML 1.0 cannot hold control characters; they are replaced
+			want.Path = strings.Replace(want.Path, "\a", "\uFFFD", 1)
+		}
+		if issue != want {
+			t.Errorf("issue %d = %+v, want %+v", i, issue, want)
+		}
+	}
+	if got.Errors == nil || len(got.Errors.Errors) != 1 || got.Errors.Errors[0] != xmlScanError(result.Errors[0]) {
+		t.Errorf("errors = %+v, want %+v", got.Errors, result.Errors)
+	}
+	if got.TopOffenders != nil || got.ByExtension != nil || got.PotentialSecrets != nil {
+		t.Error("empty optional sections were written")
+	}
+}
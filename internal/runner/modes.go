@@ -0,0 +1,217 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/validator"
+	"github.com/ajoshuasmith/sharepoint-prescan/scan"
+)
+
+// benchFolderSize is the number of generated files per folder in -bench;
+// folders are grouped ten to a parent
+const benchFolderSize = 100
+
+// benchNames are the generated file names, cycled through, so the
+// benchmark exercises the blocked, problematic and owner-file checks as
+// well as clean files
+var benchNames = []string{
+	"Report %d.docx", "Budget %d.xlsx", "Scan %d.pdf", "Notes %d.txt", "Photo %d.jpg",
+	"Drawing %d.dwg", "Setup %d.exe", "Backup %d.zip", "~$Draft %d.docx",
+	"A rather long document name that is typical of real shares, revision %d.docx",
+}
+
+// Bench generates a tree of files in a temporary folder, scans it with
+// cfg as a normal scan would, minus the reports, and prints the
+// throughput and allocations. A profile of the given kind, if any, is
+// written to outputDir.
+func Bench(cfg *config.Config, files, workers int, profile, outputDir string) error {
+	root, err := os.MkdirTemp("", "spready-bench-")
+	if err != nil {
+		return fail("Failed to create benchmark folder", err)
+	}
+	defer os.RemoveAll(root)
+
+	fmt.Printf("Generating %d files in %s...\n", files, root)
+	start := time.Now()
+	for i := 0; i < files; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("Group %03d", i/(benchFolderSize*10)), fmt.Sprintf("Folder %03d", i/benchFolderSize))
+		if i%benchFolderSize == 0 {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fail("Failed to generate benchmark tree", err)
+			}
+		}
+		name := fmt.Sprintf(benchNames[i%len(benchNames)], i)
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			return fail("Failed to generate benchmark tree", err)
+		}
+	}
+	fmt.Printf("Generated in %s\n", time.Since(start).Round(time.Millisecond))
+
+	var stopProfile func() (string, error)
+	if profile != "" {
+		stopProfile, err = startProfile(profile, outputDir)
+		if err != nil {
+			return fail("Failed to start profile", err)
+		}
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	start = time.Now()
+	result, err := scan.Run(context.Background(), scan.Options{
+		Path:    root,
+		Config:  cfg,
+		Workers: workers,
+	})
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	if stopProfile != nil {
+		path, profileErr := stopProfile()
+		if profileErr != nil {
+			return fail("Failed to write profile", profileErr)
+		}
+		fmt.Printf("Profile saved: %s\n", path)
+	}
+	if err != nil {
+		return fail("Scan failed", err)
+	}
+
+	allocs := after.Mallocs - before.Mallocs
+	fmt.Printf("Scanned %d items (%d folders) in %s: %.0f items/sec\n",
+		result.TotalItems, result.TotalFolders, elapsed.Round(time.Millisecond), float64(result.TotalItems)/elapsed.Seconds())
+	fmt.Printf("Issues:      %d\n", result.IssuesFound)
+	fmt.Printf("Allocations: %d (%.0f per item), %.1f MB\n",
+		allocs, float64(allocs)/float64(max(result.TotalItems, 1)), float64(after.TotalAlloc-before.TotalAlloc)/(1<<20))
+	return nil
+}
+
+// Explain prints the rules behind an issue type or extension under cfg,
+// and reports whether any rule matched key
+func Explain(cfg *config.Config, key string) bool {
+	v := validator.NewValidator(cfg, "", cfg.Settings.DefaultChecks)
+	explanation, ok := v.Explain(key)
+	if !ok {
+		fmt.Printf("No rule found for %q. Use an issue type such as ProblematicFile or an extension such as .pst.\n", key)
+		return false
+	}
+
+	fmt.Println(explanation.Topic)
+	if explanation.Description != "" {
+		fmt.Printf("  %s\n", explanation.Description)
+	}
+
+	for _, rule := range explanation.Rules {
+		fmt.Println()
+		if rule.Issue.Category != "" {
+			fmt.Printf("  Category:   %s\n", rule.Issue.Category)
+		}
+		fmt.Printf("  Type:       %s\n", rule.Issue.Type)
+		fmt.Printf("  Severity:   %s\n", rule.Issue.Severity)
+		if rule.Condition != "" {
+			fmt.Printf("  Applies to: %s\n", rule.Condition)
+		}
+		if len(rule.Extensions) > 0 {
+			fmt.Printf("  Extensions: %s\n", strings.Join(rule.Extensions, ", "))
+		}
+		fmt.Printf("  Message:    %s\n", rule.Issue.Message)
+		if rule.Issue.RemediationHint != "" {
+			fmt.Printf("  Fix:        %s\n", rule.Issue.RemediationHint)
+		}
+	}
+
+	return true
+}
+
+// ConfigDiff lists where a config file differs from the built-in
+// defaults, so a pinned config can be brought up to date
+func ConfigDiff(configFile string) error {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		return fail("Failed to load config file", err)
+	}
+
+	diffs, err := config.Diff(cfg, config.NewDefaultConfig())
+	if err != nil {
+		return fail("Failed to compare config", err)
+	}
+	if len(diffs) == 0 {
+		fmt.Printf("%s matches the built-in defaults.\n", configFile)
+		return nil
+	}
+
+	fmt.Printf("%s differs from the built-in defaults:\n", configFile)
+	fmt.Println("  + only in the defaults   - only in the config file   ~ changed")
+	fmt.Println()
+
+	// List entries added to or removed from the same list on one line
+	for i := 0; i < len(diffs); {
+		d := diffs[i]
+		if d.Kind == config.DiffChanged {
+			fmt.Printf("  ~ %s: %s (default %s)\n", d.Path, d.Value, d.Base)
+			i++
+			continue
+		}
+
+		var values []string
+		for ; i < len(diffs) && diffs[i].Path == d.Path && diffs[i].Kind == d.Kind; i++ {
+			values = append(values, diffs[i].Value)
+		}
+		sign := "+"
+		if d.Kind == config.DiffRemoved {
+			sign = "-"
+		}
+		fmt.Printf("  %s %s: %s\n", sign, d.Path, strings.Join(values, ", "))
+	}
+	return nil
+}
+
+// Validate checks a single proposed name, or a path below the library
+// root when path is set, against the rules a scan would apply, prints the
+// issues and returns them
+func Validate(cfg *config.Config, destination, name, path string, isDir bool) []models.Issue {
+	kind := "file"
+	if isDir {
+		kind = "folder"
+	}
+
+	v := validator.NewValidator(cfg, destination, cfg.Settings.DefaultChecks)
+	var issues []models.Issue
+	if path != "" {
+		fmt.Printf("Checking %s path: %s\n", kind, path)
+		issues = v.ValidatePath(path, isDir)
+	} else {
+		fmt.Printf("Checking %s name: %s\n", kind, name)
+		issues = v.ValidateName(name, isDir)
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("\nNo issues found.")
+		return nil
+	}
+
+	for _, issue := range issues {
+		fmt.Println()
+		fmt.Printf("  [%s] %s  %s\n", issue.Severity, issue.Code, issue.Message)
+		if path != "" {
+			fmt.Printf("  Path:    %s\n", issue.Path)
+		}
+		if issue.Details != "" {
+			fmt.Printf("  Details: %s\n", issue.Details)
+		}
+		if issue.RemediationHint != "" {
+			fmt.Printf("  Fix:     %s\n", issue.RemediationHint)
+		}
+	}
+	return issues
+}
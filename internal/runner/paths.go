@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/validator"
+)
+
+// ReadPathList reads newline-delimited paths from a file, or from stdin
+// when source is "-". Blank lines and duplicates are skipped and relative
+// paths are made absolute.
+func ReadPathList(source string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if source != "-" {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	seen := make(map[string]bool)
+	paths := []string{}
+
+	lines := bufio.NewScanner(r)
+	lines.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" {
+			continue
+		}
+
+		path, err := filepath.Abs(line)
+		if err != nil {
+			path = line
+		}
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+
+	return paths, lines.Err()
+}
+
+// verifyPathList returns the paths with issues in an earlier report, to be
+// re-checked, and where each one is now. Paths under the earlier scan
+// root are moved under root, and a path that no longer exists is looked
+// for under the names SuggestName gives it and its folders, the way a
+// rename would have fixed it. Paths that are gone map to "". Paths whose
+// issues all need a full scan to confirm are left out.
+func verifyPathList(previous *models.ScanResult, root string, v *validator.Validator) ([]string, map[string]string) {
+	current := make(map[string]string)
+	seen := make(map[string]bool)
+	paths := []string{}
+
+	for _, issue := range previous.Issues {
+		if _, done := current[issue.Path]; done || needsFullScan(issue) {
+			continue
+		}
+
+		rel, err := filepath.Rel(previous.ScanPath, issue.Path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			rel, err = filepath.Rel(root, issue.Path)
+			if err != nil {
+				current[issue.Path] = ""
+				continue
+			}
+		}
+
+		path := filepath.Join(root, rel)
+		if _, err := os.Lstat(path); err != nil {
+			path = renamedPath(root, rel, issue.IsDirectory, v)
+		}
+		current[issue.Path] = path
+		if path != "" && !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, current
+}
+
+// renamedPath looks for rel below root with each missing name replaced by
+// its suggested name, and returns "" when it is not there either
+func renamedPath(root, rel string, isDir bool, v *validator.Validator) string {
+	names := strings.Split(rel, string(filepath.Separator))
+	path := root
+	for i, name := range names {
+		next := filepath.Join(path, name)
+		if _, err := os.Lstat(next); err != nil {
+			suggested := v.SuggestName(name, isDir || i < len(names)-1)
+			if suggested == "" || suggested == name {
+				return ""
+			}
+			next = filepath.Join(path, suggested)
+			if _, err := os.Lstat(next); err != nil {
+				return ""
+			}
+		}
+		path = next
+	}
+	return path
+}
+
+// needsFullScan reports whether an earlier issue can only be confirmed by
+// scanning the whole tree: conditions decided by other items, and the
+// summaries -collapse-problematic writes in place of individual files
+func needsFullScan(issue models.Issue) bool {
+	return validator.NeedsTree(issue.MessageID) || issue.MessageID == "" && issue.Count > 0
+}
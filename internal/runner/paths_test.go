@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/validator"
+)
+
+func TestReadPathList(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	list := filepath.Join(dir, "paths.txt")
+	if err := os.WriteFile(list, []byte(a+"\n\n  "+b+"  \n"+a+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := ReadPathList(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{a, b}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("ReadPathList = %q, want %q", paths, want)
+	}
+
+	if _, err := ReadPathList(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("ReadPathList of a missing file returned no error")
+	}
+}
+
+func TestVerifyPathList(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"kept.txt", "Budget_2024.xlsx"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The earlier scan was of the same tree at another path
+	oldRoot := filepath.Join(string(filepath.Separator), "share", "old")
+	previous := &models.ScanResult{
+		ScanPath: oldRoot,
+		Issues: []models.Issue{
+			{Path: filepath.Join(oldRoot, "kept.txt"), MessageID: "any"},
+			{Path: filepath.Join(oldRoot, "Budget|2024.xlsx"), MessageID: "any"},
+			{Path: filepath.Join(oldRoot, "gone.txt"), MessageID: "any"},
+			// A -collapse-problematic summary needs a full scan to confirm
+			{Path: filepath.Join(oldRoot, "media"), Count: 120},
+		},
+	}
+
+	v := validator.NewValidator(config.NewDefaultConfig(), "", nil)
+	paths, current := verifyPathList(previous, root, v)
+
+	kept := filepath.Join(root, "kept.txt")
+	renamed := filepath.Join(root, "Budget_2024.xlsx")
+	if want := []string{kept, renamed}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %q, want %q", paths, want)
+	}
+	wantCurrent := map[string]string{
+		filepath.Join(oldRoot, "kept.txt"):         kept,
+		filepath.Join(oldRoot, "Budget|2024.xlsx"): renamed,
+		filepath.Join(oldRoot, "gone.txt"):         "",
+	}
+	if !reflect.DeepEqual(current, wantCurrent) {
+		t.Errorf("current = %q, want %q", current, wantCurrent)
+	}
+}
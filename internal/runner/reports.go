@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/reporter"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/ui"
+	"github.com/ajoshuasmith/sharepoint-prescan/scan"
+)
+
+// newReporter creates a reporter whose default filenames follow the
+// -filename-template flag and the configured company and project names,
+// and whose HTML report carries the configured title
+func newReporter(outputDir, filenameTemplate string, cfg *config.Config, scanRoot string) *reporter.Reporter {
+	rep := reporter.NewReporter(outputDir)
+	rep.SetFilenameTemplate(
+		filenameTemplate,
+		cfg.Settings.ReportSettings.CompanyName,
+		cfg.Settings.ReportSettings.ProjectName,
+		scanRoot,
+	)
+	rep.SetTitle(cfg.Settings.ReportSettings.ReportTitle)
+	rep.SetRemediation(cfg.Settings.ReportSettings.IncludeRemediation)
+	rep.SetTimestamp(cfg.Settings.ReportSettings.IncludeTimestamp)
+	return rep
+}
+
+// writeReports writes the combined report in each format, and reports
+// whether all of them were written. A CSV report streamed during the scan
+// is only closed, and the HTML report replaces the live one in
+// liveFilename, if set. Without combined, only the CSV stream is closed.
+func writeReports(rep *reporter.Reporter, result *models.ScanResult, formats []string, csvStream *reporter.CSVStream, liveFilename string, combined bool) bool {
+	ok := true
+	for _, format := range formats {
+		switch {
+		case format == reporter.FormatCSV && csvStream != nil:
+			// Rows were written during the scan; close the file
+			if err := csvStream.Close(); err != nil {
+				ui.ShowError("Failed to generate CSV report", err)
+				ok = false
+			}
+			continue
+		case !combined:
+			continue
+		}
+
+		filename := ""
+		if format == reporter.FormatHTML {
+			filename = liveFilename
+		}
+		if err := rep.Generate(format, result, filename); err != nil {
+			ui.ShowError(fmt.Sprintf("Failed to generate %s report", strings.ToUpper(format)), err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// severityResult returns a copy of result limited to the issues and
+// potential secrets of one severity. Totals and the readiness score still
+// describe the whole scan.
+func severityResult(result *models.ScanResult, severity models.Severity) *models.ScanResult {
+	split := *result
+	split.Issues = []models.Issue{}
+	for _, issue := range result.Issues {
+		if issue.Severity == severity {
+			split.Issues = append(split.Issues, issue)
+		}
+	}
+	split.PotentialSecrets = nil
+	for _, issue := range result.PotentialSecrets {
+		if issue.Severity == severity {
+			split.PotentialSecrets = append(split.PotentialSecrets, issue)
+		}
+	}
+	split.IssuesFound = len(split.Issues)
+	split.Summary = scan.Summarize(split.Issues)
+	return &split
+}
+
+// writeSeverityReports writes the reports of a result limited to one
+// severity in each requested format, and reports whether all of them were
+// written
+func writeSeverityReports(rep *reporter.Reporter, result *models.ScanResult, severity models.Severity, formats []string) bool {
+	ok := true
+	for _, format := range formats {
+		writer, err := rep.Writer(format)
+		if err == nil {
+			err = rep.Generate(format, result, rep.SeverityFilename(severity, writer.Extension()))
+		}
+		if err != nil {
+			ui.ShowError(fmt.Sprintf("Failed to generate %s %s report", severity, strings.ToUpper(format)), err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// savePartialResult writes the result collected so far to a JSON report
+// after a panic. A second panic while writing is reported, not raised.
+func savePartialResult(rep *reporter.Reporter, outputDir string, result *models.ScanResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			ui.ShowError("Failed to save partial results", fmt.Errorf("%v", r))
+		}
+	}()
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		ui.ShowError("Failed to save partial results", err)
+		return
+	}
+	if err := rep.GenerateJSON(result, ""); err != nil {
+		ui.ShowError("Failed to save partial results", err)
+	}
+}
+
+// startProfile starts a -profile profile in dir. The returned function
+// ends it and returns the file written: it stops a CPU profile, or writes
+// a memory profile of every allocation made since the program started.
+func startProfile(kind, dir string) (func() (string, error), error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "spready-"+kind+".pprof")
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == "cpu" {
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return nil, err
+		}
+		return func() (string, error) {
+			pprof.StopCPUProfile()
+			return path, file.Close()
+		}, nil
+	}
+
+	return func() (string, error) {
+		runtime.GC() // Bring the in-use figures up to date
+		if err := pprof.Lookup("allocs").WriteTo(file, 0); err != nil {
+			file.Close()
+			return path, err
+		}
+		return path, file.Close()
+	}, nil
+}
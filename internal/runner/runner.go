@@ -0,0 +1,752 @@
+// Package runner carries out a spready scan once the command line has been
+// parsed: the progress display, the outputs streamed during the scan, the
+// reports written after it, and delivery of the result. It reports how the
+// run went and leaves the exit code to the caller.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/reporter"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/ui"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/validator"
+	"github.com/ajoshuasmith/sharepoint-prescan/scan"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+)
+
+// DefaultIndexFile is the index -incremental uses, in the output directory,
+// when no index file is named
+const DefaultIndexFile = "spready-index.json"
+
+// Options configures a run. Path must be absolute; zero values of the scan
+// settings mean the same as they do in scan.Options.
+type Options struct {
+	Path        string
+	Destination string
+	Config      *config.Config
+
+	// Scan settings, passed on to scan.Run
+	Paths          []string
+	ExcludeFolders []string
+	MaxItems       int64
+	Workers        int
+	ReadRetries    int
+	RetryBackoff   time.Duration
+	SniffMaxSize   int64
+	Filter         scan.FileFilter
+	CountFiltered  bool
+	MaxMemory      int64
+	IgnoreFile     string
+	Logger         *slog.Logger
+
+	// Incremental validates only the files changed since the index in
+	// IndexFile was saved, or in DefaultIndexFile under Output
+	Incremental bool
+	IndexFile   string
+
+	// Verify is an earlier report whose issues are re-checked; Paths is
+	// replaced by the paths with issues in it. VerifyReport names it in
+	// the output.
+	Verify       *models.ScanResult
+	VerifyReport string
+
+	// FailFast stops the scan at the first Critical issue
+	FailFast bool
+
+	// Profile is the kind of pprof profile to write of the scan, if any:
+	// cpu or mem
+	Profile string
+
+	// Outputs
+	Output              string
+	FilenameTemplate    string
+	Formats             []string
+	Sort                string
+	SplitBySeverity     bool
+	SplitOnly           bool
+	SplitEmpty          bool
+	StreamCSV           bool
+	LiveHTML            bool
+	Manifest            bool
+	StatusFile          string
+	MetricsFile         string
+	CollapseProblematic bool
+	CollapseThreshold   int
+	CollapseList        bool
+	MaxIssuesPerFolder  int
+	OnlyTypes           map[models.IssueType]bool
+	FolderStats         bool
+	FolderStatsDepth    int
+	RenameMap           bool
+	Bundle              bool
+	Zip                 bool
+	SummaryFormat       string
+	GitHubAnnotations   bool
+	PostURL             string
+	PostHeaders         map[string]string
+	PostTimeout         time.Duration
+
+	// Progress display
+	UseTUI     bool
+	NoProgress bool
+
+	// Recorded in the bundle's run.json
+	Version string
+	Commit  string
+}
+
+// Outcome describes how a run ended
+type Outcome struct {
+	// Summary counts every issue found, including those OnlyTypes left
+	// out of the reports
+	Summary models.IssueSummary
+
+	Interrupted    bool // Canceled by a signal or by closing the TUI
+	FailedFast     bool // Stopped at the first Critical issue
+	ScanPanicked   bool // The scan panicked; a JSON report was still written
+	ReportPanicked bool // Writing the reports panicked; partial JSON was saved
+	ScanFailed     bool // The scan ended with an error
+	ReportFailed   bool // An output could not be written
+	PostFailed     bool // The result could not be posted to PostURL
+}
+
+// Error is a failure that ends a run before its reports are written.
+// Title says what failed, in the words shown to the user.
+type Error struct {
+	Title string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Title
+	}
+	return e.Title + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func fail(title string, err error) error {
+	return &Error{Title: title, Err: err}
+}
+
+// Run scans opts.Path and writes the requested outputs. Problems with
+// individual outputs are shown as they happen and recorded in the
+// Outcome; the returned error is an *Error for failures that stop the run.
+func Run(opts Options) (outcome Outcome, err error) {
+	cfg := opts.Config
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	}
+	formats := append([]string(nil), opts.Formats...)
+	withJSON := hasFormat(formats, reporter.FormatJSON)
+	withCSV := hasFormat(formats, reporter.FormatCSV)
+
+	// Re-check the paths with issues in the earlier report, following
+	// renames made since
+	paths := opts.Paths
+	var verifyPaths map[string]string
+	if opts.Verify != nil {
+		paths, verifyPaths = verifyPathList(opts.Verify, opts.Path, validator.NewValidator(cfg, opts.Destination, nil))
+	}
+
+	// Load accepted findings to suppress
+	var ignore *scan.IgnoreList
+	if opts.IgnoreFile != "" {
+		ignore, err = scan.LoadIgnoreFile(opts.IgnoreFile)
+		if err != nil {
+			return outcome, fail("Failed to load ignore file", err)
+		}
+	}
+
+	// Setup context with cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Track why the scan stopped so the exit code can reflect it
+	var (
+		interrupted  atomic.Bool
+		scanFinished atomic.Bool
+	)
+
+	// Handle interrupt signal
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	defer func() {
+		signal.Stop(sigChan)
+		close(done)
+	}()
+	go func() {
+		select {
+		case <-sigChan:
+			interrupted.Store(true)
+			fmt.Println("\n\n⚠️  Scan interrupted by user. Generating partial results...")
+			cancel()
+		case <-done:
+		}
+	}()
+
+	var (
+		program     *tea.Program
+		programDone chan struct{}
+	)
+
+	if opts.UseTUI {
+		program = tea.NewProgram(ui.NewScanModel(opts.Path, opts.Destination), tea.WithAltScreen())
+		programDone = make(chan struct{})
+		go func() {
+			_, _ = program.Run()
+			close(programDone)
+		}()
+		go func() {
+			<-programDone
+			if !scanFinished.Load() {
+				interrupted.Store(true)
+			}
+			cancel()
+		}()
+	}
+
+	// Write every output of this run into its own folder
+	output := opts.Output
+	var bundleDir string
+	if opts.Bundle {
+		bundleDir, err = reporter.CreateBundleDir(opts.Output, opts.Path, time.Now())
+		if err != nil {
+			return outcome, fail("Failed to create bundle directory", err)
+		}
+		output = bundleDir
+	}
+	newRep := func() *reporter.Reporter {
+		return newReporter(output, opts.FilenameTemplate, cfg, opts.Path)
+	}
+
+	// Stream a manifest of every item to disk as the scan runs
+	var (
+		manifestChan chan *models.FileSystemItem
+		manifestDone chan error
+	)
+	if opts.Manifest || cfg.Settings.ReportSettings.IncludeAllItems {
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return outcome, fail("Failed to create output directory", err)
+		}
+
+		manifestChan = make(chan *models.FileSystemItem, 1000)
+		manifestDone = make(chan error, 1)
+		rep := newRep()
+		go func() {
+			manifestDone <- rep.GenerateManifestCSV(manifestChan, "")
+		}()
+	}
+
+	// Stream CSV rows to disk as issues are found
+	var csvStream *reporter.CSVStream
+	if withCSV && opts.StreamCSV {
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return outcome, fail("Failed to create output directory", err)
+		}
+
+		csvStream, err = newRep().StreamCSV("")
+		if err != nil {
+			return outcome, fail("Failed to generate CSV report", err)
+		}
+	}
+
+	offenders := reporter.NewOffenderTracker(cfg.Settings.ReportSettings.TopOffenders)
+	var folderRollup *reporter.FolderStats
+	if opts.FolderStats {
+		folderRollup = reporter.NewFolderStats(opts.FolderStatsDepth)
+	}
+
+	// Cursor-based progress only works on a terminal; fall back to log lines
+	stdoutIsTerminal := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+
+	interval := time.Duration(cfg.Settings.ProgressUpdateInterval) * time.Millisecond
+	var display scan.ProgressObserver
+	if program != nil {
+		display = &ui.TUIObserver{Program: program, Interval: interval}
+	} else if !opts.NoProgress {
+		display = &ui.ConsoleObserver{Plain: !stdoutIsTerminal, StartTime: time.Now(), Interval: interval}
+	}
+
+	var status *reporter.StatusFile
+	if opts.StatusFile != "" {
+		status, err = reporter.NewStatusFile(opts.StatusFile, time.Now())
+		if err != nil {
+			return outcome, fail("Failed to create status file", err)
+		}
+		status.SetInterval(interval)
+	}
+	var statusObserver scan.ProgressObserver
+	if status != nil {
+		statusObserver = status
+	}
+
+	// Keep the HTML report current while the scan runs; the final report
+	// is written to the same file
+	var live *reporter.LiveHTML
+	var liveObserver scan.ProgressObserver
+	if opts.LiveHTML {
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return outcome, fail("Failed to create output directory", err)
+		}
+
+		rep := newRep()
+		rep.SetSort(opts.Sort)
+		live, err = rep.NewLiveHTML(opts.Path, opts.Destination, time.Now(), func(issues []models.Issue, totalItems int64) int {
+			return scan.ReadinessScore(issues, totalItems, cfg.Settings.ReportSettings.ReadinessWeights)
+		})
+		if err != nil {
+			return outcome, fail("Failed to generate HTML report", err)
+		}
+		liveObserver = live
+		fmt.Printf("Live HTML report: %s\n", live.Path())
+	}
+	observer := scan.MultiObserver(display, statusObserver, liveObserver)
+
+	// Load the previous run's index for an incremental scan
+	var index *scan.Index
+	indexPath := opts.IndexFile
+	if opts.Incremental {
+		index, indexPath = loadIndex(indexPath, opts.Output)
+	}
+
+	// Profile only the scan, not the report generation
+	var stopProfile func() (string, error)
+	if opts.Profile != "" {
+		stopProfile, err = startProfile(opts.Profile, output)
+		if err != nil {
+			return outcome, fail("Failed to start profile", err)
+		}
+	}
+
+	// Under -max-memory, issues the CSV stream has written can be dropped
+	// from memory once they are flushed to disk
+	var spill func([]models.Issue) error
+	if csvStream != nil {
+		spill = func([]models.Issue) error {
+			return csvStream.Flush()
+		}
+	}
+
+	// Run the scan
+	result, err := scan.Run(ctx, scan.Options{
+		Path:           opts.Path,
+		Paths:          paths,
+		Destination:    opts.Destination,
+		Config:         cfg,
+		ExcludeFolders: opts.ExcludeFolders,
+		MaxItems:       opts.MaxItems,
+		Workers:        opts.Workers,
+		ReadRetries:    opts.ReadRetries,
+		RetryBackoff:   opts.RetryBackoff,
+		SniffMaxSize:   opts.SniffMaxSize,
+		Filter:         opts.Filter,
+		CountFiltered:  opts.CountFiltered,
+		MaxMemory:      opts.MaxMemory,
+		Spill:          spill,
+		Index:          index,
+		Ignore:         ignore,
+		Logger:         logger,
+		ItemSizes:      manifestChan != nil || folderRollup != nil,
+		OnItem: func(item *models.FileSystemItem) {
+			if item.Filtered {
+				return
+			}
+			if manifestChan != nil {
+				manifestChan <- item
+			}
+			offenders.Add(item)
+			if folderRollup != nil {
+				folderRollup.Add(item)
+			}
+		},
+		OnIssues: func(issues []models.Issue) {
+			if csvStream != nil {
+				csvStream.Write(filterIssueTypes(issues, opts.OnlyTypes))
+			}
+			if opts.FailFast && !outcome.FailedFast {
+				for _, issue := range issues {
+					if issue.Severity == models.SeverityCritical {
+						outcome.FailedFast = true
+						cancel()
+						break
+					}
+				}
+			}
+		},
+		Observer: observer,
+	})
+
+	scanFinished.Store(true)
+
+	if stopProfile != nil {
+		if path, profileErr := stopProfile(); profileErr != nil {
+			ui.ShowError("Failed to write profile", profileErr)
+			outcome.ReportFailed = true
+		} else {
+			fmt.Printf("Profile saved: %s\n", path)
+		}
+	}
+
+	if result == nil {
+		return outcome, fail("Scan failed", err)
+	}
+
+	// A panic from here on still saves what the scan collected
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("panic while generating reports", "panic", r, "stack", string(debug.Stack()))
+			savePartialResult(newRep(), output, result)
+			outcome = Outcome{ReportPanicked: true}
+			err = nil
+		}
+	}()
+
+	// A panic during the scan ends it early; always keep a JSON report
+	var panicErr *scan.PanicError
+	if errors.As(err, &panicErr) {
+		outcome.ScanPanicked = true
+		if !withJSON {
+			withJSON = true
+			formats = append(formats, reporter.FormatJSON)
+		}
+	}
+	if err != nil && err != context.Canceled {
+		if program != nil {
+			program.Send(ui.ErrorMsg(err))
+		} else {
+			ui.ShowError("Scan error", err)
+		}
+		outcome.ScanFailed = true
+	}
+
+	if status != nil && status.Err() != nil {
+		ui.ShowError("Failed to update status file", status.Err())
+		outcome.ReportFailed = true
+	}
+	if live != nil && live.Err() != nil {
+		ui.ShowError("Failed to update live HTML report", live.Err())
+		outcome.ReportFailed = true
+	}
+
+	// Save the index for the next incremental run; a partial scan would
+	// drop the files it did not reach
+	if index != nil && err == nil {
+		if saveErr := saveIndex(index, indexPath); saveErr != nil {
+			ui.ShowError("Failed to save index", saveErr)
+			outcome.ReportFailed = true
+		}
+	}
+
+	// Compare with the earlier report before issues are folded or filtered
+	var verified []reporter.VerifiedIssue
+	if opts.Verify != nil {
+		verified = reporter.VerifyIssues(opts.Verify.Issues, result.Issues, verifyPaths, needsFullScan)
+	}
+
+	// Fold noisy problematic-file categories into summary issues
+	if opts.CollapseProblematic {
+		threshold := cfg.Settings.ReportSettings.CollapseProblematicThreshold
+		if opts.CollapseThreshold > 0 {
+			threshold = opts.CollapseThreshold
+		}
+
+		rep := newRep()
+		listFile := ""
+		if opts.CollapseList {
+			listFile = rep.CollapsedListFilename()
+		}
+
+		var folded []models.Issue
+		result.Issues, folded = reporter.CollapseProblematic(result.Issues, threshold, opts.Path, listFile)
+		result.IssuesFound = len(result.Issues)
+		result.Summary = scan.Summarize(result.Issues)
+		if len(folded) > 0 && opts.CollapseList {
+			if err := os.MkdirAll(output, 0755); err != nil {
+				return outcome, fail("Failed to create output directory", err)
+			}
+			if err := rep.GenerateCollapsedListCSV(folded, listFile); err != nil {
+				ui.ShowError("Failed to write problematic file list", err)
+				outcome.ReportFailed = true
+			}
+		}
+	}
+
+	// Limit the reports to the requested issue types; the exit code
+	// still counts every issue
+	outcome.Summary = result.Summary
+	if opts.OnlyTypes != nil {
+		result.Issues = filterIssueTypes(result.Issues, opts.OnlyTypes)
+		result.IssuesFound = len(result.Issues)
+		result.Summary = scan.Summarize(result.Issues)
+		for _, issueType := range models.IssueTypes {
+			if opts.OnlyTypes[issueType] {
+				result.OnlyTypes = append(result.OnlyTypes, issueType)
+			}
+		}
+	}
+
+	// Close the progress display
+	if program != nil {
+		program.Send(ui.DoneMsg{})
+		<-programDone
+	}
+
+	if manifestChan != nil {
+		close(manifestChan)
+		if err := <-manifestDone; err != nil {
+			ui.ShowError("Failed to generate manifest", err)
+			outcome.ReportFailed = true
+		}
+	}
+
+	if cfg.Settings.ReportSettings.TopOffenders > 0 {
+		result.TopOffenders = offenders.Result()
+		if result.SizesSkipped {
+			// Only the files with issues were sized, so a ranking of
+			// them would not show the largest files
+			result.TopOffenders.LargestFiles = []models.RankedItem{}
+		}
+	}
+	result.ByExtension = reporter.ExtensionBreakdown(result.Issues, cfg.Settings.ReportSettings.ExtensionBreakdownRows)
+	result.PotentialSecrets = reporter.PotentialSecrets(result.Issues)
+
+	// Keep one pathological folder from burying the rest of the reports.
+	// The summary still counts every issue, and the rename map still
+	// lists every item.
+	allIssues := result.Issues
+	if opts.MaxIssuesPerFolder > 0 {
+		result.Issues = reporter.CapIssuesPerFolder(result.Issues, opts.MaxIssuesPerFolder)
+	}
+
+	// Show summary
+	ui.ShowStyledSummary(result)
+	if opts.Verify != nil {
+		counts := reporter.CountVerified(verified)
+		fmt.Printf("\nVerified against %s: %d resolved, %d still present, %d new, %d not verified\n",
+			opts.VerifyReport, counts[reporter.VerifyResolved], counts[reporter.VerifyPresent],
+			counts[reporter.VerifyNew], counts[reporter.VerifyUnverified])
+	}
+
+	// Generate reports
+	reportStart := time.Now()
+	if len(formats) > 0 {
+		fmt.Println("\nGenerating reports...")
+
+		// Ensure output directory exists
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return outcome, fail("Failed to create output directory", err)
+		}
+
+		rep := newRep()
+		rep.SetSort(opts.Sort)
+		liveFilename := ""
+		if live != nil {
+			liveFilename = live.Filename()
+		}
+		if !writeReports(rep, result, formats, csvStream, liveFilename, !opts.SplitOnly) {
+			outcome.ReportFailed = true
+		}
+
+		// Write the same reports once per severity, for handing each
+		// slice to its owner
+		if opts.SplitBySeverity {
+			for _, severity := range models.Severities {
+				if result.Summary.BySeverity[severity] == 0 && !opts.SplitEmpty {
+					continue
+				}
+				if !writeSeverityReports(rep, severityResult(result, severity), severity, formats) {
+					outcome.ReportFailed = true
+				}
+			}
+		}
+
+		fmt.Println()
+	}
+
+	// Write the per-folder rollup
+	if folderRollup != nil {
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return outcome, fail("Failed to create output directory", err)
+		}
+
+		rep := newRep()
+		if err := rep.GenerateFolderStats(folderRollup, ""); err != nil {
+			ui.ShowError("Failed to generate folder stats", err)
+			outcome.ReportFailed = true
+		}
+		if withJSON {
+			if err := rep.GenerateFolderStats(folderRollup, rep.FolderStatsFilename(".json")); err != nil {
+				ui.ShowError("Failed to generate folder stats", err)
+				outcome.ReportFailed = true
+			}
+		}
+	}
+
+	// Write the suggested renames
+	if opts.RenameMap {
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return outcome, fail("Failed to create output directory", err)
+		}
+
+		v := validator.NewValidator(cfg, opts.Destination, cfg.Settings.DefaultChecks)
+		full := *result
+		full.Issues = allIssues
+		if err := newRep().GenerateRenameMap(&full, validator.FixedByRename, v.SuggestName, ""); err != nil {
+			ui.ShowError("Failed to generate rename map", err)
+			outcome.ReportFailed = true
+		}
+	}
+
+	// Write what the verification found
+	if opts.Verify != nil {
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return outcome, fail("Failed to create output directory", err)
+		}
+
+		if err := newRep().GenerateVerifyCSV(verified, ""); err != nil {
+			ui.ShowError("Failed to generate verification report", err)
+			outcome.ReportFailed = true
+		}
+	}
+
+	// Write metrics for trend graphs
+	if opts.MetricsFile != "" {
+		if err := reporter.WriteMetrics(opts.MetricsFile, result); err != nil {
+			ui.ShowError("Failed to write metrics", err)
+			outcome.ReportFailed = true
+		}
+	}
+
+	logger.Debug("reports written", "elapsed", time.Since(reportStart))
+
+	outcome.Interrupted = interrupted.Load()
+
+	// Describe the run and archive the bundle
+	if bundleDir != "" {
+		info := reporter.RunInfo{
+			Tool:           "spready",
+			Version:        opts.Version,
+			Commit:         opts.Commit,
+			SchemaVersion:  result.SchemaVersion,
+			ScanPath:       result.ScanPath,
+			DestinationURL: result.DestinationURL,
+			StartTime:      result.StartTime,
+			EndTime:        result.EndTime,
+			TotalItems:     result.TotalItems,
+			IssuesFound:    result.IssuesFound,
+			Interrupted:    outcome.Interrupted || outcome.FailedFast,
+			Truncated:      result.Truncated,
+		}
+		if err := reporter.WriteRunInfo(bundleDir, info); err != nil {
+			ui.ShowError("Failed to write run metadata", err)
+			outcome.ReportFailed = true
+		} else if opts.Zip {
+			if _, err := reporter.ZipBundle(bundleDir); err != nil {
+				ui.ShowError("Failed to archive bundle", err)
+				outcome.ReportFailed = true
+			}
+		}
+	}
+
+	// Print a compact summary for pasting into chat
+	if opts.SummaryFormat != "" {
+		text, err := reporter.FormatSummary(result, opts.SummaryFormat)
+		if err != nil {
+			ui.ShowError("Failed to format summary", err)
+			outcome.ReportFailed = true
+		} else {
+			fmt.Print(text)
+		}
+	}
+
+	// Surface issues inline in a GitHub Actions run
+	if opts.GitHubAnnotations {
+		if err := reporter.WriteGitHubAnnotations(os.Stdout, result.Issues, os.Getenv("GITHUB_WORKSPACE")); err != nil {
+			ui.ShowError("Failed to write GitHub annotations", err)
+			outcome.ReportFailed = true
+		}
+	}
+
+	// Push the result to a central collector
+	if opts.PostURL != "" && !outcome.Interrupted && !outcome.FailedFast {
+		if err := reporter.PostJSON(context.Background(), opts.PostURL, result, opts.PostHeaders, opts.PostTimeout); err != nil {
+			ui.ShowError("Failed to post scan results", err)
+			outcome.PostFailed = true
+		}
+	}
+
+	// A signal may arrive while the reports are written
+	outcome.Interrupted = interrupted.Load()
+	return outcome, nil
+}
+
+// loadIndex loads the index of an earlier -incremental run from path, or
+// from DefaultIndexFile in outputDir, and returns it with the path to save
+// it to. A missing or unreadable index gives an empty one, so every file
+// is validated.
+func loadIndex(path, outputDir string) (*scan.Index, string) {
+	if path == "" {
+		path = filepath.Join(outputDir, DefaultIndexFile)
+	}
+	index, err := scan.LoadIndex(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			ui.ShowWarning(fmt.Sprintf("Ignoring unreadable index, running a full scan: %v", err))
+		}
+		index = &scan.Index{}
+	}
+	return index, path
+}
+
+// saveIndex saves the index for the next incremental run, creating its
+// folder if needed
+func saveIndex(index *scan.Index, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return index.Save(path)
+}
+
+// filterIssueTypes returns the issues whose type is in types, or all of
+// them when types is nil
+func filterIssueTypes(issues []models.Issue, types map[models.IssueType]bool) []models.Issue {
+	if types == nil {
+		return issues
+	}
+
+	var kept []models.Issue
+	for _, issue := range issues {
+		if types[issue.Type] {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
+
+func hasFormat(formats []string, format string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/reporter"
+)
+
+// testTree creates a clean file and one with an invalid character, and
+// returns the root and the path of the invalid one
+func testTree(t *testing.T) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "Report.docx"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	bad := filepath.Join(root, "Budget|2024.xlsx")
+	if err := os.WriteFile(bad, nil, 0644); err != nil {
+		t.Skipf("cannot create %q here: %v", bad, err)
+	}
+	return root, bad
+}
+
+func testOptions(root, output string) Options {
+	return Options{
+		Path:       root,
+		Config:     config.NewDefaultConfig(),
+		Output:     output,
+		Formats:    []string{reporter.FormatJSON, reporter.FormatCSV},
+		NoProgress: true,
+	}
+}
+
+// outputFile returns the single file in dir whose name ends in suffix
+func outputFile(t *testing.T, dir, suffix string) string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+suffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("found %v in %s, want one *%s", matches, dir, suffix)
+	}
+	return matches[0]
+}
+
+func TestRunWritesReports(t *testing.T) {
+	root, bad := testTree(t)
+	output := t.TempDir()
+
+	outcome, err := Run(testOptions(root, output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Summary.BySeverity[models.SeverityCritical] == 0 {
+		t.Errorf("outcome = %+v, want the Critical issue of %s counted", outcome, bad)
+	}
+	if outcome.ScanFailed || outcome.ReportFailed || outcome.Interrupted {
+		t.Errorf("outcome = %+v, want a clean run", outcome)
+	}
+
+	result, err := reporter.LoadJSON(outputFile(t, output, ".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IssuesFound == 0 || result.Issues[0].Path != bad {
+		t.Errorf("JSON report has issues %+v, want the issue of %s", result.Issues, bad)
+	}
+	outputFile(t, output, ".csv")
+}
+
+func TestRunOnlyTypesKeepsExitSummary(t *testing.T) {
+	root, _ := testTree(t)
+	output := t.TempDir()
+
+	opts := testOptions(root, output)
+	opts.Formats = []string{reporter.FormatJSON}
+	opts.OnlyTypes = map[models.IssueType]bool{models.IssuePathLength: true}
+	outcome, err := Run(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Summary.BySeverity[models.SeverityCritical] == 0 {
+		t.Errorf("outcome summary = %+v, want the filtered-out Critical issue still counted", outcome.Summary)
+	}
+
+	result, err := reporter.LoadJSON(outputFile(t, output, ".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 0 {
+		t.Errorf("JSON report has issues %+v, want only PathLength issues", result.Issues)
+	}
+}
+
+func TestRunVerifyFollowsRenames(t *testing.T) {
+	root, bad := testTree(t)
+	first := t.TempDir()
+	if _, err := Run(testOptions(root, first)); err != nil {
+		t.Fatal(err)
+	}
+	reportPath := outputFile(t, first, ".json")
+	previous, err := reporter.LoadJSON(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fix the name the way the rename map suggests
+	fixed := filepath.Join(root, "Budget_2024.xlsx")
+	if err := os.Rename(bad, fixed); err != nil {
+		t.Fatal(err)
+	}
+
+	second := t.TempDir()
+	opts := testOptions(root, second)
+	opts.Formats = nil
+	opts.Verify = previous
+	opts.VerifyReport = reportPath
+	outcome, err := Run(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Summary.BySeverity[models.SeverityCritical] != 0 {
+		t.Errorf("outcome summary = %+v, want the renamed file to have no issues", outcome.Summary)
+	}
+
+	data, err := os.ReadFile(outputFile(t, second, "-verify.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{reporter.VerifyResolved, fixed} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("verification report does not mention %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestRunIncrementalIndexAndBundle(t *testing.T) {
+	root, _ := testTree(t)
+	output := t.TempDir()
+
+	opts := testOptions(root, output)
+	opts.Incremental = true
+	opts.Bundle = true
+	opts.Zip = true
+	if _, err := Run(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// The index stays in the output directory, where the next run looks
+	// for it, rather than in this run's bundle
+	if _, err := os.Stat(filepath.Join(output, DefaultIndexFile)); err != nil {
+		t.Errorf("index not saved: %v", err)
+	}
+	zipPath := outputFile(t, output, ".zip")
+	bundleDir := strings.TrimSuffix(zipPath, ".zip")
+	if _, err := os.Stat(filepath.Join(bundleDir, "run.json")); err != nil {
+		t.Errorf("bundle is missing run.json: %v", err)
+	}
+	outputFile(t, bundleDir, ".csv")
+}
+
+func TestRunStopsOnSetupErrors(t *testing.T) {
+	root, _ := testTree(t)
+
+	opts := testOptions(root, t.TempDir())
+	opts.IgnoreFile = filepath.Join(t.TempDir(), "missing.txt")
+	_, err := Run(opts)
+
+	var runErr *Error
+	if !errors.As(err, &runErr) || runErr.Title != "Failed to load ignore file" {
+		t.Fatalf("Run returned %v, want the ignore file error", err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Run returned %v, want it to wrap the missing file", err)
+	}
+}
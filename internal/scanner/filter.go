@@ -0,0 +1,44 @@
+package scanner
+
+import (
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// FileFilter limits which files are validated by size and modification
+// time. Zero values leave the corresponding bound unset. Folders are never
+// filtered.
+type FileFilter struct {
+	MinSize        int64
+	MaxSize        int64
+	ModifiedBefore time.Time
+	ModifiedAfter  time.Time
+}
+
+// IsZero reports whether the filter has no bounds set
+func (f FileFilter) IsZero() bool {
+	return f.MinSize == 0 && f.MaxSize == 0 && f.ModifiedBefore.IsZero() && f.ModifiedAfter.IsZero()
+}
+
+// Includes reports whether an item passes the filter
+func (f FileFilter) Includes(item *models.FileSystemItem) bool {
+	if item.IsDir {
+		return true
+	}
+
+	if f.MinSize > 0 && item.Size < f.MinSize {
+		return false
+	}
+	if f.MaxSize > 0 && item.Size > f.MaxSize {
+		return false
+	}
+	if !f.ModifiedBefore.IsZero() && !item.ModTime.Before(f.ModifiedBefore) {
+		return false
+	}
+	if !f.ModifiedAfter.IsZero() && !item.ModTime.After(f.ModifiedAfter) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package scanner
+
+func isLockedWindows(path string) bool {
+	return false
+}
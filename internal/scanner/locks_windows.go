@@ -0,0 +1,26 @@
+//go:build windows
+
+package scanner
+
+import "golang.org/x/sys/windows"
+
+// isLockedWindows reports whether another process holds the file open in a
+// way that prevents an exclusive open. The file is opened for reading with
+// no sharing and closed immediately; errors other than a sharing or lock
+// violation, such as access denied, are not treated as a lock.
+func isLockedWindows(path string) bool {
+	handle, err := windows.CreateFile(
+		windows.StringToUTF16Ptr(path),
+		windows.GENERIC_READ,
+		0,
+		nil,
+		windows.OPEN_EXISTING,
+		windows.FILE_ATTRIBUTE_NORMAL,
+		0,
+	)
+	if err != nil {
+		return err == windows.ERROR_SHARING_VIOLATION || err == windows.ERROR_LOCK_VIOLATION
+	}
+	windows.CloseHandle(handle)
+	return false
+}
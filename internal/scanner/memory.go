@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"context"
+	"time"
+)
+
+// Channel buffer sizes. Without a memory limit the item buffers hold
+// defaultBufferSize items; with one they shrink with the limit, one slot
+// per bytesPerBufferSlot, down to minBufferSize.
+const (
+	defaultBufferSize  = 1000
+	minBufferSize      = 64
+	bytesPerBufferSlot = 256 << 10
+)
+
+// throttleWait is how often a throttled walk checks whether it may go on
+const throttleWait = 50 * time.Millisecond
+
+// SetMemoryLimit sizes the item channel buffers for a soft memory limit
+// in bytes, so fewer discovered items wait in memory on a tightly capped
+// scan. 0 or less keeps the default buffers. The limit itself is enforced
+// by the caller, which measures memory and calls Throttle.
+func (s *Scanner) SetMemoryLimit(limit int64) {
+	if limit <= 0 {
+		s.bufferSize = defaultBufferSize
+		return
+	}
+	size := limit / bytesPerBufferSlot
+	if size > defaultBufferSize {
+		size = defaultBufferSize
+	}
+	if size < minBufferSize {
+		size = minBufferSize
+	}
+	s.bufferSize = int(size)
+}
+
+// Throttle pauses the walk before its next item, or lets it go on. Items
+// already discovered are still validated and delivered, so the consumer
+// can catch up and free memory. It is safe to call from any goroutine.
+func (s *Scanner) Throttle(paused bool) {
+	s.throttled.Store(paused)
+}
+
+// waitThrottle blocks while the walk is throttled
+func (s *Scanner) waitThrottle(ctx context.Context) error {
+	for s.throttled.Load() {
+		select {
+		case <-time.After(throttleWait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
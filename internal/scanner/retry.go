@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// DefaultReadRetries is how many times a read that fails with a transient
+// error is repeated unless SetReadRetries changes it
+const DefaultReadRetries = 2
+
+// DefaultReadRetryBackoff is the wait before the first repeat of a failed
+// read; it doubles for each repeat after that
+const DefaultReadRetryBackoff = 500 * time.Millisecond
+
+// isTransient reports whether a read error may go away when the read is
+// repeated, such as a timeout or a network share that briefly dropped.
+// Missing paths and permission errors are permanent.
+func isTransient(err error) bool {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+		return false
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return transientErrnos[errno] || errno.Timeout()
+	}
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
+
+// retryRead repeats read while err is transient, up to the configured
+// number of retries, waiting longer before each one. It returns the
+// number of retries made and the last error, which is nil once a read
+// succeeds. A cancelled scan stops waiting and returns the last error.
+func (s *Scanner) retryRead(ctx context.Context, path string, err error, read func() error) (int, error) {
+	backoff := s.readBackoff
+	retries := 0
+	for err != nil && retries < s.readRetries && isTransient(err) {
+		s.logger.Debug("retrying read", "path", path, "error", err, "wait", backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return retries, err
+		}
+
+		backoff *= 2
+		retries++
+		err = read()
+	}
+	return retries, err
+}
+
+// recordRetried records a path that could not be read, noting how many
+// times the read was repeated first
+func (s *Scanner) recordRetried(path string, retries int, err error) {
+	if retries == 0 {
+		s.recordError(path, err)
+		return
+	}
+
+	s.logger.Debug("cannot read path after retries", "path", path, "retries", retries, "error", err)
+
+	times := fmt.Sprintf("%d times", retries)
+	if retries == 1 {
+		times = "once"
+	}
+
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+
+	s.scanErrors = append(s.scanErrors, models.ScanError{
+		Path:    path,
+		Message: fmt.Sprintf("retried %s, failed: %v", times, err),
+		Retries: retries,
+	})
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package scanner
+
+import "syscall"
+
+// transientErrnos are the errors from reading a local disk or network
+// mount that can clear up on their own
+var transientErrnos = map[syscall.Errno]bool{
+	syscall.EIO:          true,
+	syscall.EINTR:        true,
+	syscall.EAGAIN:       true,
+	syscall.ETIMEDOUT:    true,
+	syscall.ESTALE:       true,
+	syscall.EHOSTDOWN:    true,
+	syscall.EHOSTUNREACH: true,
+	syscall.ENETDOWN:     true,
+	syscall.ENETUNREACH:  true,
+	syscall.ENETRESET:    true,
+	syscall.ECONNRESET:   true,
+	syscall.ECONNABORTED: true,
+}
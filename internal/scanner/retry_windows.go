@@ -0,0 +1,26 @@
+//go:build windows
+
+package scanner
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// transientErrnos are the errors from reading a network share that can
+// clear up on their own, such as "The specified network name is no longer
+// available"
+var transientErrnos = map[syscall.Errno]bool{
+	windows.ERROR_NETNAME_DELETED:     true,
+	windows.ERROR_UNEXP_NET_ERR:       true,
+	windows.ERROR_NETWORK_BUSY:        true,
+	windows.ERROR_BAD_NET_RESP:        true,
+	windows.ERROR_SEM_TIMEOUT:         true,
+	windows.ERROR_NETWORK_UNREACHABLE: true,
+	windows.ERROR_HOST_UNREACHABLE:    true,
+	windows.ERROR_CONNECTION_ABORTED:  true,
+	windows.ERROR_NOT_READY:           true,
+	windows.WSAETIMEDOUT:              true,
+	windows.WSAECONNRESET:             true,
+}
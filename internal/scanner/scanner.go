@@ -2,9 +2,13 @@ package scanner
 
 import (
 	"context"
+	"fmt"
 	"io/fs"
+	"log/slog"
+	"os"
 	"path/filepath"
 	"runtime"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -13,20 +17,67 @@ import (
 	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
 )
 
+// ItemValidator validates a single item. It is implemented by
+// validator.Validator and must be safe for concurrent use.
+type ItemValidator interface {
+	ValidateItem(item *models.FileSystemItem) []models.Issue
+}
+
+// ListingObserver is implemented by validators with checks that compare
+// the items of one folder with each other. If the validator implements
+// it, the scanner calls ObserveListing from the walk with each item before
+// sending it on, in walk order, so a folder's items arrive together.
+type ListingObserver interface {
+	ObserveListing(item *models.FileSystemItem)
+}
+
+// DefaultProgressInterval is how often progress is sent unless
+// SetProgressInterval changes it
+const DefaultProgressInterval = 500 * time.Millisecond
+
 // Scanner performs file system scanning
 type Scanner struct {
-	rootPath       string
-	excludeFolders map[string]bool
-	maxItems       int64
-	workerCount    int
-	progressChan   chan *models.ScanProgress
+	rootPath        string
+	excludeFolders  map[string]bool
+	excludeGlobs    []string // Entries with wildcards, such as ".Trash-*"
+	maxItems        int64
+	workerCount     int
+	progressEvery   time.Duration
+	progressChan    chan *models.ScanProgress
+	validator       ItemValidator
+	lister          ListingObserver
+	checkLocks      bool
+	checkStreams    bool
+	checkShortNames bool
+	sniffMaxSize    int64
+	filter          FileFilter
+	countFiltered   bool
+	skipInfo        bool
+	readRetries     int
+	readBackoff     time.Duration
+	bufferSize      int
+	throttled       atomic.Bool
+	logger          *slog.Logger
+
+	errMu      sync.Mutex
+	scanErrors []models.ScanError
+
+	// Written by the walk only and read once it has finished
+	contents  models.FolderContents
+	truncated bool
 }
 
 // NewScanner creates a new Scanner instance
 func NewScanner(rootPath string, excludeFolders []string, maxItems int64) *Scanner {
 	excludeMap := make(map[string]bool)
+	var excludeGlobs []string
 	for _, folder := range excludeFolders {
-		excludeMap[strings.ToLower(folder)] = true
+		folder = strings.ToLower(folder)
+		if strings.ContainsAny(folder, "*?[") {
+			excludeGlobs = append(excludeGlobs, folder)
+			continue
+		}
+		excludeMap[folder] = true
 	}
 
 	// Use CPU count for parallel processing
@@ -38,15 +89,130 @@ func NewScanner(rootPath string, excludeFolders []string, maxItems int64) *Scann
 	return &Scanner{
 		rootPath:       rootPath,
 		excludeFolders: excludeMap,
+		excludeGlobs:   excludeGlobs,
 		maxItems:       maxItems,
 		workerCount:    workerCount,
+		progressEvery:  DefaultProgressInterval,
 		progressChan:   make(chan *models.ScanProgress, 100),
+		readRetries:    DefaultReadRetries,
+		readBackoff:    DefaultReadRetryBackoff,
+		bufferSize:     defaultBufferSize,
+		logger:         slog.New(slog.DiscardHandler),
+	}
+}
+
+// SetLogger sets where the scanner logs skipped paths and access errors,
+// at debug level, and panics in checks, at error level. By default
+// nothing is logged.
+func (s *Scanner) SetLogger(logger *slog.Logger) {
+	if logger != nil {
+		s.logger = logger
+	}
+}
+
+// SetValidator makes the scanner validate items as they are discovered.
+// Validation runs on the scanner's worker goroutines and the results are
+// attached to each item's Issues field, so consumers only aggregate.
+func (s *Scanner) SetValidator(v ItemValidator) {
+	s.validator = v
+	s.lister, _ = v.(ListingObserver)
+}
+
+// SetWorkers overrides the number of goroutines that validate items and
+// check locks. The default is the CPU count capped at 8; an explicit count
+// is not capped. Counts below 1 keep the default.
+func (s *Scanner) SetWorkers(n int) {
+	if n >= 1 {
+		s.workerCount = n
+	}
+}
+
+// SetProgressInterval sets how often progress is sent while the walk is
+// busy in a folder. Durations of 0 or less keep DefaultProgressInterval.
+func (s *Scanner) SetProgressInterval(d time.Duration) {
+	if d > 0 {
+		s.progressEvery = d
 	}
 }
 
+// SetReadRetries sets how many times a folder listing or file lookup that
+// fails with a transient error, such as a timeout on a network share, is
+// repeated, and the wait before the first repeat, which doubles for each
+// one after it. A count of 0 keeps DefaultReadRetries and a negative count
+// disables retrying; durations of 0 or less keep DefaultReadRetryBackoff.
+// Missing paths and permission errors are never retried.
+func (s *Scanner) SetReadRetries(retries int, backoff time.Duration) {
+	if retries < 0 {
+		s.readRetries = 0
+	} else if retries > 0 {
+		s.readRetries = retries
+	}
+	if backoff > 0 {
+		s.readBackoff = backoff
+	}
+}
+
+// SetCheckLocks makes the scanner try an exclusive open of every file to
+// detect files that are in use by another process. This adds a file open
+// per item and is only supported on Windows; elsewhere it has no effect.
+func (s *Scanner) SetCheckLocks(enabled bool) {
+	s.checkLocks = enabled
+}
+
+// SetCheckStreams makes the scanner list the NTFS alternate data streams
+// of every file into Streams. This adds two or more system calls per file
+// and is only supported on Windows; elsewhere it has no effect. Reparse
+// points are skipped.
+func (s *Scanner) SetCheckStreams(enabled bool) {
+	s.checkStreams = enabled
+}
+
+// SetCheckShortNames makes the scanner look up the 8.3 short name of every
+// item into ShortName. This adds a system call per item and is only
+// supported on Windows; elsewhere it has no effect. Reparse points are
+// skipped.
+func (s *Scanner) SetCheckShortNames(enabled bool) {
+	s.checkShortNames = enabled
+}
+
+// SetSniff makes the scanner read the first few KB of every file up to
+// maxSize bytes and record the detected format in ContentType. Cloud
+// placeholders and other reparse points are never read, so sniffing does
+// not trigger downloads. A maxSize of 0 disables sniffing.
+func (s *Scanner) SetSniff(maxSize int64) {
+	s.sniffMaxSize = maxSize
+}
+
+// SetFilter skips files that do not pass filter. Skipped files are not
+// sent at all unless countFiltered is set, in which case they are sent
+// with Filtered set and without being validated, so they still count
+// toward totals.
+func (s *Scanner) SetFilter(filter FileFilter, countFiltered bool) {
+	s.filter = filter
+	s.countFiltered = countFiltered
+}
+
+// SetSkipInfo makes the walk build items from the folder listing alone,
+// without the stat of every item that reading its size and modification
+// time costs on most systems. Size and ModTime are left zero, except on
+// items with issues, which are stat'ed after validation so reports still
+// show them. Use it only when no check or filter reads them. ScanPaths
+// stats every path regardless.
+func (s *Scanner) SetSkipInfo(skip bool) {
+	s.skipInfo = skip
+}
+
 // Scan performs the file system scan and returns all items
 func (s *Scanner) Scan(ctx context.Context) (<-chan *models.FileSystemItem, <-chan *models.ScanProgress, <-chan error) {
-	itemsChan := make(chan *models.FileSystemItem, 1000)
+	return s.run(ctx, func(itemsChan chan<- *models.FileSystemItem, progressChan chan<- *models.ScanProgress) error {
+		return s.scanDirectory(ctx, itemsChan, progressChan)
+	})
+}
+
+// run executes a discovery function in the background, wiring up the
+// result channels and, when a validator is set, the validation workers.
+func (s *Scanner) run(ctx context.Context, discover func(chan<- *models.FileSystemItem, chan<- *models.ScanProgress) error) (<-chan *models.FileSystemItem, <-chan *models.ScanProgress, <-chan error) {
+	itemsChan := make(chan *models.FileSystemItem, s.bufferSize)
 	progressChan := make(chan *models.ScanProgress, 100)
 	errChan := make(chan error, 1)
 
@@ -55,7 +221,20 @@ func (s *Scanner) Scan(ctx context.Context) (<-chan *models.FileSystemItem, <-ch
 		defer close(progressChan)
 		defer close(errChan)
 
-		if err := s.scanDirectory(ctx, itemsChan, progressChan); err != nil {
+		// Discovered items go straight out unless they are validated first
+		discovered := itemsChan
+		var validated <-chan struct{}
+		if s.validator != nil || s.checkLocks || s.checkStreams || s.checkShortNames || s.sniffMaxSize > 0 {
+			discovered, validated = s.startValidators(ctx, itemsChan)
+		}
+
+		err := discover(discovered, progressChan)
+		if validated != nil {
+			close(discovered)
+			<-validated
+		}
+
+		if err != nil {
 			errChan <- err
 		}
 	}()
@@ -63,6 +242,84 @@ func (s *Scanner) Scan(ctx context.Context) (<-chan *models.FileSystemItem, <-ch
 	return itemsChan, progressChan, errChan
 }
 
+// startValidators returns the channel discovered items should be sent on.
+// workerCount goroutines check locks on, list streams of, look up short names of, sniff and validate items from that
+// channel and forward them to out; the returned done channel is closed once the input channel is
+// closed and drained.
+func (s *Scanner) startValidators(ctx context.Context, out chan<- *models.FileSystemItem) (chan *models.FileSystemItem, <-chan struct{}) {
+	in := make(chan *models.FileSystemItem, s.bufferSize)
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range in {
+				if s.checkLocks && !item.IsDir && !item.Filtered {
+					item.IsLocked = isLockedWindows(item.Path)
+				}
+				if s.checkStreams && !item.IsDir && !item.Filtered && item.ReparseType == "" {
+					item.Streams = alternateStreamsWindows(item.Path)
+				}
+				if s.checkShortNames && !item.Filtered && item.ReparseType == "" {
+					item.ShortName = shortNameWindows(item.Path)
+				}
+				if s.shouldSniff(item) {
+					item.ContentType = sniffContentType(item.Path)
+				}
+				if s.validator != nil && !item.Filtered {
+					item.Issues = s.validateItem(item)
+				}
+				if s.skipInfo && len(item.Issues) > 0 {
+					s.statItem(item)
+				}
+				select {
+				case out <- item:
+				case <-ctx.Done():
+					// Keep draining so the walk never blocks
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	return in, done
+}
+
+// validateItem validates one item. A panic in a check is logged with its
+// stack and recorded as a scan error for the item, so one bad file does
+// not end the scan.
+func (s *Scanner) validateItem(item *models.FileSystemItem) (issues []models.Issue) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("validation panicked", "path", item.Path, "panic", r, "stack", string(debug.Stack()))
+			s.recordError(item.Path, fmt.Errorf("validation failed: %v", r))
+			issues = nil
+		}
+	}()
+
+	return s.validator.ValidateItem(item)
+}
+
+// observeListing passes an item the validator will see on to its
+// ListingObserver, if it has one
+func (s *Scanner) observeListing(item *models.FileSystemItem) {
+	if s.lister != nil && !item.Filtered {
+		s.lister.ObserveListing(item)
+	}
+}
+
+// shouldSniff reports whether the content of item should be sniffed
+func (s *Scanner) shouldSniff(item *models.FileSystemItem) bool {
+	return s.sniffMaxSize > 0 && !item.IsDir && !item.Filtered && item.ReparseType == "" &&
+		item.Size > 0 && item.Size <= s.sniffMaxSize
+}
+
 func (s *Scanner) scanDirectory(ctx context.Context, itemsChan chan<- *models.FileSystemItem, progressChan chan<- *models.ScanProgress) error {
 	var (
 		itemsScanned int64
@@ -72,13 +329,28 @@ func (s *Scanner) scanDirectory(ctx context.Context, itemsChan chan<- *models.Fi
 		mu           sync.Mutex
 	)
 
-	// Progress reporting ticker
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
+	// Progress reporting ticker. Stopping a ticker does not close its
+	// channel, so the goroutine also ends on stop, which is closed once the
+	// walk returns, and is waited for before the final update is sent.
+	stop := make(chan struct{})
+	var ticking sync.WaitGroup
+	ticking.Add(1)
 
 	var currentPath string
 	go func() {
-		for range ticker.C {
+		defer ticking.Done()
+		ticker := time.NewTicker(s.progressEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+
 			mu.Lock()
 			path := currentPath
 			mu.Unlock()
@@ -91,14 +363,23 @@ func (s *Scanner) scanDirectory(ctx context.Context, itemsChan chan<- *models.Fi
 				BytesScanned: atomic.LoadInt64(&bytesScanned),
 				CurrentPath:  path,
 			}:
+			case <-stop:
+				return
 			case <-ctx.Done():
 				return
 			}
 		}
 	}()
 
+	s.contents = models.FolderContents{
+		Excluded: make(map[string]bool),
+		Unread:   make(map[string]bool),
+	}
+	limitReached := false
+
 	// Walk the file system
-	err := filepath.WalkDir(s.rootPath, func(path string, d fs.DirEntry, err error) error {
+	var visit fs.WalkDirFunc
+	visit = func(path string, d fs.DirEntry, err error) error {
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
@@ -107,13 +388,36 @@ func (s *Scanner) scanDirectory(ctx context.Context, itemsChan chan<- *models.Fi
 		}
 
 		if err != nil {
-			// Skip directories we can't access
+			// Skip directories we can't access, once transient errors
+			// have been retried
 			if d != nil && d.IsDir() {
+				retries, err := s.retryRead(ctx, path, err, func() error {
+					_, err := os.ReadDir(path)
+					return err
+				})
+				if err == nil {
+					return s.walkRetried(path, retries, visit)
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				s.logger.Debug("skipping unreadable folder", "path", path, "error", err)
+				if isTransient(err) {
+					s.recordRetried(path, retries, err)
+				}
+				s.contents.Unread[path] = true
 				return filepath.SkipDir
 			}
+			s.logger.Debug("skipping unreadable file", "path", path, "error", err)
+			s.contents.Unread[filepath.Dir(path)] = true
 			return nil // Skip files with errors
 		}
 
+		// The root itself is not an item; only its contents are scanned
+		if path == s.rootPath {
+			return nil
+		}
+
 		// Update current path for progress
 		mu.Lock()
 		currentPath = path
@@ -121,43 +425,56 @@ func (s *Scanner) scanDirectory(ctx context.Context, itemsChan chan<- *models.Fi
 
 		// Check if we should exclude this directory
 		if d.IsDir() && s.shouldExcludeDir(d.Name()) {
+			s.logger.Debug("skipping excluded folder", "path", path)
+			s.contents.Excluded[filepath.Dir(path)] = true
 			return filepath.SkipDir
 		}
 
-		// Check max items limit
-		if s.maxItems > 0 && atomic.LoadInt64(&itemsScanned) >= s.maxItems {
-			return filepath.SkipAll
+		// Get file info, unless nothing needs more than the listing has
+		var info fs.FileInfo = entryInfo{d}
+		retries := 0
+		if !s.skipInfo {
+			info, err = d.Info()
 		}
-
-		// Get file info
-		info, err := d.Info()
 		if err != nil {
+			retries, err = s.retryRead(ctx, path, err, func() (err error) {
+				info, err = os.Lstat(path)
+				return err
+			})
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.logger.Debug("skipping item without file info", "path", path, "error", err)
+			if isTransient(err) {
+				s.recordRetried(path, retries, err)
+			}
+			s.contents.Unread[filepath.Dir(path)] = true
 			return nil // Skip if we can't get info
 		}
 
-		// Create relative path
-		relPath, err := filepath.Rel(s.rootPath, path)
-		if err != nil {
-			relPath = path
+		// Create file system item
+		item := s.newItem(path, d.Name(), d.IsDir(), info)
+		if !s.applyFilter(item) {
+			return nil
 		}
 
-		// Determine if hidden/system file
-		isHidden := s.isHidden(d.Name(), path)
-		isSystem := s.isSystem(path)
+		// Stop at the item limit. Only this goroutine adds to
+		// itemsScanned, so the count cannot pass the limit, and the scan
+		// is only truncated when an item that would be sent is left out.
+		if s.maxItems > 0 && atomic.LoadInt64(&itemsScanned) >= s.maxItems {
+			s.logger.Debug("stopping at item limit", "maxItems", s.maxItems)
+			limitReached = true
+			return filepath.SkipAll
+		}
 
-		// Create file system item
-		item := &models.FileSystemItem{
-			Path:         path,
-			Name:         d.Name(),
-			IsDir:        d.IsDir(),
-			Size:         info.Size(),
-			ModTime:      info.ModTime(),
-			IsHidden:     isHidden,
-			IsSystem:     isSystem,
-			RelativePath: relPath,
-		}
-
-		// Send item to channel
+		// Send item to channel, once the consumer has caught up if the
+		// walk is throttled
+		if err := s.waitThrottle(ctx); err != nil {
+			return err
+		}
+		s.observeListing(item)
 		select {
 		case itemsChan <- item:
 			atomic.AddInt64(&itemsScanned, 1)
@@ -171,23 +488,257 @@ func (s *Scanner) scanDirectory(ctx context.Context, itemsChan chan<- *models.Fi
 			return ctx.Err()
 		}
 
+		// Mount points and junctions lead to other volumes or folders;
+		// report them but do not scan through them
+		if d.IsDir() && item.ReparseType == models.ReparseMountPoint {
+			s.logger.Debug("not scanning through mount point", "path", path)
+			s.contents.Unread[path] = true
+			return filepath.SkipDir
+		}
+
 		return nil
-	})
+	}
+	err := filepath.WalkDir(s.rootPath, visit)
+	s.contents.Complete = err == nil && !limitReached
+	s.truncated = limitReached
+
+	close(stop)
+	ticking.Wait()
 
 	// Send final progress update
-	progressChan <- &models.ScanProgress{
+	sendFinalProgress(ctx, progressChan, &models.ScanProgress{
 		ItemsScanned: atomic.LoadInt64(&itemsScanned),
 		FilesScanned: atomic.LoadInt64(&filesScanned),
 		DirsScanned:  atomic.LoadInt64(&dirsScanned),
 		BytesScanned: atomic.LoadInt64(&bytesScanned),
 		CurrentPath:  "",
-	}
+	})
 
 	return err
 }
 
+// walkRetried walks the contents of a folder whose listing failed and then
+// succeeded on a retry. The folder itself has already been visited; if
+// listing it fails yet again, it is given up on.
+func (s *Scanner) walkRetried(dir string, retries int, visit fs.WalkDirFunc) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if path != dir {
+			return visit(path, d, err)
+		}
+		if err != nil {
+			s.logger.Debug("skipping unreadable folder", "path", path, "error", err)
+			s.recordRetried(path, retries, err)
+			s.contents.Unread[path] = true
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// ScanPaths validates an explicit list of paths instead of walking the
+// root directory. Paths are stat'ed individually; paths that cannot be
+// read are recorded in Errors rather than aborting the scan.
+func (s *Scanner) ScanPaths(ctx context.Context, paths []string) (<-chan *models.FileSystemItem, <-chan *models.ScanProgress, <-chan error) {
+	return s.run(ctx, func(itemsChan chan<- *models.FileSystemItem, progressChan chan<- *models.ScanProgress) error {
+		return s.scanPathList(ctx, paths, itemsChan, progressChan)
+	})
+}
+
+func (s *Scanner) scanPathList(ctx context.Context, paths []string, itemsChan chan<- *models.FileSystemItem, progressChan chan<- *models.ScanProgress) error {
+	progress := &models.ScanProgress{}
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			var retries int
+			retries, err = s.retryRead(ctx, path, err, func() (err error) {
+				info, err = os.Lstat(path)
+				return err
+			})
+			if err != nil {
+				s.recordRetried(path, retries, err)
+				continue
+			}
+		}
+
+		item := s.newItem(path, info.Name(), info.IsDir(), info)
+		if !s.applyFilter(item) {
+			continue
+		}
+
+		if s.maxItems > 0 && progress.ItemsScanned >= s.maxItems {
+			s.truncated = true
+			break
+		}
+
+		if err := s.waitThrottle(ctx); err != nil {
+			return err
+		}
+		s.observeListing(item)
+		select {
+		case itemsChan <- item:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		progress.ItemsScanned++
+		if info.IsDir() {
+			progress.DirsScanned++
+		} else {
+			progress.FilesScanned++
+			progress.BytesScanned += info.Size()
+		}
+		progress.CurrentPath = path
+
+		// Progress is best effort; never block the scan on it
+		update := *progress
+		select {
+		case progressChan <- &update:
+		default:
+		}
+	}
+
+	progress.CurrentPath = ""
+	sendFinalProgress(ctx, progressChan, progress)
+
+	return nil
+}
+
+// sendFinalProgress sends the last progress update of a scan. It waits for
+// room on the channel unless the scan was cancelled, since the consumer
+// may have stopped reading by then; a cancelled scan takes its totals from
+// the items it received.
+func sendFinalProgress(ctx context.Context, progressChan chan<- *models.ScanProgress, progress *models.ScanProgress) {
+	select {
+	case progressChan <- progress:
+		return
+	default:
+	}
+
+	select {
+	case progressChan <- progress:
+	case <-ctx.Done():
+	}
+}
+
+// FolderContents reports which folders were not fully listed by the walk.
+// Call it once the scan has finished. After ScanPaths it is never
+// Complete, since folders are not listed at all.
+func (s *Scanner) FolderContents() models.FolderContents {
+	return s.contents
+}
+
+// Truncated reports whether the scan stopped at the item limit with
+// items left unscanned. Call it once the scan has finished.
+func (s *Scanner) Truncated() bool {
+	return s.truncated
+}
+
+// Errors returns the paths that could not be scanned
+func (s *Scanner) Errors() []models.ScanError {
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+
+	errs := make([]models.ScanError, len(s.scanErrors))
+	copy(errs, s.scanErrors)
+	return errs
+}
+
+func (s *Scanner) recordError(path string, err error) {
+	s.logger.Debug("cannot read path", "path", path, "error", err)
+
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+
+	s.scanErrors = append(s.scanErrors, models.ScanError{
+		Path:    path,
+		Message: err.Error(),
+	})
+}
+
+// newItem builds a FileSystemItem for a path below the scan root
+func (s *Scanner) newItem(path, name string, isDir bool, info fs.FileInfo) *models.FileSystemItem {
+	// Create relative path
+	relPath, err := filepath.Rel(s.rootPath, path)
+	if err != nil {
+		relPath = path
+	}
+
+	reparseType := reparseTypeWindows(path)
+	if reparseType == "" && info.Mode()&fs.ModeSymlink != 0 {
+		reparseType = models.ReparseSymlink
+	}
+
+	return &models.FileSystemItem{
+		Path:         path,
+		Name:         name,
+		IsDir:        isDir,
+		Size:         info.Size(),
+		ModTime:      info.ModTime(),
+		IsHidden:     s.isHidden(name, path),
+		IsSystem:     s.isSystem(path),
+		ReparseType:  reparseType,
+		RelativePath: relPath,
+	}
+}
+
+// statItem reads the size and modification time of an item built by a
+// walk that skipped them, and copies the size into its issues. An item
+// that can no longer be read keeps zeros.
+func (s *Scanner) statItem(item *models.FileSystemItem) {
+	info, err := os.Lstat(item.Path)
+	if err != nil {
+		s.logger.Debug("cannot read file info", "path", item.Path, "error", err)
+		return
+	}
+	item.Size = info.Size()
+	item.ModTime = info.ModTime()
+	for i := range item.Issues {
+		if item.Issues[i].Path == item.Path {
+			item.Issues[i].Size = item.Size
+		}
+	}
+}
+
+// entryInfo is the file info of a directory entry that was not stat'ed.
+// Only its name and type are known; its size and modification time are
+// zero.
+type entryInfo struct {
+	fs.DirEntry
+}
+
+func (e entryInfo) Size() int64        { return 0 }
+func (e entryInfo) Mode() fs.FileMode  { return e.Type() }
+func (e entryInfo) ModTime() time.Time { return time.Time{} }
+func (e entryInfo) Sys() interface{}   { return nil }
+
+// applyFilter marks items that fail the file filter and reports whether
+// the item should still be sent
+func (s *Scanner) applyFilter(item *models.FileSystemItem) bool {
+	if s.filter.Includes(item) {
+		return true
+	}
+	item.Filtered = true
+	if s.contents.Excluded != nil {
+		s.contents.Excluded[filepath.Dir(item.Path)] = true
+	}
+	return s.countFiltered
+}
+
 func (s *Scanner) shouldExcludeDir(name string) bool {
-	return s.excludeFolders[strings.ToLower(name)]
+	name = strings.ToLower(name)
+	if s.excludeFolders[name] {
+		return true
+	}
+	for _, glob := range s.excludeGlobs {
+		if matched, _ := filepath.Match(glob, name); matched {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *Scanner) isHidden(name, path string) bool {
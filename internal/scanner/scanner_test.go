@@ -0,0 +1,123 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// collect runs a scan to the end and returns its items and its last
+// progress update
+func collect(t *testing.T, s *Scanner) ([]*models.FileSystemItem, *models.ScanProgress) {
+	t.Helper()
+	itemsChan, progressChan, errChan := s.Scan(context.Background())
+
+	var items []*models.FileSystemItem
+	var last *models.ScanProgress
+	for itemsChan != nil || progressChan != nil || errChan != nil {
+		select {
+		case item, ok := <-itemsChan:
+			if !ok {
+				itemsChan = nil
+				continue
+			}
+			items = append(items, item)
+		case progress, ok := <-progressChan:
+			if !ok {
+				progressChan = nil
+				continue
+			}
+			last = progress
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return items, last
+}
+
+func TestScanReportsChildrenNotRoot(t *testing.T) {
+	root := t.TempDir()
+	children := []string{"a.txt", "b.docx", "Folder", filepath.Join("Folder", "c.xlsx"), filepath.Join("Folder", "Sub")}
+	for _, child := range children {
+		path := filepath.Join(root, child)
+		var err error
+		if filepath.Ext(child) == "" {
+			err = os.MkdirAll(path, 0755)
+		} else {
+			err = os.WriteFile(path, []byte("x"), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, progress := collect(t, NewScanner(root, nil, 0))
+	if len(items) != len(children) {
+		t.Fatalf("got %d items, want %d", len(items), len(children))
+	}
+	for _, item := range items {
+		if item.Path == root || item.RelativePath == "." {
+			t.Errorf("the scan root was sent as an item: %+v", item)
+		}
+	}
+	if progress == nil || progress.ItemsScanned != int64(len(children)) || progress.DirsScanned != 2 || progress.FilesScanned != 3 {
+		t.Errorf("final progress = %+v, want 5 items, 2 folders and 3 files", progress)
+	}
+}
+
+func TestScanEmptyRootHasNoItems(t *testing.T) {
+	items, _ := collect(t, NewScanner(t.TempDir(), nil, 0))
+	if len(items) != 0 {
+		t.Errorf("got %d items from an empty folder, want 0", len(items))
+	}
+}
+
+// TestScanStopsWhenConsumerLeaves cancels a scan and stops reading its
+// channels, as a caller that gives up does, and checks that the walk, the
+// progress ticker and the workers all exit rather than block on a send
+func TestScanStopsWhenConsumerLeaves(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 2000; i++ {
+		if err := os.WriteFile(filepath.Join(root, fmt.Sprintf("file%04d.txt", i)), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, validate := range []bool{false, true} {
+		before := runtime.NumGoroutine()
+		s := NewScanner(root, nil, 0)
+		s.SetProgressInterval(time.Millisecond)
+		if validate {
+			s.SetWorkers(4)
+			s.SetCheckLocks(true)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		itemsChan, _, _ := s.Scan(ctx)
+		for i := 0; i < 10; i++ {
+			<-itemsChan
+		}
+		cancel()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				buf := make([]byte, 1<<20)
+				buf = buf[:runtime.Stack(buf, true)]
+				t.Fatalf("validate=%v: %d goroutines running, %d before the scan:\n%s", validate, runtime.NumGoroutine(), before, buf)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
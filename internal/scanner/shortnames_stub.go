@@ -0,0 +1,7 @@
+//go:build !windows
+
+package scanner
+
+func shortNameWindows(path string) string {
+	return ""
+}
@@ -0,0 +1,41 @@
+//go:build windows
+
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// shortNameWindows returns the 8.3 short name Windows generated for an
+// item, such as REPORT~1.DOC for "Report 2024.docx". Items with no short
+// name, those whose short name is their own name in another case, and
+// those that cannot be looked up return "".
+func shortNameWindows(path string) string {
+	longPath, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return ""
+	}
+
+	buf := make([]uint16, windows.MAX_PATH)
+	for {
+		n, err := windows.GetShortPathName(longPath, &buf[0], uint32(len(buf)))
+		if err != nil || n == 0 {
+			return ""
+		}
+		if int(n) < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		// Too small; n is the size needed including the terminating NUL
+		buf = make([]uint16, n)
+	}
+
+	short := filepath.Base(windows.UTF16ToString(buf))
+	if strings.EqualFold(short, filepath.Base(path)) {
+		return ""
+	}
+	return short
+}
@@ -0,0 +1,73 @@
+package scanner
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// DefaultSniffMaxSize is the largest file content sniffing reads from
+// when no limit is set
+const DefaultSniffMaxSize = 100 << 20
+
+// sniffBytes is how much of each file content sniffing reads
+const sniffBytes = 4096
+
+// signatures maps leading magic numbers to a content type. ZIP archives
+// are refined into Office formats by looking at the entry names.
+var signatures = []struct {
+	magic       []byte
+	contentType string
+}{
+	{[]byte("%PDF-"), models.ContentTypePDF},
+	{[]byte("\x89PNG\r\n\x1a\n"), models.ContentTypePNG},
+	{[]byte{0xFF, 0xD8, 0xFF}, models.ContentTypeJPEG},
+	{[]byte("PK\x03\x04"), models.ContentTypeZIP},
+	{[]byte("AC10"), models.ContentTypeDWG},
+}
+
+// sniffContentType reads the start of a file and returns its content
+// type, or "" if it cannot be read or is not recognized
+func sniffContentType(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	header := make([]byte, sniffBytes)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return ""
+	}
+	return detectContentType(header[:n])
+}
+
+func detectContentType(header []byte) string {
+	for _, sig := range signatures {
+		if !bytes.HasPrefix(header, sig.magic) {
+			continue
+		}
+		if sig.contentType == models.ContentTypeZIP {
+			return detectOfficeType(header)
+		}
+		return sig.contentType
+	}
+	return ""
+}
+
+// detectOfficeType tells OOXML documents apart from other ZIP archives by
+// the part names stored in the first local file headers
+func detectOfficeType(header []byte) string {
+	switch {
+	case bytes.Contains(header, []byte("word/")):
+		return models.ContentTypeWord
+	case bytes.Contains(header, []byte("xl/")):
+		return models.ContentTypeExcel
+	case bytes.Contains(header, []byte("ppt/")):
+		return models.ContentTypePowerPoint
+	}
+	return models.ContentTypeZIP
+}
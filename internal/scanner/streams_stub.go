@@ -0,0 +1,7 @@
+//go:build !windows
+
+package scanner
+
+func alternateStreamsWindows(path string) []string {
+	return nil
+}
@@ -0,0 +1,64 @@
+//go:build windows
+
+package scanner
+
+import (
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// FindFirstStreamW and FindNextStreamW are not wrapped by
+// golang.org/x/sys/windows
+var (
+	modkernel32          = windows.NewLazySystemDLL("kernel32.dll")
+	procFindFirstStreamW = modkernel32.NewProc("FindFirstStreamW")
+	procFindNextStreamW  = modkernel32.NewProc("FindNextStreamW")
+)
+
+// findStreamInfoStandard is FindStreamInfoStandard from the
+// STREAM_INFO_LEVELS enumeration
+const findStreamInfoStandard = 0
+
+// win32FindStreamData mirrors WIN32_FIND_STREAM_DATA
+type win32FindStreamData struct {
+	StreamSize int64
+	StreamName [windows.MAX_PATH + 36]uint16
+}
+
+// alternateStreamsWindows returns the names of a file's alternate data
+// streams, such as "Zone.Identifier". The default unnamed stream is left
+// out, and a file whose streams cannot be listed has none.
+func alternateStreamsWindows(path string) []string {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil
+	}
+
+	var data win32FindStreamData
+	handle, _, _ := procFindFirstStreamW.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		findStreamInfoStandard,
+		uintptr(unsafe.Pointer(&data)),
+		0,
+	)
+	if windows.Handle(handle) == windows.InvalidHandle {
+		return nil
+	}
+	defer windows.FindClose(windows.Handle(handle))
+
+	var streams []string
+	for {
+		// Names look like ":Zone.Identifier:$DATA"; the default is "::$DATA"
+		name := windows.UTF16ToString(data.StreamName[:])
+		name = strings.TrimSuffix(strings.TrimPrefix(name, ":"), ":$DATA")
+		if name != "" {
+			streams = append(streams, name)
+		}
+
+		if ok, _, _ := procFindNextStreamW.Call(handle, uintptr(unsafe.Pointer(&data))); ok == 0 {
+			return streams
+		}
+	}
+}
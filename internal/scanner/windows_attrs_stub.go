@@ -9,3 +9,7 @@ func isHiddenWindows(path string) bool {
 func isSystemWindows(path string) bool {
 	return false
 }
+
+func reparseTypeWindows(path string) string {
+	return ""
+}
@@ -2,7 +2,11 @@
 
 package scanner
 
-import "golang.org/x/sys/windows"
+import (
+	"golang.org/x/sys/windows"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
 
 func isHiddenWindows(path string) bool {
 	attrs, err := windows.GetFileAttributes(windows.StringToUTF16Ptr(path))
@@ -19,3 +23,42 @@ func isSystemWindows(path string) bool {
 	}
 	return attrs&windows.FILE_ATTRIBUTE_SYSTEM != 0
 }
+
+// Reparse tags not defined in golang.org/x/sys/windows
+const (
+	ioReparseTagDedup     = 0x80000013
+	ioReparseTagCloud     = 0x9000001A
+	ioReparseTagCloudMask = 0x0000F000
+)
+
+// reparseTypeWindows classifies a reparse point by its tag, or returns ""
+// when the path is not a reparse point
+func reparseTypeWindows(path string) string {
+	pathPtr := windows.StringToUTF16Ptr(path)
+
+	attrs, err := windows.GetFileAttributes(pathPtr)
+	if err != nil || attrs&windows.FILE_ATTRIBUTE_REPARSE_POINT == 0 {
+		return ""
+	}
+
+	// The reparse tag is only exposed through the find data
+	var data windows.Win32finddata
+	handle, err := windows.FindFirstFile(pathPtr, &data)
+	if err != nil {
+		return models.ReparseOther
+	}
+	windows.FindClose(handle)
+
+	switch tag := data.Reserved0; {
+	case tag == windows.IO_REPARSE_TAG_SYMLINK:
+		return models.ReparseSymlink
+	case tag == windows.IO_REPARSE_TAG_MOUNT_POINT:
+		return models.ReparseMountPoint
+	case tag == ioReparseTagDedup:
+		return models.ReparseDedup
+	case tag&^ioReparseTagCloudMask == ioReparseTagCloud:
+		return models.ReparseCloud
+	default:
+		return models.ReparseOther
+	}
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -11,24 +12,38 @@ import (
 )
 
 // ConfigResult holds the values collected from the interactive setup.
+// Checks holds every check offered, by name, and whether it was selected.
 type ConfigResult struct {
 	Path        string
 	Destination string
 	Output      string
+	Checks      map[string]bool
 	Canceled    bool
 }
 
+// visibleChecks is how many rows of the check list are shown at once
+const visibleChecks = 10
+
+type checkOption struct {
+	name    string
+	enabled bool
+}
+
 type configModel struct {
-	inputs     []textinput.Model
-	focusIndex int
-	done       bool
-	canceled   bool
-	errMsg     string
-	width      int
+	inputs      []textinput.Model
+	checks      []checkOption
+	checkCursor int
+	focusIndex  int // len(inputs) when the check list has focus
+	done        bool
+	canceled    bool
+	errMsg      string
+	width       int
 }
 
-func RunConfigTUI(defaultPath, defaultDestination, defaultOutput string) (ConfigResult, error) {
-	model := newConfigModel(defaultPath, defaultDestination, defaultOutput)
+// RunConfigTUI asks for the scan path, destination and output folder, and
+// then which checks to run, starting from defaultChecks
+func RunConfigTUI(defaultPath, defaultDestination, defaultOutput string, defaultChecks map[string]bool) (ConfigResult, error) {
+	model := newConfigModel(defaultPath, defaultDestination, defaultOutput, defaultChecks)
 	program := tea.NewProgram(model, tea.WithAltScreen())
 	finalModel, err := program.Run()
 	if err != nil {
@@ -40,15 +55,21 @@ func RunConfigTUI(defaultPath, defaultDestination, defaultOutput string) (Config
 		return ConfigResult{}, fmt.Errorf("unexpected TUI model")
 	}
 
+	checks := make(map[string]bool, len(m.checks))
+	for _, check := range m.checks {
+		checks[check.name] = check.enabled
+	}
+
 	return ConfigResult{
 		Path:        strings.TrimSpace(m.inputs[0].Value()),
 		Destination: strings.TrimSpace(m.inputs[1].Value()),
 		Output:      strings.TrimSpace(m.inputs[2].Value()),
+		Checks:      checks,
 		Canceled:    m.canceled,
 	}, nil
 }
 
-func newConfigModel(defaultPath, defaultDestination, defaultOutput string) configModel {
+func newConfigModel(defaultPath, defaultDestination, defaultOutput string, defaultChecks map[string]bool) configModel {
 	inputs := make([]textinput.Model, 3)
 
 	pathInput := textinput.New()
@@ -71,8 +92,17 @@ func newConfigModel(defaultPath, defaultDestination, defaultOutput string) confi
 	inputs[1] = destinationInput
 	inputs[2] = outputInput
 
+	checks := make([]checkOption, 0, len(defaultChecks))
+	for name, enabled := range defaultChecks {
+		checks = append(checks, checkOption{name: name, enabled: enabled})
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].name < checks[j].name
+	})
+
 	m := configModel{
 		inputs:     inputs,
+		checks:     checks,
 		focusIndex: 0,
 		width:      80,
 	}
@@ -96,11 +126,24 @@ func (m configModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+c", "esc":
 			m.canceled = true
 			return m, tea.Quit
-		case "tab", "shift+tab", "up", "down":
+		case "tab", "shift+tab":
 			m.moveFocus(msg.String())
 			return m, nil
+		case "up", "down":
+			if m.onChecks() {
+				m.moveCheckCursor(msg.String())
+			} else {
+				m.moveFocus(msg.String())
+			}
+			return m, nil
+		case " ":
+			if m.onChecks() && len(m.checks) > 0 {
+				m.checks[m.checkCursor].enabled = !m.checks[m.checkCursor].enabled
+				m.errMsg = ""
+				return m, nil
+			}
 		case "enter":
-			if m.focusIndex < len(m.inputs)-1 {
+			if m.focusIndex < m.lastFocus() {
 				m.focusIndex++
 				m.errMsg = ""
 				m.applyFocus()
@@ -113,12 +156,20 @@ func (m configModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.applyFocus()
 				return m, nil
 			}
+			if !m.anyCheckEnabled() {
+				m.errMsg = "Select at least one check."
+				return m, nil
+			}
 
 			m.done = true
 			return m, tea.Quit
 		}
 	}
 
+	if m.onChecks() {
+		return m, nil
+	}
+
 	var cmd tea.Cmd
 	m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
 	return m, cmd
@@ -145,6 +196,10 @@ func (m configModel) View() string {
 	form.WriteString(m.inputs[1].View())
 	form.WriteString("\n")
 	form.WriteString(m.inputs[2].View())
+	if len(m.checks) > 0 {
+		form.WriteString("\n\n")
+		form.WriteString(m.checksView())
+	}
 
 	b.WriteString(boxStyle.Width(formWidth).Render(form.String()))
 
@@ -155,7 +210,11 @@ func (m configModel) View() string {
 	}
 
 	b.WriteString("\n\n")
-	b.WriteString(subtleStyle.Render("Tab to move, Enter to start, Ctrl+C to cancel"))
+	if m.onChecks() {
+		b.WriteString(subtleStyle.Render("Up/Down to choose, Space to toggle, Tab to move, Enter to start, Ctrl+C to cancel"))
+	} else {
+		b.WriteString(subtleStyle.Render("Tab to move, Enter to start, Ctrl+C to cancel"))
+	}
 
 	return b.String()
 }
@@ -168,11 +227,11 @@ func (m *configModel) moveFocus(key string) {
 		m.focusIndex++
 	}
 
-	if m.focusIndex > len(m.inputs)-1 {
+	if m.focusIndex > m.lastFocus() {
 		m.focusIndex = 0
 	}
 	if m.focusIndex < 0 {
-		m.focusIndex = len(m.inputs) - 1
+		m.focusIndex = m.lastFocus()
 	}
 
 	m.errMsg = ""
@@ -196,6 +255,98 @@ func (m *configModel) applyFocus() {
 	}
 }
 
+// lastFocus is the focus index of the check list, or of the last input
+// when there are no checks to offer
+func (m configModel) lastFocus() int {
+	if len(m.checks) == 0 {
+		return len(m.inputs) - 1
+	}
+	return len(m.inputs)
+}
+
+// onChecks reports whether the check list has focus
+func (m configModel) onChecks() bool {
+	return m.focusIndex == len(m.inputs)
+}
+
+func (m *configModel) moveCheckCursor(key string) {
+	if key == "up" {
+		m.checkCursor--
+	} else {
+		m.checkCursor++
+	}
+
+	if m.checkCursor > len(m.checks)-1 {
+		m.checkCursor = 0
+	}
+	if m.checkCursor < 0 {
+		m.checkCursor = len(m.checks) - 1
+	}
+}
+
+func (m configModel) anyCheckEnabled() bool {
+	for _, check := range m.checks {
+		if check.enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// checksView renders the check list, scrolled to keep the cursor in view
+func (m configModel) checksView() string {
+	focused := lipgloss.NewStyle().Foreground(accentColor)
+	blurred := lipgloss.NewStyle().Foreground(dimTextColor)
+
+	labelStyle := blurred
+	if m.onChecks() {
+		labelStyle = focused
+	}
+
+	enabled := 0
+	for _, check := range m.checks {
+		if check.enabled {
+			enabled++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(labelStyle.Render(fmt.Sprintf("Checks: %d of %d selected", enabled, len(m.checks))))
+
+	start := m.checkCursor - visibleChecks/2
+	if start > len(m.checks)-visibleChecks {
+		start = len(m.checks) - visibleChecks
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + visibleChecks
+	if end > len(m.checks) {
+		end = len(m.checks)
+	}
+
+	for i := start; i < end; i++ {
+		check := m.checks[i]
+		box := "[ ]"
+		if check.enabled {
+			box = "[x]"
+		}
+		cursor := "  "
+		style := blurred
+		if m.onChecks() && i == m.checkCursor {
+			cursor = "> "
+			style = focused
+		}
+		b.WriteString("\n" + style.Render(cursor+box+" "+check.name))
+	}
+
+	if start > 0 || end < len(m.checks) {
+		b.WriteString("\n" + blurred.Render(fmt.Sprintf("  (%d-%d of %d)", start+1, end, len(m.checks))))
+	}
+
+	return b.String()
+}
+
 func (m configModel) validate() string {
 	path := strings.TrimSpace(m.inputs[0].Value())
 	if path == "" {
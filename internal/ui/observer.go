@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// progressRenderInterval is how often the styled and TUI progress
+// displays are redrawn unless an observer sets Interval; updates in
+// between are dropped
+const progressRenderInterval = 500 * time.Millisecond
+
+// renderDue reports whether a display last drawn at last should be
+// redrawn, given an observer's Interval
+func renderDue(last time.Time, interval time.Duration) bool {
+	if interval <= 0 {
+		interval = progressRenderInterval
+	}
+	return time.Since(last) >= interval
+}
+
+// ConsoleObserver shows scan progress on the console. It implements
+// scan.ProgressObserver.
+type ConsoleObserver struct {
+	// Plain writes periodic log lines to stderr instead of redrawing a
+	// styled display, for output that is not a terminal
+	Plain     bool
+	StartTime time.Time
+	Interval  time.Duration // Minimum time between redraws of the styled display
+
+	lastRender time.Time
+}
+
+// OnProgress redraws the progress display
+func (o *ConsoleObserver) OnProgress(progress *models.ScanProgress) {
+	if o.Plain {
+		ShowPlainProgress(progress, o.StartTime)
+		return
+	}
+	if !renderDue(o.lastRender, o.Interval) {
+		return
+	}
+	o.lastRender = time.Now()
+	ShowStyledProgress(progress, o.StartTime)
+}
+
+// OnIssue does nothing; the display only shows the issue count
+func (o *ConsoleObserver) OnIssue(models.Issue) {}
+
+// OnDone clears the styled progress display
+func (o *ConsoleObserver) OnDone(*models.ScanResult) {
+	if !o.Plain {
+		ClearStyledProgress()
+	}
+}
+
+// TUIObserver sends scan progress to a program running a ScanModel. It
+// implements scan.ProgressObserver.
+type TUIObserver struct {
+	Program  *tea.Program
+	Interval time.Duration // Minimum time between updates sent to Program
+
+	lastRender time.Time
+}
+
+// OnProgress sends the update to the program
+func (o *TUIObserver) OnProgress(progress *models.ScanProgress) {
+	if !renderDue(o.lastRender, o.Interval) {
+		return
+	}
+	o.lastRender = time.Now()
+	o.Program.Send(ProgressMsg(progress))
+}
+
+// OnIssue does nothing; the display only shows the issue count
+func (o *TUIObserver) OnIssue(models.Issue) {}
+
+// OnDone does nothing. The caller ends the program with DoneMsg or
+// ErrorMsg once it knows whether the scan failed.
+func (o *TUIObserver) OnDone(*models.ScanResult) {}
@@ -2,6 +2,8 @@ package ui
 
 import (
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,6 +13,12 @@ import (
 
 var lastLineCount = 0
 
+// plainProgressInterval is how often a log line is written when stdout
+// is not a terminal.
+const plainProgressInterval = 10 * time.Second
+
+var lastPlainProgress time.Time
+
 // ShowStyledProgress displays scan progress with the custom UI theme.
 func ShowStyledProgress(progress *models.ScanProgress, startTime time.Time) {
 	// Clear previous lines
@@ -47,7 +55,7 @@ func ShowStyledProgress(progress *models.ScanProgress, startTime time.Time) {
 
 	// Current path
 	if progress.CurrentPath != "" {
-		currentPath := progress.CurrentPath
+		currentPath := printablePath(progress.CurrentPath)
 		maxLen := 70
 		if len(currentPath) > maxLen {
 			currentPath = "..." + currentPath[len(currentPath)-maxLen+3:]
@@ -68,6 +76,23 @@ func ShowStyledProgress(progress *models.ScanProgress, startTime time.Time) {
 	lastLineCount = strings.Count(output, "\n")
 }
 
+// ShowPlainProgress writes a single progress line to stderr without any
+// cursor control sequences, for redirected output and CI logs. Calls are
+// throttled to one line per plainProgressInterval.
+func ShowPlainProgress(progress *models.ScanProgress, startTime time.Time) {
+	now := time.Now()
+	if now.Sub(lastPlainProgress) < plainProgressInterval {
+		return
+	}
+	lastPlainProgress = now
+
+	fmt.Fprintf(os.Stderr, "[%s] scanned %s items, %s, %s issues\n",
+		formatDuration(now.Sub(startTime)),
+		formatNumber(progress.ItemsScanned),
+		formatBytes(progress.BytesScanned),
+		formatNumber(int64(progress.IssuesFound)))
+}
+
 // ClearStyledProgress clears the progress display
 func ClearStyledProgress() {
 	if lastLineCount > 0 {
@@ -84,25 +109,25 @@ func renderProgressStats(stats *models.ScanProgress, elapsed time.Duration, rate
 	// Row 1: Basic counts
 	b.WriteString(
 		statLabelStyle.Render("Items:") + "   " +
-		statValueStyle.Render(formatNumber(stats.ItemsScanned)) + "  " +
-		subtleStyle.Render("│") + "  " +
-		statLabelStyle.Render("Files:") + "   " +
-		statValueStyle.Render(formatNumber(stats.FilesScanned)) + "  " +
-		subtleStyle.Render("│") + "  " +
-		statLabelStyle.Render("Folders:") + " " +
-		statValueStyle.Render(formatNumber(stats.DirsScanned)) + "\n",
+			statValueStyle.Render(formatNumber(stats.ItemsScanned)) + "  " +
+			subtleStyle.Render("│") + "  " +
+			statLabelStyle.Render("Files:") + "   " +
+			statValueStyle.Render(formatNumber(stats.FilesScanned)) + "  " +
+			subtleStyle.Render("│") + "  " +
+			statLabelStyle.Render("Folders:") + " " +
+			statValueStyle.Render(formatNumber(stats.DirsScanned)) + "\n",
 	)
 
 	// Row 2: Size and performance
 	b.WriteString(
 		statLabelStyle.Render("Size:") + "    " +
-		statValueStyle.Render(formatBytes(stats.BytesScanned)) + "  " +
-		subtleStyle.Render("│") + "  " +
-		statLabelStyle.Render("Rate:") + "    " +
-		statValueStyle.Render(fmt.Sprintf("%s/s", formatNumber(int64(rate)))) + "  " +
-		subtleStyle.Render("│") + "  " +
-		statLabelStyle.Render("Elapsed:") + " " +
-		statValueStyle.Render(formatDuration(elapsed)),
+			statValueStyle.Render(formatBytes(stats.BytesScanned)) + "  " +
+			subtleStyle.Render("│") + "  " +
+			statLabelStyle.Render("Rate:") + "    " +
+			statValueStyle.Render(fmt.Sprintf("%s/s", formatNumber(int64(rate)))) + "  " +
+			subtleStyle.Render("│") + "  " +
+			statLabelStyle.Render("Elapsed:") + " " +
+			statValueStyle.Render(formatDuration(elapsed)),
 	)
 
 	// Row 3: Issues (if any)
@@ -110,13 +135,47 @@ func renderProgressStats(stats *models.ScanProgress, elapsed time.Duration, rate
 		b.WriteString("\n")
 		b.WriteString(
 			statLabelStyle.Render("Issues:") + "  " +
-			warningStyle.Render(formatNumber(int64(stats.IssuesFound))),
+				warningStyle.Render(formatNumber(int64(stats.IssuesFound))),
 		)
+		if breakdown := issueTypeBreakdown(stats.IssuesByType, progressBreakdownTypes); breakdown != "" {
+			b.WriteString("  " + subtleStyle.Render(breakdown))
+		}
 	}
 
 	return b.String()
 }
 
+// progressBreakdownTypes is the number of issue types named in the live
+// breakdown; the rest are counted as "+N more"
+const progressBreakdownTypes = 3
+
+// issueTypeBreakdown lists the most common issue types with their counts,
+// such as "PathLength 812 · InvalidCharacters 97 · +2 more"
+func issueTypeBreakdown(byType map[models.IssueType]int, limit int) string {
+	types := make([]models.IssueType, 0, len(byType))
+	for issueType, count := range byType {
+		if count > 0 {
+			types = append(types, issueType)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if byType[types[i]] != byType[types[j]] {
+			return byType[types[i]] > byType[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	var parts []string
+	for i, issueType := range types {
+		if i == limit {
+			parts = append(parts, fmt.Sprintf("+%d more", len(types)-limit))
+			break
+		}
+		parts = append(parts, string(issueType)+" "+formatNumber(int64(byType[issueType])))
+	}
+	return strings.Join(parts, " · ")
+}
+
 func renderProgressBar(itemsScanned int64, elapsed time.Duration) string {
 	// Animated indeterminate progress bar
 	width := 50
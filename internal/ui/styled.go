@@ -15,100 +15,100 @@ import (
 // Color scheme for the scanner UI.
 var (
 	// Primary colors
-	primaryColor   = lipgloss.Color("#FF6B35") // Coral orange
-	accentColor    = lipgloss.Color("#4ECDC4") // Teal
-	successColor   = lipgloss.Color("#95E1D3") // Mint green
-	warningColor   = lipgloss.Color("#FFD93D") // Yellow
-	errorColor     = lipgloss.Color("#F38181") // Soft red
-	infoColor      = lipgloss.Color("#6C5CE7") // Purple
+	primaryColor = lipgloss.Color("#FF6B35") // Coral orange
+	accentColor  = lipgloss.Color("#4ECDC4") // Teal
+	successColor = lipgloss.Color("#95E1D3") // Mint green
+	warningColor = lipgloss.Color("#FFD93D") // Yellow
+	errorColor   = lipgloss.Color("#F38181") // Soft red
+	infoColor    = lipgloss.Color("#6C5CE7") // Purple
 
 	// UI colors
-	subtleColor    = lipgloss.Color("#6B7280") // Gray
-	borderColor    = lipgloss.Color("#374151") // Dark gray
-	bgColor        = lipgloss.Color("#1F2937") // Very dark gray
+	subtleColor = lipgloss.Color("#6B7280") // Gray
+	borderColor = lipgloss.Color("#374151") // Dark gray
+	bgColor     = lipgloss.Color("#1F2937") // Very dark gray
 
 	// Text colors
-	textColor      = lipgloss.Color("#F9FAFB") // Almost white
-	dimTextColor   = lipgloss.Color("#9CA3AF") // Light gray
+	textColor    = lipgloss.Color("#F9FAFB") // Almost white
+	dimTextColor = lipgloss.Color("#9CA3AF") // Light gray
 )
 
 // Styles
 var (
 	titleStyle = lipgloss.NewStyle().
-		Foreground(primaryColor).
-		Bold(true).
-		PaddingTop(1).
-		PaddingBottom(1)
+			Foreground(primaryColor).
+			Bold(true).
+			PaddingTop(1).
+			PaddingBottom(1)
 
 	bannerStyle = lipgloss.NewStyle().
-		Foreground(accentColor).
-		Bold(true).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(borderColor).
-		Padding(1, 2).
-		Margin(1, 0)
+			Foreground(accentColor).
+			Bold(true).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(borderColor).
+			Padding(1, 2).
+			Margin(1, 0)
 
 	boxStyle = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(borderColor).
-		Padding(1, 2).
-		Margin(1, 0)
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(borderColor).
+			Padding(1, 2).
+			Margin(1, 0)
 
 	statStyle = lipgloss.NewStyle().
-		Foreground(textColor).
-		PaddingRight(2)
+			Foreground(textColor).
+			PaddingRight(2)
 
 	statLabelStyle = lipgloss.NewStyle().
-		Foreground(dimTextColor).
-		PaddingRight(1)
+			Foreground(dimTextColor).
+			PaddingRight(1)
 
 	statValueStyle = lipgloss.NewStyle().
-		Foreground(accentColor).
-		Bold(true)
+			Foreground(accentColor).
+			Bold(true)
 
 	pathStyle = lipgloss.NewStyle().
-		Foreground(dimTextColor).
-		Italic(true).
-		MaxWidth(80)
+			Foreground(dimTextColor).
+			Italic(true).
+			MaxWidth(80)
 
 	criticalStyle = lipgloss.NewStyle().
-		Foreground(errorColor).
-		Bold(true)
+			Foreground(errorColor).
+			Bold(true)
 
 	warningStyle = lipgloss.NewStyle().
-		Foreground(warningColor).
-		Bold(true)
+			Foreground(warningColor).
+			Bold(true)
 
 	infoStyle = lipgloss.NewStyle().
-		Foreground(infoColor).
-		Bold(true)
+			Foreground(infoColor).
+			Bold(true)
 
 	successStyle = lipgloss.NewStyle().
-		Foreground(successColor).
-		Bold(true)
+			Foreground(successColor).
+			Bold(true)
 
 	subtleStyle = lipgloss.NewStyle().
-		Foreground(subtleColor)
+			Foreground(subtleColor)
 
 	headerStyle = lipgloss.NewStyle().
-		Foreground(primaryColor).
-		Bold(true).
-		Underline(true).
-		PaddingBottom(1)
+			Foreground(primaryColor).
+			Bold(true).
+			Underline(true).
+			PaddingBottom(1)
 )
 
 // ScanModel is the bubbletea model for the scan progress
 type ScanModel struct {
-	progress      progress.Model
-	spinner       spinner.Model
-	scanPath      string
-	destURL       string
-	startTime     time.Time
-	currentStats  *models.ScanProgress
-	done          bool
-	err           error
-	width         int
-	height        int
+	progress     progress.Model
+	spinner      spinner.Model
+	scanPath     string
+	destURL      string
+	startTime    time.Time
+	currentStats *models.ScanProgress
+	done         bool
+	err          error
+	width        int
+	height       int
 }
 
 // NewScanModel creates a new scan progress model
@@ -197,7 +197,7 @@ func (m ScanModel) renderProgress() string {
 
 	// Scan path info
 	pathBox := boxStyle.Width(m.width - 4).Render(
-		statLabelStyle.Render("Path:") + " " + pathStyle.Render(m.scanPath) + "\n" +
+		statLabelStyle.Render("Path:") + " " + pathStyle.Render(printablePath(m.scanPath)) + "\n" +
 			statLabelStyle.Render("Destination:") + " " + pathStyle.Render(m.destURL),
 	)
 	b.WriteString(pathBox)
@@ -220,7 +220,7 @@ func (m ScanModel) renderProgress() string {
 
 		// Current path being scanned
 		if m.currentStats.CurrentPath != "" {
-			currentPath := m.currentStats.CurrentPath
+			currentPath := printablePath(m.currentStats.CurrentPath)
 			if len(currentPath) > 80 {
 				currentPath = "..." + currentPath[len(currentPath)-77:]
 			}
@@ -242,15 +242,15 @@ func (m ScanModel) renderStatsGrid(stats *models.ScanProgress, elapsed time.Dura
 	// Row 1: Items and Files
 	b.WriteString(
 		statLabelStyle.Render("Items:") + " " + statValueStyle.Render(formatNumber(stats.ItemsScanned)) + "    " +
-		statLabelStyle.Render("Files:") + " " + statValueStyle.Render(formatNumber(stats.FilesScanned)) + "    " +
-		statLabelStyle.Render("Folders:") + " " + statValueStyle.Render(formatNumber(stats.DirsScanned)) + "\n",
+			statLabelStyle.Render("Files:") + " " + statValueStyle.Render(formatNumber(stats.FilesScanned)) + "    " +
+			statLabelStyle.Render("Folders:") + " " + statValueStyle.Render(formatNumber(stats.DirsScanned)) + "\n",
 	)
 
 	// Row 2: Size and Rate
 	b.WriteString(
 		statLabelStyle.Render("Size:") + " " + statValueStyle.Render(formatBytes(stats.BytesScanned)) + "    " +
-		statLabelStyle.Render("Rate:") + " " + statValueStyle.Render(fmt.Sprintf("%s/sec", formatNumber(int64(rate)))) + "    " +
-		statLabelStyle.Render("Time:") + " " + statValueStyle.Render(formatDuration(elapsed)),
+			statLabelStyle.Render("Rate:") + " " + statValueStyle.Render(fmt.Sprintf("%s/sec", formatNumber(int64(rate)))) + "    " +
+			statLabelStyle.Render("Time:") + " " + statValueStyle.Render(formatDuration(elapsed)),
 	)
 
 	// Row 3: Issues
@@ -259,6 +259,9 @@ func (m ScanModel) renderStatsGrid(stats *models.ScanProgress, elapsed time.Dura
 		b.WriteString(
 			statLabelStyle.Render("Issues:") + " " + warningStyle.Render(formatNumber(int64(stats.IssuesFound))),
 		)
+		if breakdown := issueTypeBreakdown(stats.IssuesByType, progressBreakdownTypes); breakdown != "" {
+			b.WriteString("  " + subtleStyle.Render(breakdown))
+		}
 	}
 
 	return b.String()
@@ -299,8 +302,8 @@ func ShowStyledBanner() {
 
 	styledBanner := bannerStyle.Render(
 		lipgloss.NewStyle().Foreground(accentColor).Render(banner) + "\n\n" +
-		lipgloss.NewStyle().Foreground(textColor).Render("SharePoint Online Migration Readiness Scanner") + "\n" +
-		subtleStyle.Render("Built for Speed & Scale • Go Edition v2.0"),
+			lipgloss.NewStyle().Foreground(textColor).Render("SharePoint Online Migration Readiness Scanner") + "\n" +
+			subtleStyle.Render("Built for Speed & Scale • Go Edition v2.0"),
 	)
 
 	fmt.Println(styledBanner)
@@ -319,6 +322,23 @@ func ShowStyledSummary(result *models.ScanResult) {
 	fmt.Println(bannerStyle.Render(headerStyle.Render(header)))
 	fmt.Println()
 
+	if result.Truncated {
+		fmt.Println(warningStyle.Render(fmt.Sprintf("⚠ Results truncated at %s items (-max-items); this is not a complete scan", formatNumber(result.ItemLimit))))
+		fmt.Println()
+	}
+
+	if n := len(result.PotentialSecrets); n > 0 {
+		fmt.Println(criticalStyle.Render(fmt.Sprintf("⚠ %s potential secrets found; review them in the Potential Secrets section of the report", formatNumber(int64(n)))))
+		fmt.Println()
+	}
+
+	if len(result.RootIssues) > 0 {
+		for _, issue := range result.RootIssues {
+			fmt.Println(warningStyle.Render("⚠ " + rootIssueText(issue)))
+		}
+		fmt.Println()
+	}
+
 	// Stats section
 	statsBox := renderStatsBox(result)
 	fmt.Println(boxStyle.Width(80).Render(statsBox))
@@ -349,7 +369,10 @@ func renderStatsBox(result *models.ScanResult) string {
 	b.WriteString("\n\n")
 
 	// Path
-	b.WriteString(statLabelStyle.Render("Path:") + "         " + lipgloss.NewStyle().Foreground(textColor).Render(result.ScanPath) + "\n")
+	b.WriteString(statLabelStyle.Render("Path:") + "         " + lipgloss.NewStyle().Foreground(textColor).Render(printablePath(result.ScanPath)) + "\n")
+
+	// Readiness score
+	b.WriteString(statLabelStyle.Render("Readiness:") + "    " + renderReadinessGauge(result.ReadinessScore) + "\n")
 
 	// Duration
 	b.WriteString(statLabelStyle.Render("Duration:") + "     " + statValueStyle.Render(formatDuration(result.Duration)) + "\n")
@@ -362,15 +385,59 @@ func renderStatsBox(result *models.ScanResult) string {
 	b.WriteString(statLabelStyle.Render("Items:") + "        " + lipgloss.NewStyle().Foreground(textColor).Render(itemsText) + "\n")
 
 	// Size
-	b.WriteString(statLabelStyle.Render("Total Size:") + "   " + statValueStyle.Render(formatBytes(result.TotalSize)) + "\n")
+	b.WriteString(statLabelStyle.Render("Total Size:") + "   " + statValueStyle.Render(totalSizeText(result)) + "\n")
 
 	// Rate
 	rate := float64(result.TotalItems) / result.Duration.Seconds()
 	b.WriteString(statLabelStyle.Render("Scan Rate:") + "    " + statValueStyle.Render(fmt.Sprintf("%s items/sec", formatNumber(int64(rate)))))
 
+	// Unreadable paths
+	if len(result.Errors) > 0 {
+		b.WriteString("\n" + statLabelStyle.Render("Errors:") + "       " +
+			criticalStyle.Render(fmt.Sprintf("%s paths could not be read (see report)", formatNumber(int64(len(result.Errors))))))
+	}
+
+	// Problematic-file categories accepted for this run
+	if len(result.AcceptedCategories) > 0 {
+		b.WriteString("\n" + statLabelStyle.Render("Accepted:") + "     " +
+			subtleStyle.Render(strings.Join(result.AcceptedCategories, ", ")+" (not reported)"))
+	}
+
+	// Issues dropped by the ignore file
+	if result.Suppressed > 0 {
+		b.WriteString("\n" + statLabelStyle.Render("Suppressed:") + "   " +
+			subtleStyle.Render(fmt.Sprintf("%s issues matched the ignore file", formatNumber(int64(result.Suppressed)))))
+	}
+
+	// Issues dropped from memory under -max-memory
+	if result.Spilled > 0 {
+		b.WriteString("\n" + statLabelStyle.Render("Streamed only:") + "   " +
+			subtleStyle.Render(fmt.Sprintf("%s issues are only in the streamed CSV (-max-memory)", formatNumber(int64(result.Spilled)))))
+	}
+
 	return b.String()
 }
 
+// renderReadinessGauge draws the readiness score as a bar colored green,
+// yellow or red
+func renderReadinessGauge(score int) string {
+	style := readinessStyle(score)
+	filled := score / 5
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", 20-filled)
+	return style.Render(bar) + " " + style.Render(fmt.Sprintf("%d/100", score))
+}
+
+func readinessStyle(score int) lipgloss.Style {
+	switch {
+	case score >= 80:
+		return successStyle
+	case score >= 50:
+		return warningStyle
+	default:
+		return criticalStyle
+	}
+}
+
 func renderIssuesBox(result *models.ScanResult) string {
 	var b strings.Builder
 
@@ -399,6 +466,11 @@ func renderIssuesBox(result *models.ScanResult) string {
 			subtleStyle.Render("  (review recommended)"))
 	}
 
+	if skipped := result.Summary.AutoSkipped; skipped > 0 {
+		return strings.TrimRight(b.String(), "\n") + "\n\n" +
+			subtleStyle.Render(fmt.Sprintf("%s of these will be skipped automatically by migration tools", formatNumber(int64(skipped))))
+	}
+
 	return b.String()
 }
 
@@ -408,26 +480,13 @@ func renderIssueTypesBox(result *models.ScanResult) string {
 	b.WriteString(headerStyle.Render("Issue Types Breakdown"))
 	b.WriteString("\n\n")
 
-	// Sort issue types for consistent display
-	types := []models.IssueType{
-		models.IssuePathLength,
-		models.IssueInvalidCharacters,
-		models.IssueReservedName,
-		models.IssueBlockedFileType,
-		models.IssueProblematicFile,
-		models.IssueFileSize,
-		models.IssueNameConflict,
-		models.IssueHiddenFile,
-		models.IssueSystemFile,
-	}
-
-	for _, issueType := range types {
+	for _, issueType := range models.IssueTypes {
 		if count, exists := result.Summary.ByType[issueType]; exists && count > 0 {
 			icon := getIssueIcon(issueType)
 			typeName := string(issueType)
 
 			// Pad type name for alignment
-			padding := strings.Repeat(" ", 22 - len(typeName))
+			padding := strings.Repeat(" ", 22-len(typeName))
 
 			b.WriteString(lipgloss.NewStyle().Foreground(accentColor).Render(icon) + " " +
 				lipgloss.NewStyle().Foreground(textColor).Render(typeName) + padding +
@@ -496,6 +555,22 @@ func getIssueIcon(issueType models.IssueType) string {
 		return "·"
 	case models.IssueSystemFile:
 		return "*"
+	case models.IssueCustomRule:
+		return "#"
+	case models.IssueCaseConflict:
+		return "≈"
+	case models.IssueFileLocked:
+		return "@"
+	case models.IssueReparsePoint:
+		return "&"
+	case models.IssueExtensionMismatch:
+		return "≠"
+	case models.IssueAlternateStream:
+		return ":"
+	case models.IssueEmptyFolder:
+		return "○"
+	case models.IssueConfusableName:
+		return "≋"
 	default:
 		return "•"
 	}
@@ -533,6 +608,15 @@ func formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// totalSizeText formats the total size of a scan, which is not measured
+// when the walk skipped reading sizes
+func totalSizeText(result *models.ScanResult) string {
+	if result.SizesSkipped {
+		return "not measured"
+	}
+	return formatBytes(result.TotalSize)
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Second {
 		return fmt.Sprintf("%dms", d.Milliseconds())
@@ -46,7 +46,7 @@ func ShowProgress(progress *models.ScanProgress, startTime time.Time) {
 	bar := strings.Repeat("█", barWidth)
 
 	// Truncate path if too long
-	currentPath := progress.CurrentPath
+	currentPath := printablePath(progress.CurrentPath)
 	maxPathLen := 60
 	if len(currentPath) > maxPathLen {
 		currentPath = "..." + currentPath[len(currentPath)-maxPathLen+3:]
@@ -70,6 +70,15 @@ func ClearProgress() {
 	fmt.Print("\r\033[K\033[1B\033[K\033[1B\033[K\033[1B\033[K\033[1B\033[K\033[1B\033[K")
 }
 
+// rootIssueText describes a problem with the scan root's own name
+func rootIssueText(issue models.Issue) string {
+	text := "Scan root name: " + issue.Message
+	if issue.Details != "" {
+		text += " (" + issue.Details + ")"
+	}
+	return text + "; rename it before naming the destination library or folder after it"
+}
+
 // ShowSummary displays the scan summary
 func ShowSummary(result *models.ScanResult) {
 	fmt.Println("\n╔═══════════════════════════════════════════════════════════════╗")
@@ -77,16 +86,39 @@ func ShowSummary(result *models.ScanResult) {
 	fmt.Println("╚═══════════════════════════════════════════════════════════════╝")
 	fmt.Println()
 
+	if result.Truncated {
+		fmt.Printf("⚠️  Results truncated at %s items (-max-items); this is not a complete scan\n\n", formatNumber(result.ItemLimit))
+	}
+	if n := len(result.PotentialSecrets); n > 0 {
+		fmt.Printf("🔑 %s potential secrets found; review them in the Potential Secrets section of the report\n\n", formatNumber(int64(n)))
+	}
+	if len(result.RootIssues) > 0 {
+		for _, issue := range result.RootIssues {
+			fmt.Printf("⚠️  %s\n", rootIssueText(issue))
+		}
+		fmt.Println()
+	}
+
 	// Scan statistics
-	fmt.Printf("📁 Scan Path:      %s\n", result.ScanPath)
+	fmt.Printf("📁 Scan Path:      %s\n", printablePath(result.ScanPath))
+	fmt.Printf("🎯 Readiness:      %d/100\n", result.ReadinessScore)
 	fmt.Printf("⏱️  Duration:       %s\n", formatDuration(result.Duration))
 	fmt.Printf("📊 Total Items:    %s (%s files, %s folders)\n",
 		formatNumber(result.TotalItems),
 		formatNumber(result.TotalFiles),
 		formatNumber(result.TotalFolders))
-	fmt.Printf("💾 Total Size:     %s\n", formatBytes(result.TotalSize))
+	fmt.Printf("💾 Total Size:     %s\n", totalSizeText(result))
 	fmt.Printf("⚡ Scan Rate:      %s items/sec\n",
 		formatNumber(int64(float64(result.TotalItems)/result.Duration.Seconds())))
+	if len(result.AcceptedCategories) > 0 {
+		fmt.Printf("✔️  Accepted:       %s (warnings and info not reported)\n", strings.Join(result.AcceptedCategories, ", "))
+	}
+	if result.Suppressed > 0 {
+		fmt.Printf("🙈 Suppressed:     %s issues matched the ignore file\n", formatNumber(int64(result.Suppressed)))
+	}
+	if result.Spilled > 0 {
+		fmt.Printf("💽 Streamed only:  %s issues are only in the streamed CSV (-max-memory)\n", formatNumber(int64(result.Spilled)))
+	}
 	fmt.Println()
 
 	// Issues summary
@@ -113,6 +145,9 @@ func ShowSummary(result *models.ScanResult) {
 	if info > 0 {
 		fmt.Printf("  🔵 Info:      %s (review recommended)\n", formatNumber(int64(info)))
 	}
+	if skipped := result.Summary.AutoSkipped; skipped > 0 {
+		fmt.Printf("  %s of these will be skipped automatically by migration tools\n", formatNumber(int64(skipped)))
+	}
 	fmt.Println()
 
 	// By type
@@ -148,6 +183,14 @@ func ShowWarning(msg string) {
 	fmt.Printf("\n[WARN]  %s\n", msg)
 }
 
+// ShowRootIssues warns about problems with the scan root's own name
+// before the scan starts
+func ShowRootIssues(issues []models.Issue) {
+	for _, issue := range issues {
+		ShowWarning(rootIssueText(issue))
+	}
+}
+
 // ShowInfo displays an info message
 func ShowInfo(msg string) {
 	fmt.Printf("\n[INFO]  %s\n", msg)
@@ -157,3 +200,18 @@ func ShowInfo(msg string) {
 func ShowSuccess(msg string) {
 	fmt.Printf("\n[OK]    %s\n", msg)
 }
+
+// printablePath replaces control characters such as newlines in a path
+// with their Unicode control pictures (U+2400 block) so a file name cannot
+// break or rewrite the terminal display
+func printablePath(path string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20:
+			return 0x2400 + r
+		case r == 0x7F:
+			return 0x2421
+		}
+		return r
+	}, path)
+}
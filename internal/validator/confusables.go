@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"strings"
+	"unicode"
+)
+
+// confusables maps letters from other scripts to the Latin letters they
+// are drawn like in common fonts. It is a small subset of the Unicode
+// confusables data (UTS #39) covering the Cyrillic, Greek and Armenian
+// lookalikes seen in real file names; fullwidth forms are handled in
+// confusableRune. Only non-ASCII letters are mapped, so names written
+// entirely in ASCII are never confusable with each other.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j',
+	'ӏ': 'l', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's', 'ԝ': 'w', 'х': 'x',
+	'у': 'y', 'ѵ': 'v',
+	'А': 'A', 'В': 'B', 'С': 'C', 'Е': 'E', 'Н': 'H', 'І': 'I', 'Ј': 'J',
+	'К': 'K', 'М': 'M', 'О': 'O', 'Р': 'P', 'Ѕ': 'S', 'Т': 'T', 'Х': 'X',
+	'Ү': 'Y', 'Ԛ': 'Q', 'Ԝ': 'W', 'Ӏ': 'I',
+
+	// Greek
+	'α': 'a', 'ι': 'i', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'υ': 'u',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K',
+	'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+
+	// Armenian
+	'օ': 'o', 'ս': 'u', 'հ': 'h', 'ո': 'n',
+
+	// Latin lookalikes
+	'ı': 'i', 'ȷ': 'j', 'ℓ': 'l',
+}
+
+// confusableRune returns the ASCII character r is drawn like, and whether
+// it is a lookalike at all
+func confusableRune(r rune) (rune, bool) {
+	if mapped, ok := confusables[r]; ok {
+		return mapped, true
+	}
+	// Fullwidth forms of the printable ASCII characters
+	if r >= 0xFF01 && r <= 0xFF5E {
+		return r - 0xFF01 + '!', true
+	}
+	return r, false
+}
+
+// confusableSkeleton reduces a name to the form two visually confusable
+// names share: lookalikes are replaced with the letters they resemble and
+// the result is lower-cased, since SharePoint ignores case anyway
+func confusableSkeleton(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		mapped, _ := confusableRune(r)
+		b.WriteRune(unicode.ToLower(mapped))
+	}
+	return b.String()
+}
+
+// lookalikes lists the lookalike characters in name as "а U+0430"
+func lookalikes(name string, seen map[rune]bool) []string {
+	var found []string
+	for _, r := range name {
+		if _, ok := confusableRune(r); !ok || seen[r] {
+			continue
+		}
+		seen[r] = true
+		found = append(found, string(r)+" "+formatCodePoint(r))
+	}
+	return found
+}
@@ -0,0 +1,202 @@
+package validator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// Explanation describes the rules behind an issue type or a file extension
+type Explanation struct {
+	Topic       string
+	Description string
+	Rules       []RuleExplanation
+}
+
+// RuleExplanation is one configured rule, shown as the issue it produces
+type RuleExplanation struct {
+	Issue      models.Issue
+	Condition  string   // When the rule applies, if not always
+	Extensions []string // Extensions the rule covers, for issue-type lookups
+}
+
+// issueTypeDescriptions explains what each check looks for
+var issueTypeDescriptions = map[models.IssueType]string{
+	models.IssuePathLength:        "The decoded server-relative path (site, library, folders and name) must stay within the SharePoint path limit, and each name within the name limit. Paths close to the limit are reported as warnings because renames or moves can push them over.",
+	models.IssueInvalidCharacters: "Names must not contain characters SharePoint rejects, invisible or zero-width characters, blocked patterns such as _vti_, or sync-breaking prefixes such as ~ for folders and .. at the start of a name.",
+	models.IssueReservedName:      "Device names reserved by Windows (CON, PRN, AUX, NUL, COM0-9, LPT0-9) cannot be used for files or folders, even with an extension. .lock and desktop.ini are blocked as whole names, and forms is reserved for folders at the library root.",
+	models.IssueBlockedFileType:   "File types that SharePoint administrators commonly block, or that were blocked for this scan with -block-ext, and names that hide an executable extension before the last one (report.exe.txt).",
+	models.IssueProblematicFile:   "File types that upload but cause trouble after migration, such as broken links, missing locking or no browser preview.",
+	models.IssueFileSize:          "Files over the SharePoint upload limit fail to migrate; large files are slow to sync.",
+	models.IssueNameConflict:      "Items whose names collide once SharePoint's naming rules are applied, including Windows 8.3 short names (-check-short-names).",
+	models.IssueHiddenFile:        "Hidden files and folders are usually not needed in SharePoint.",
+	models.IssueSystemFile:        "System files typically should not be migrated.",
+	models.IssueCustomRule:        "Names matching a customRules pattern from the config file.",
+	models.IssueCaseConflict:      "SharePoint is case-insensitive, so paths that differ only by letter case collide (-detect-case-conflicts).",
+	models.IssueFileLocked:        "Files open in another process at scan time fail to copy or produce conflicts (-check-locks).",
+	models.IssueReparsePoint:      "Symbolic links, mount points, deduplicated files and cloud placeholders do not migrate as ordinary files.",
+	models.IssueExtensionMismatch: "Files whose content (sniffed from the first bytes with -sniff) does not match their extension escape the checks for blocked and problematic types.",
+	models.IssueAlternateStream:   "NTFS alternate data streams, such as the Zone.Identifier mark of the web on downloaded files, are dropped on upload (-check-streams).",
+	models.IssueConfusableName:    "Names in the same folder that look the same but use lookalike letters from another script, such as a Cyrillic \"а\" for a Latin \"a\". Advisory; the names are valid (-detect-confusables).",
+	models.IssueEmptyFolder:       "Empty folders are often leftover structure, and some migration tools do not create them. Folders that only hold excluded or filtered items are reported separately.",
+}
+
+// Explain looks up an issue type name (case-insensitive) or a file
+// extension such as ".pst" and describes the configured rules behind it.
+// It reports false when key matches neither.
+func (v *Validator) Explain(key string) (Explanation, bool) {
+	for issueType, description := range issueTypeDescriptions {
+		if strings.EqualFold(string(issueType), key) {
+			return Explanation{
+				Topic:       string(issueType),
+				Description: description,
+				Rules:       v.explainIssueType(issueType),
+			}, true
+		}
+	}
+
+	ext := config.NormalizeExtension(key)
+	if ext == "" {
+		return Explanation{}, false
+	}
+
+	rules := v.explainExtension(ext)
+	if len(rules) == 0 {
+		return Explanation{}, false
+	}
+	return Explanation{Topic: ext, Rules: rules}, true
+}
+
+// explainExtension runs the file-type checks on a sample file of each
+// size class, so the explanation always matches what a scan reports
+func (v *Validator) explainExtension(ext string) []RuleExplanation {
+	check := func(size int64) []models.Issue {
+		item := &models.FileSystemItem{Path: "example" + ext, Name: "example" + ext, Size: size}
+		issues := v.checkBlockedFileTypes(item, ext)
+		issues = append(issues, v.checkProblematicFiles(item, ext)...)
+		return append(issues, v.checkSecrets(item)...)
+	}
+
+	// Below settings.problematicMinSizeBytes only secrets are reported
+	floor := v.config.Settings.ProblematicMinSizeBytes
+	small := check(1)
+	if floor > 0 {
+		small = append(small, check(floor+1)...)
+	}
+	large := check(1 << 50)
+
+	var rules []RuleExplanation
+	for i, issue := range small {
+		if i > 0 && containsIssue(small[:i], issue) {
+			continue
+		}
+		rule := RuleExplanation{Issue: withoutItem(issue)}
+		if floor > 0 && issue.Type == models.IssueProblematicFile && issue.Category != models.CategorySecrets {
+			rule.Condition = "Files larger than " + formatSize(floor)
+		}
+		rules = append(rules, rule)
+	}
+
+	// Rules that only apply, or are more severe, above a size threshold
+	for _, issue := range large {
+		if containsIssue(small, issue) {
+			continue
+		}
+		condition := "Large files only"
+		if threshold := v.sizeThreshold(issue.Category, ext); threshold > 0 {
+			condition = "Files larger than " + formatSize(threshold)
+		}
+		rules = append(rules, RuleExplanation{Issue: withoutItem(issue), Condition: condition})
+	}
+
+	return rules
+}
+
+// explainIssueType lists each file-type category that reports the type
+func (v *Validator) explainIssueType(issueType models.IssueType) []RuleExplanation {
+	var groups [][]string
+	switch issueType {
+	case models.IssueBlockedFileType:
+		b := v.config.BlockedFileTypes
+		groups = [][]string{
+			setKeys(b.Custom.ExtensionsSet),
+			setKeys(b.Executables.ExtensionsSet),
+			setKeys(b.Scripts.ExtensionsSet),
+			setKeys(b.System.ExtensionsSet),
+			setKeys(b.Dangerous.ExtensionsSet),
+		}
+	case models.IssueProblematicFile:
+		p := v.config.ProblematicFiles
+		groups = [][]string{
+			setKeys(p.CAD.ExtensionsSet),
+			setKeys(p.Adobe.ExtensionsSet),
+			setKeys(p.Database.ExtensionsSet),
+			setKeys(p.EmailArchive.ExtensionsSet),
+			setKeys(p.LargeMedia.ExtensionsSet),
+			setKeys(p.VirtualMachine.ExtensionsSet),
+			setKeys(p.Backup.ExtensionsSet),
+			setKeys(p.OneNote.ExtensionsSet),
+		}
+	default:
+		return nil
+	}
+
+	var rules []RuleExplanation
+	for _, exts := range groups {
+		if len(exts) == 0 {
+			continue
+		}
+		for _, rule := range v.explainExtension(exts[0]) {
+			if rule.Issue.Type == issueType {
+				rule.Extensions = exts
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules
+}
+
+// sizeThreshold returns the size above which a size-dependent problematic
+// file category, or an extension in the Other category, is flagged or
+// escalated
+func (v *Validator) sizeThreshold(category, ext string) int64 {
+	p := v.config.ProblematicFiles
+	switch category {
+	case "Other":
+		return p.Other[ext].SizeThresholdBytes
+	case p.EmailArchive.Category:
+		return p.EmailArchive.SizeWarningBytes
+	case p.LargeMedia.Category:
+		return p.LargeMedia.SizeThresholdBytes
+	case p.Backup.Category:
+		return p.Backup.SizeThresholdBytes
+	}
+	return 0
+}
+
+// withoutItem clears the fields that describe the sample item
+func withoutItem(issue models.Issue) models.Issue {
+	issue.Path = ""
+	issue.Size = 0
+	return issue
+}
+
+func containsIssue(issues []models.Issue, issue models.Issue) bool {
+	for _, existing := range issues {
+		if existing.Category == issue.Category && existing.Severity == issue.Severity {
+			return true
+		}
+	}
+	return false
+}
+
+func setKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
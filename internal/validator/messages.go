@@ -0,0 +1,251 @@
+package validator
+
+import "github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+
+// Message IDs identify each issue condition in the message catalog and are
+// reported as Issue.MessageID. They are stable across versions and
+// languages; add new IDs rather than reusing old ones.
+const (
+	msgNameTooLong   = "path.name-too-long"
+	msgPathTooLong   = "path.too-long"
+	msgPathNearLimit = "path.near-limit"
+	msgDeepFolder    = "path.deep-folder"
+
+	msgFolderNameTooLong = "path.folder-name-too-long"
+	msgFolderNameLong    = "path.folder-name-long"
+	msgSyncPathTooLong   = "path.sync-too-long"
+
+	msgNameTooLongBase  = "path.name-too-long-base"
+	msgExtensionTooLong = "path.extension-too-long"
+
+	msgInvalidChars    = "chars.invalid"
+	msgInvisibleOnly   = "chars.invisible-only"
+	msgBlankName       = "chars.blank-name"
+	msgInvisibleChars  = "chars.invisible"
+	msgControlChars    = "chars.control"
+	msgBlockedPattern  = "chars.blocked-pattern"
+	msgFilePrefix      = "chars.file-prefix"
+	msgFolderPrefix    = "chars.folder-prefix"
+	msgOwnerFileOpen   = "chars.owner-file-open"
+	msgOwnerFileOrphan = "chars.owner-file-orphan"
+	msgSuggestedName   = "name.suggested"
+
+	msgReservedName  = "reserved.name"
+	msgBlockedName   = "reserved.blocked-name"
+	msgRootLevelName = "reserved.root-level"
+
+	msgBlockedCustom     = "blocked.custom"
+	msgBlockedExecutable = "blocked.executable"
+	msgBlockedScript     = "blocked.script"
+	msgBlockedSystem     = "blocked.system"
+	msgBlockedDangerous  = "blocked.dangerous"
+	msgDoubleExtension   = "blocked.double-extension"
+
+	msgCAD              = "problematic.cad"
+	msgAdobe            = "problematic.adobe"
+	msgDatabase         = "problematic.database"
+	msgEmailArchive     = "problematic.email-archive"
+	msgLargeMedia       = "problematic.large-media"
+	msgVirtualMachine   = "problematic.virtual-machine"
+	msgBackup           = "problematic.backup"
+	msgOneNote          = "problematic.onenote"
+	msgOtherProblematic = "problematic.other"
+	msgSecrets          = "problematic.secrets"
+	msgVersionControl   = "problematic.version-control"
+	msgVCSLinked        = "problematic.version-control-linked"
+
+	msgSizeOverLimit = "size.over-limit"
+	msgSizeHuge      = "size.huge"
+	msgSizeLarge     = "size.large"
+	msgSizeCritical  = "size.critical"
+
+	msgHidden = "hidden.hidden"
+	msgSystem = "hidden.system"
+
+	msgSymlink      = "reparse.symlink"
+	msgMountPoint   = "reparse.mount-point"
+	msgDedup        = "reparse.dedup"
+	msgCloud        = "reparse.cloud"
+	msgReparseOther = "reparse.other"
+
+	msgNoExtension       = "content.no-extension"
+	msgExtensionMismatch = "content.mismatch"
+
+	msgFileInUse = "lock.in-use"
+
+	msgZoneIdentifier = "stream.zone-identifier"
+	msgStreams        = "stream.other"
+
+	msgCustomRule = "custom.rule"
+
+	msgCaseConflict = "case.conflict"
+
+	msgConfusableName = "confusable.name"
+
+	msgShortNameCollision = "shortname.collision"
+	msgShortNameAlias     = "shortname.alias"
+
+	msgNameConflict = "name.conflict"
+
+	msgFolderEmpty        = "folder.empty"
+	msgFolderOnlyExcluded = "folder.only-excluded"
+)
+
+// issueCodes assigns each message ID the code reported as Issue.Code.
+// Codes are stable: never renumber or reuse one, and add new conditions
+// at the end of their group.
+var issueCodes = map[string]string{
+	msgNameTooLong:   "SPO-PATH-001",
+	msgPathTooLong:   "SPO-PATH-002",
+	msgPathNearLimit: "SPO-PATH-003",
+	msgDeepFolder:    "SPO-PATH-004",
+
+	msgFolderNameTooLong: "SPO-PATH-005",
+	msgFolderNameLong:    "SPO-PATH-006",
+	msgSyncPathTooLong:   "SPO-PATH-007",
+
+	msgInvalidChars:    "SPO-CHAR-001",
+	msgInvisibleOnly:   "SPO-CHAR-002",
+	msgInvisibleChars:  "SPO-CHAR-003",
+	msgControlChars:    "SPO-CHAR-004",
+	msgBlockedPattern:  "SPO-CHAR-005",
+	msgFilePrefix:      "SPO-CHAR-006",
+	msgFolderPrefix:    "SPO-CHAR-007",
+	msgOwnerFileOpen:   "SPO-CHAR-008",
+	msgOwnerFileOrphan: "SPO-CHAR-009",
+	msgBlankName:       "SPO-CHAR-010",
+
+	msgReservedName:  "SPO-NAME-001",
+	msgBlockedName:   "SPO-NAME-002",
+	msgRootLevelName: "SPO-NAME-003",
+
+	msgBlockedCustom:     "SPO-BLOCK-001",
+	msgBlockedExecutable: "SPO-BLOCK-002",
+	msgBlockedScript:     "SPO-BLOCK-003",
+	msgBlockedSystem:     "SPO-BLOCK-004",
+	msgBlockedDangerous:  "SPO-BLOCK-005",
+	msgDoubleExtension:   "SPO-BLOCK-006",
+
+	msgCAD:              "SPO-FILE-001",
+	msgAdobe:            "SPO-FILE-002",
+	msgDatabase:         "SPO-FILE-003",
+	msgEmailArchive:     "SPO-FILE-004",
+	msgLargeMedia:       "SPO-FILE-005",
+	msgVirtualMachine:   "SPO-FILE-006",
+	msgBackup:           "SPO-FILE-007",
+	msgOneNote:          "SPO-FILE-008",
+	msgOtherProblematic: "SPO-FILE-009",
+	msgSecrets:          "SPO-FILE-010",
+	msgVersionControl:   "SPO-FILE-011",
+	msgVCSLinked:        "SPO-FILE-012",
+
+	msgSizeOverLimit: "SPO-SIZE-001",
+	msgSizeHuge:      "SPO-SIZE-002",
+	msgSizeLarge:     "SPO-SIZE-003",
+	msgSizeCritical:  "SPO-SIZE-004",
+
+	msgHidden: "SPO-HIDDEN-001",
+	msgSystem: "SPO-HIDDEN-002",
+
+	msgSymlink:      "SPO-REPARSE-001",
+	msgMountPoint:   "SPO-REPARSE-002",
+	msgDedup:        "SPO-REPARSE-003",
+	msgCloud:        "SPO-REPARSE-004",
+	msgReparseOther: "SPO-REPARSE-005",
+
+	msgNoExtension:       "SPO-CONTENT-001",
+	msgExtensionMismatch: "SPO-CONTENT-002",
+
+	msgFileInUse: "SPO-LOCK-001",
+
+	msgZoneIdentifier: "SPO-STREAM-001",
+	msgStreams:        "SPO-STREAM-002",
+
+	msgCustomRule: "SPO-CUSTOM-001",
+
+	msgCaseConflict: "SPO-CASE-001",
+
+	msgConfusableName: "SPO-CONFUSABLE-001",
+
+	msgShortNameCollision: "SPO-SHORTNAME-001",
+	msgShortNameAlias:     "SPO-SHORTNAME-002",
+
+	msgNameConflict: "SPO-CONFLICT-001",
+
+	msgFolderEmpty:        "SPO-FOLDER-001",
+	msgFolderOnlyExcluded: "SPO-FOLDER-002",
+}
+
+// treeMessages are the conditions reported on one item but decided by
+// others, such as the deepest path below a folder, so re-checking the
+// item on its own cannot confirm them
+var treeMessages = map[string]bool{
+	msgDeepFolder:         true,
+	msgFolderEmpty:        true,
+	msgFolderOnlyExcluded: true,
+	msgVersionControl:     true,
+	msgVCSLinked:          true,
+	msgShortNameCollision: true,
+	msgShortNameAlias:     true,
+	msgNameConflict:       true,
+	msgCaseConflict:       true,
+	msgConfusableName:     true,
+	msgOwnerFileOpen:      true,
+	msgOwnerFileOrphan:    true,
+}
+
+// NeedsTree reports whether an issue with this message ID can only be
+// confirmed by scanning the whole tree
+func NeedsTree(id string) bool {
+	return treeMessages[id]
+}
+
+// renameMessages are the conditions a new name fixes, which SuggestName
+// corrects or which need a name chosen by hand
+var renameMessages = map[string]bool{
+	msgInvalidChars:   true,
+	msgInvisibleOnly:  true,
+	msgBlankName:      true,
+	msgInvisibleChars: true,
+	msgControlChars:   true,
+	msgBlockedPattern: true,
+	msgFilePrefix:     true,
+	msgFolderPrefix:   true,
+	msgReservedName:   true,
+	msgBlockedName:    true,
+	msgRootLevelName:  true,
+}
+
+// FixedByRename reports whether an issue with this message ID is fixed by
+// renaming the item
+func FixedByRename(id string) bool {
+	return renameMessages[id]
+}
+
+// withCodes sets the Code of each issue from its message ID, and whether
+// it needs action
+func (v *Validator) withCodes(issues []models.Issue) []models.Issue {
+	for i := range issues {
+		issues[i].Code = issueCodes[issues[i].MessageID]
+		issues[i].ActionRequired = !v.autoSkipped(&issues[i])
+	}
+	return issues
+}
+
+// autoSkipped reports whether migration tools skip the item of an issue
+// on their own: its code is in settings.autoSkippedCodes, or it matched a
+// custom rule marked autoSkipped
+func (v *Validator) autoSkipped(issue *models.Issue) bool {
+	if v.config.Settings.AutoSkippedSet[issue.Code] {
+		return true
+	}
+	if issue.MessageID != msgCustomRule {
+		return false
+	}
+	for i := range v.config.CustomRules {
+		if v.config.CustomRules[i].Name == issue.Category {
+			return v.config.CustomRules[i].AutoSkipped
+		}
+	}
+	return false
+}
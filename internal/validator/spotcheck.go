@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// ValidateName runs the checks on a single proposed name, as a file or,
+// with isDir, a folder at the top of the library. Nothing on disk is
+// read: checks that depend on the file itself, such as attributes, locks,
+// streams and content sniffing, have nothing to report, and the size is 0.
+func (v *Validator) ValidateName(name string, isDir bool) []models.Issue {
+	item := &models.FileSystemItem{
+		Path:         name,
+		Name:         name,
+		RelativePath: name,
+		IsDir:        isDir,
+	}
+	return v.ValidateItem(item)
+}
+
+// ValidatePath runs the checks on a proposed path relative to the library
+// root, with either / or \ between names. Every folder along the path is
+// checked as a folder, the way a scan reports them, and the last name as
+// a file or, with isDir, a folder. Like ValidateName, nothing on disk is
+// read.
+func (v *Validator) ValidatePath(path string, isDir bool) []models.Issue {
+	var names []string
+	for _, name := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if name != "." {
+			names = append(names, name)
+		}
+	}
+
+	var issues []models.Issue
+	for i, name := range names {
+		rel := filepath.Join(names[:i+1]...)
+		item := &models.FileSystemItem{
+			Path:         filepath.ToSlash(rel),
+			Name:         name,
+			RelativePath: rel,
+			IsDir:        isDir || i < len(names)-1,
+		}
+		issues = append(issues, v.ValidateItem(item)...)
+	}
+	return issues
+}
+
+// ValidateRoot checks the name of the scan root, which a scan does not
+// validate as an item, for invalid characters, reserved names and length.
+// The destination library or folder is often named after it, so a
+// problem here affects every path below it. A drive or share root has no
+// name of its own and returns nil. Like ValidateName, nothing on disk is
+// read.
+func (v *Validator) ValidateRoot(root string) []models.Issue {
+	root = filepath.Clean(root)
+	name := filepath.Base(root)
+	if name == "." || name == string(filepath.Separator) || root == filepath.VolumeName(root) {
+		return nil
+	}
+
+	item := &models.FileSystemItem{
+		Path:         root,
+		Name:         name,
+		RelativePath: name,
+		IsDir:        true,
+	}
+
+	var issues []models.Issue
+	if v.enabledChecks["InvalidCharacters"] {
+		issues = append(issues, v.checkInvalidCharacters(item)...)
+	}
+	if v.enabledChecks["ReservedNames"] {
+		issues = append(issues, v.checkReservedNames(item)...)
+	}
+	if v.enabledChecks["PathLength"] {
+		issues = append(issues, v.checkFolderNameLength(item, v.config.SPOLimits.MaxFileNameLength)...)
+	}
+	return v.withCodes(issues)
+}
@@ -1,11 +1,20 @@
 package validator
 
 import (
+	"math"
 	"net/url"
+	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/i18n"
 	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
 )
 
@@ -14,13 +23,85 @@ type Validator struct {
 	config             *config.Config
 	destinationURL     string
 	destinationPathLen int
+	encodedBasis       bool
 	enabledChecks      map[string]bool
+
+	// Whole-tree state evaluated in Finalize
+	mu              sync.Mutex
+	caseGroups      map[string][]*models.FileSystemItem
+	nameListing     []*nameFolder                       // Folders being listed, outermost first
+	nameConflicts   map[string][]nameEntry              // Keyed by folder and SharePoint name
+	lookalikeGroups map[string][]*models.FileSystemItem // Keyed by folder and confusable skeleton
+	deepPaths       map[string]deepestPath              // Keyed by folder relative path
+	ownerFiles      []*models.FileSystemItem
+	folders         map[string]*models.FileSystemItem // Keyed by path
+	nonEmpty        map[string]bool                   // Paths of folders with an item in them
+	contents        models.FolderContents
+	vcsFolders      map[string]*vcsMetadata     // Keyed by path of the metadata folder
+	vcsLinks        []*models.FileSystemItem    // Metadata files, such as the .git file of a Git submodule
+	shortNames      map[string]*shortNameFolder // Keyed by lower-cased folder relative path
+}
+
+// nameFolder holds the SharePoint names in a folder that is still being
+// listed, for NameConflicts
+type nameFolder struct {
+	dir   string               // Lower-cased canonical relative path
+	names map[string]nameEntry // First item with each SharePoint name
+}
+
+// nameEntry is an item that may share its SharePoint name with a sibling
+type nameEntry struct {
+	name  string
+	path  string
+	isDir bool
+}
+
+// shortNameFolder holds the names in one folder and the items with an 8.3
+// short name, for checkShortNames
+type shortNameFolder struct {
+	names  map[string][]*models.FileSystemItem // Keyed by upper-cased name
+	shorts []*models.FileSystemItem
+}
+
+// ownerFilePrefix starts the owner files Office creates next to an open
+// document, such as ~$Report.docx or ~$port.docx for Report.docx
+const ownerFilePrefix = "~$"
+
+// ownerFileExtensions are the document types Office creates owner files for
+var ownerFileExtensions = map[string]bool{
+	".doc": true, ".docx": true, ".docm": true, ".dot": true, ".dotx": true, ".dotm": true, ".rtf": true,
+	".xls": true, ".xlsx": true, ".xlsm": true, ".xlsb": true, ".xlt": true, ".xltx": true, ".xltm": true,
+	".ppt": true, ".pptx": true, ".pptm": true, ".pot": true, ".potx": true, ".potm": true,
+	".pps": true, ".ppsx": true, ".ppsm": true,
+}
+
+// zoneIdentifierStream is the alternate data stream Windows adds to
+// downloaded files to record where they came from (the mark of the web)
+const zoneIdentifierStream = "Zone.Identifier"
+
+// vcsMetadata counts the files in a version control metadata folder
+type vcsMetadata struct {
+	files int
+	bytes int64
+}
+
+// perFolderVCS are the metadata folders, upper-cased, that Subversion
+// before 1.7 and CVS keep in every folder of a working copy rather than
+// only at its top
+var perFolderVCS = map[string]bool{".SVN": true, "CVS": true}
+
+// deepestPath is the longest over-limit path found below a folder
+type deepestPath struct {
+	folder string // Absolute path of the folder
+	path   string // Absolute path of the descendant
+	length int
 }
 
 // NewValidator creates a new Validator instance
 func NewValidator(cfg *config.Config, destinationURL string, enabledChecks map[string]bool) *Validator {
-	// Calculate destination path length for URL encoding
-	destPathLen := destinationLength(destinationURL)
+	// Calculate the server-relative length of the destination library
+	encodedBasis := cfg.Settings.PathLengthBasis == "encoded"
+	destPathLen := destinationLength(destinationURL, encodedBasis)
 
 	if enabledChecks == nil {
 		enabledChecks = cfg.Settings.DefaultChecks
@@ -30,7 +111,16 @@ func NewValidator(cfg *config.Config, destinationURL string, enabledChecks map[s
 		config:             cfg,
 		destinationURL:     destinationURL,
 		destinationPathLen: destPathLen,
+		encodedBasis:       encodedBasis,
 		enabledChecks:      enabledChecks,
+		caseGroups:         make(map[string][]*models.FileSystemItem),
+		nameConflicts:      make(map[string][]nameEntry),
+		lookalikeGroups:    make(map[string][]*models.FileSystemItem),
+		deepPaths:          make(map[string]deepestPath),
+		folders:            make(map[string]*models.FileSystemItem),
+		nonEmpty:           make(map[string]bool),
+		vcsFolders:         make(map[string]*vcsMetadata),
+		shortNames:         make(map[string]*shortNameFolder),
 	}
 }
 
@@ -52,86 +142,1846 @@ func (v *Validator) ValidateItem(item *models.FileSystemItem) []models.Issue {
 
 	if !item.IsDir {
 		ext := strings.ToLower(filepath.Ext(item.Name))
+		if v.isBlankName(item.Name) {
+			ext = "" // "..." is all dots, not a file with an empty extension
+		}
 
 		if v.enabledChecks["BlockedFileTypes"] {
 			issues = append(issues, v.checkBlockedFileTypes(item, ext)...)
 		}
 
+		if v.enabledChecks["DoubleExtensions"] {
+			issues = append(issues, v.checkDoubleExtensions(item, ext)...)
+		}
+
 		if v.enabledChecks["ProblematicFiles"] {
-			issues = append(issues, v.checkProblematicFiles(item, ext)...)
+			issues = append(issues, v.withoutAccepted(v.checkProblematicFiles(item, ext))...)
+		}
+
+		if v.enabledChecks["Secrets"] {
+			issues = append(issues, v.withoutAccepted(v.checkSecrets(item))...)
+		}
+
+		if v.enabledChecks["FileSize"] {
+			issues = append(issues, v.checkFileSize(item)...)
+		}
+
+		if v.enabledChecks["ExtensionMismatch"] && item.ContentType != "" {
+			issues = append(issues, v.checkExtensionMismatch(item, ext)...)
+		}
+	}
+
+	if v.enabledChecks["HiddenFiles"] && (item.IsHidden || item.IsSystem) {
+		issues = append(issues, v.checkHiddenFiles(item)...)
+	}
+
+	if v.enabledChecks["ReparsePoints"] && item.ReparseType != "" {
+		issues = append(issues, v.checkReparsePoints(item)...)
+	}
+
+	if v.enabledChecks["FileLocks"] && item.IsLocked {
+		issues = append(issues, v.checkFileLocks(item)...)
+	}
+
+	if v.enabledChecks["AlternateStreams"] && len(item.Streams) > 0 {
+		issues = append(issues, v.checkAlternateStreams(item)...)
+	}
+
+	if v.enabledChecks["CustomRules"] && len(v.config.CustomRules) > 0 {
+		issues = append(issues, v.checkCustomRules(item)...)
+	}
+
+	v.Observe(item)
+
+	return v.withCodes(issues)
+}
+
+// Observe records an item for the whole-tree checks run by Finalize
+// without validating it. ValidateItem calls it; use it directly for items
+// whose issues are already known.
+func (v *Validator) Observe(item *models.FileSystemItem) {
+	if v.enabledChecks["CaseConflicts"] {
+		v.trackCaseConflicts(item)
+	}
+	if v.enabledChecks["ConfusableNames"] {
+		v.trackConfusableNames(item)
+	}
+	if v.enabledChecks["PathLength"] {
+		v.trackDeepPaths(item)
+	}
+	if v.enabledChecks["InvalidCharacters"] && !item.IsDir {
+		v.trackOwnerFiles(item)
+	}
+	if v.enabledChecks["EmptyFolders"] {
+		v.trackFolderContents(item)
+	}
+	if v.enabledChecks["VersionControl"] {
+		v.trackVersionControl(item)
+	}
+	if v.enabledChecks["ShortNames"] {
+		v.trackShortNames(item)
+	}
+}
+
+// ObserveListing records an item for the checks that compare the items of
+// one folder as they are listed. Call it with every item in walk order,
+// each folder's items together and depth first, as the scanner does; only
+// the folders still being listed are held, so memory follows the depth of
+// the tree rather than its size.
+func (v *Validator) ObserveListing(item *models.FileSystemItem) {
+	if v.enabledChecks["NameConflicts"] {
+		v.trackNameConflicts(item)
+	}
+}
+
+// SetFolderContents tells Finalize which folders the scanner did not fully
+// list. Until it is called with a complete walk, no folder is reported as
+// empty.
+func (v *Validator) SetFolderContents(contents models.FolderContents) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.contents = contents
+}
+
+// Finalize runs the whole-tree checks that can only be evaluated once
+// every item has been seen. Call it once after the last ValidateItem.
+func (v *Validator) Finalize() []models.Issue {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var issues []models.Issue
+
+	if v.enabledChecks["CaseConflicts"] {
+		issues = append(issues, v.checkCaseConflicts()...)
+	}
+
+	if v.enabledChecks["NameConflicts"] {
+		issues = append(issues, v.checkNameConflicts()...)
+	}
+
+	if v.enabledChecks["ConfusableNames"] {
+		issues = append(issues, v.checkConfusableNames()...)
+	}
+
+	if v.enabledChecks["PathLength"] {
+		issues = append(issues, v.checkDeepPaths()...)
+	}
+
+	if v.enabledChecks["InvalidCharacters"] {
+		issues = append(issues, v.checkOwnerFiles()...)
+	}
+
+	if v.enabledChecks["EmptyFolders"] {
+		issues = append(issues, v.checkEmptyFolders()...)
+	}
+
+	if v.enabledChecks["VersionControl"] {
+		issues = append(issues, v.withoutAccepted(v.checkVersionControl())...)
+	}
+
+	if v.enabledChecks["ShortNames"] {
+		issues = append(issues, v.checkShortNames()...)
+	}
+
+	return v.withCodes(issues)
+}
+
+// checkPathLength validates path length constraints
+func (v *Validator) checkPathLength(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+
+	// Check individual file/folder name length
+	maxNameLength := v.config.SPOLimits.MaxFileNameLength
+	if item.IsDir {
+		issues = append(issues, v.checkFolderNameLength(item, maxNameLength)...)
+	} else {
+		issues = append(issues, v.checkFileNameLength(item, maxNameLength)...)
+	}
+
+	// Calculate server-relative path length
+	relativePath := canonicalRelativePath(item.RelativePath)
+	totalLength := v.serverRelativeLength(relativePath)
+
+	maxLength := v.config.SPOLimits.MaxPathLength
+
+	// Check if exceeds limit
+	if totalLength > maxLength {
+		overBy := totalLength - maxLength
+		text := v.text(msgPathTooLong)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssuePathLength,
+			Severity:        models.SeverityCritical,
+			Message:         formatMessage(text.Message, maxLength),
+			MessageID:       msgPathTooLong,
+			Details:         formatMessage(text.Details, totalLength, maxLength),
+			CurrentLength:   totalLength,
+			LimitPercent:    limitPercent(totalLength, maxLength),
+			IsDirectory:     item.IsDir,
+			RemediationHint: formatRemediationHint(text.Hint, overBy),
+		})
+	} else {
+		// Check if approaching limit (warning threshold)
+		thresholdPercent := v.config.Settings.PathWarningThresholdPercent
+		warningThreshold := (maxLength * thresholdPercent) / 100
+
+		if totalLength >= warningThreshold {
+			remaining := maxLength - totalLength
+			percentUsed := (totalLength * 100) / maxLength
+			text := v.text(msgPathNearLimit)
+			issues = append(issues, models.Issue{
+				Path:            item.Path,
+				Type:            models.IssuePathLength,
+				Severity:        models.SeverityWarning,
+				Message:         formatMessage(text.Message, percentUsed, maxLength),
+				MessageID:       msgPathNearLimit,
+				Details:         formatMessage(text.Details, totalLength, maxLength),
+				CurrentLength:   totalLength,
+				LimitPercent:    limitPercent(totalLength, maxLength),
+				IsDirectory:     item.IsDir,
+				RemediationHint: formatRemediationHint(text.Hint, remaining),
+			})
+		}
+
+		if v.enabledChecks["SyncPathLength"] {
+			issues = append(issues, v.checkSyncPathLength(item, relativePath)...)
+		}
+	}
+
+	return issues
+}
+
+// windowsMaxPath is MAX_PATH. It includes the terminating null, so Windows
+// paths must be shorter than this.
+const windowsMaxPath = 260
+
+// checkSyncPathLength estimates the local path of an item once OneDrive
+// syncs the library to Windows, where Explorer and many apps still enforce
+// MAX_PATH, and warns when it is too long even though SharePoint accepts it
+func (v *Validator) checkSyncPathLength(item *models.FileSystemItem, relativePath string) []models.Issue {
+	if relativePath == "" {
+		return nil
+	}
+
+	root := strings.TrimRight(v.config.Settings.SyncRoot, `\/`)
+	localLength := utf8.RuneCountInString(root) + 1 + utf8.RuneCountInString(relativePath)
+	if localLength < windowsMaxPath {
+		return nil
+	}
+
+	maxLength := windowsMaxPath - 1
+	text := v.text(msgSyncPathTooLong)
+	return []models.Issue{{
+		Path:            item.Path,
+		Type:            models.IssuePathLength,
+		Severity:        models.SeverityWarning,
+		Message:         text.Message,
+		MessageID:       msgSyncPathTooLong,
+		Details:         formatMessage(text.Details, localLength, maxLength),
+		CurrentLength:   localLength,
+		LimitPercent:    limitPercent(localLength, maxLength),
+		IsDirectory:     item.IsDir,
+		RemediationHint: formatRemediationHint(text.Hint, maxLength, root),
+	}}
+}
+
+// checkFileNameLength reports a file name over the limit, with how much
+// of it is the extension. The hint asks for a shorter base name, so the
+// extension that decides how the file opens is kept, unless the extension
+// alone leaves no room.
+func (v *Validator) checkFileNameLength(item *models.FileSystemItem, maxNameLength int) []models.Issue {
+	nameLength := utf8.RuneCountInString(item.Name)
+	if nameLength <= maxNameLength {
+		return nil
+	}
+
+	ext := filepath.Ext(item.Name)
+	if ext == item.Name {
+		ext = "" // A dot file such as .gitignore has no base name
+	}
+	extLength := utf8.RuneCountInString(ext)
+	baseLength := nameLength - extLength
+
+	text := v.text(msgNameTooLong)
+	hint := formatRemediationHint(text.Hint, maxNameLength, nameLength)
+	switch {
+	case ext == "":
+	case extLength >= maxNameLength:
+		hint = formatRemediationHint(v.text(msgExtensionTooLong).Hint, extLength, ext)
+	default:
+		hint = formatRemediationHint(v.text(msgNameTooLongBase).Hint, maxNameLength-extLength, nameLength-maxNameLength, ext)
+	}
+
+	return []models.Issue{{
+		Path:            item.Path,
+		Type:            models.IssuePathLength,
+		Severity:        models.SeverityCritical,
+		Message:         formatMessage(text.Message, maxNameLength),
+		MessageID:       msgNameTooLong,
+		Details:         formatMessage(text.Details, nameLength, maxNameLength, baseLength, extLength),
+		CurrentLength:   nameLength,
+		LimitPercent:    limitPercent(nameLength, maxNameLength),
+		IsDirectory:     false,
+		RemediationHint: hint,
+	}}
+}
+
+// checkFolderNameLength checks a folder name against the name limit and
+// the optional folder warning length. Every path below the folder
+// inherits its name, so the hints warn that renaming it moves all of them.
+func (v *Validator) checkFolderNameLength(item *models.FileSystemItem, maxNameLength int) []models.Issue {
+	var issues []models.Issue
+	nameLength := utf8.RuneCountInString(item.Name)
+
+	if nameLength > maxNameLength {
+		text := v.text(msgFolderNameTooLong)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssuePathLength,
+			Severity:        models.SeverityCritical,
+			Message:         formatMessage(text.Message, maxNameLength),
+			MessageID:       msgFolderNameTooLong,
+			Details:         formatMessage(text.Details, nameLength, maxNameLength),
+			CurrentLength:   nameLength,
+			LimitPercent:    limitPercent(nameLength, maxNameLength),
+			IsDirectory:     true,
+			RemediationHint: formatRemediationHint(text.Hint, maxNameLength, nameLength),
+		})
+		return issues
+	}
+
+	warnLength := v.config.Settings.FolderNameWarningLength
+	if warnLength > 0 && nameLength >= warnLength {
+		text := v.text(msgFolderNameLong)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssuePathLength,
+			Severity:        models.SeverityWarning,
+			Message:         formatMessage(text.Message, warnLength),
+			MessageID:       msgFolderNameLong,
+			Details:         formatMessage(text.Details, nameLength, maxNameLength),
+			CurrentLength:   nameLength,
+			LimitPercent:    limitPercent(nameLength, maxNameLength),
+			IsDirectory:     true,
+			RemediationHint: text.Hint,
+		})
+	}
+
+	return issues
+}
+
+// trackDeepPaths records an over-limit path against each folder above it
+// so Finalize can point at the folders that hold too-deep subtrees
+func (v *Validator) trackDeepPaths(item *models.FileSystemItem) {
+	relativePath := canonicalRelativePath(item.RelativePath)
+	if relativePath == "" {
+		return
+	}
+	length := v.serverRelativeLength(relativePath)
+	if length <= v.config.SPOLimits.MaxPathLength {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	folder := filepath.Dir(item.Path)
+	for rel := path.Dir(relativePath); rel != "." && rel != "/"; rel = path.Dir(rel) {
+		current, ok := v.deepPaths[rel]
+		if ok && (current.length > length || current.length == length && current.path < item.Path) {
+			break // Every folder further up already has a deeper path
+		}
+		v.deepPaths[rel] = deepestPath{folder: folder, path: item.Path, length: length}
+		folder = filepath.Dir(folder)
+	}
+}
+
+// checkDeepPaths warns on folders that are within the path limit but hold
+// a descendant that is not, naming the deepest one. Each descendant is
+// reported once, on the highest folder below the scan root whose deepest
+// path it is, so the warning lands on the folder structure that needs
+// flattening rather than on every ancestor.
+func (v *Validator) checkDeepPaths() []models.Issue {
+	var issues []models.Issue
+	maxLength := v.config.SPOLimits.MaxPathLength
+
+	rels := make([]string, 0, len(v.deepPaths))
+	for rel := range v.deepPaths {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	for _, rel := range rels {
+		deepest := v.deepPaths[rel]
+		if v.serverRelativeLength(rel) > maxLength {
+			continue // The folder itself is reported by checkPathLength
+		}
+		if parent, ok := v.deepPaths[path.Dir(rel)]; ok && parent.path == deepest.path {
+			continue
+		}
+
+		overBy := deepest.length - maxLength
+		text := v.text(msgDeepFolder)
+		issues = append(issues, models.Issue{
+			Path:            deepest.folder,
+			Type:            models.IssuePathLength,
+			Severity:        models.SeverityWarning,
+			Message:         formatMessage(text.Message, maxLength),
+			MessageID:       msgDeepFolder,
+			Details:         formatMessage(text.Details, deepest.length, overBy, deepest.path),
+			CurrentLength:   deepest.length,
+			LimitPercent:    limitPercent(deepest.length, maxLength),
+			IsDirectory:     true,
+			RemediationHint: formatRemediationHint(text.Hint, overBy),
+		})
+	}
+
+	return issues
+}
+
+// checkInvalidCharacters validates against invalid characters
+func (v *Validator) checkInvalidCharacters(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+	var foundChars []rune
+
+	for _, ch := range item.Name {
+		if v.config.SPOLimits.InvalidCharsSet[ch] {
+			foundChars = append(foundChars, ch)
+		}
+	}
+
+	if len(foundChars) > 0 {
+		charList := formatCharList(foundChars)
+		text := v.text(msgInvalidChars)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueInvalidCharacters,
+			Severity:        models.SeverityCritical,
+			Message:         text.Message,
+			MessageID:       msgInvalidChars,
+			Details:         formatMessage(text.Details, charList),
+			IsDirectory:     item.IsDir,
+			RemediationHint: v.withSuggestedName(formatRemediationHint(text.Hint, charList), item),
+		})
+	}
+
+	// Check for names made only of dots and whitespace, or else for
+	// invisible and zero-width characters, which such a name may include
+	if blank := v.checkBlankName(item); len(blank) > 0 {
+		issues = append(issues, blank...)
+	} else {
+		issues = append(issues, v.checkInvisibleCharacters(item)...)
+	}
+
+	// Check for newlines and other control characters
+	issues = append(issues, v.checkControlCharacters(item)...)
+
+	// Check for blocked patterns
+	nameLower := strings.ToLower(item.Name)
+	for _, pattern := range v.config.SPOLimits.BlockedPatterns {
+		if strings.Contains(nameLower, strings.ToLower(pattern)) {
+			text := v.text(msgBlockedPattern)
+			issues = append(issues, models.Issue{
+				Path:            item.Path,
+				Type:            models.IssueInvalidCharacters,
+				Severity:        models.SeverityCritical,
+				Message:         text.Message,
+				MessageID:       msgBlockedPattern,
+				Details:         formatMessage(text.Details, pattern),
+				IsDirectory:     item.IsDir,
+				RemediationHint: formatRemediationHint(text.Hint, pattern),
+			})
+		}
+	}
+
+	// Check for blocked prefixes, unless the whole name is dots and
+	// spaces, which is reported above
+	if !v.isBlankName(item.Name) {
+		issues = append(issues, v.checkPrefixes(item)...)
+	}
+
+	return issues
+}
+
+// checkPrefixes reports names that start with a prefix from the prefix
+// rules. Owner files, matched by the ~$ rule for files, are paired with
+// their documents by checkOwnerFiles instead.
+func (v *Validator) checkPrefixes(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+
+	id := msgFilePrefix
+	if item.IsDir {
+		id = msgFolderPrefix
+	}
+	text := v.text(id)
+
+	for i := range v.config.SPOLimits.PrefixRules {
+		rule := &v.config.SPOLimits.PrefixRules[i]
+		if !rule.AppliesToItem(item.IsDir) || !strings.HasPrefix(item.Name, rule.Prefix) {
+			continue
+		}
+		if !item.IsDir && rule.Prefix == ownerFilePrefix {
+			continue // Paired with its document by checkOwnerFiles
+		}
+
+		message := rule.Message
+		if message == "" {
+			message = text.Message
+		}
+
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueInvalidCharacters,
+			Severity:        models.Severity(rule.Severity),
+			Message:         message,
+			MessageID:       id,
+			Details:         formatMessage(text.Details, rule.Prefix),
+			IsDirectory:     item.IsDir,
+			RemediationHint: formatRemediationHint(text.Hint, rule.Prefix),
+		})
+	}
+
+	return issues
+}
+
+// blockedPrefixes returns the prefixes of the prefix rules for a file or,
+// with isDir, a folder
+func (v *Validator) blockedPrefixes(isDir bool) []string {
+	var prefixes []string
+	for i := range v.config.SPOLimits.PrefixRules {
+		if rule := &v.config.SPOLimits.PrefixRules[i]; rule.AppliesToItem(isDir) {
+			prefixes = append(prefixes, rule.Prefix)
+		}
+	}
+	return prefixes
+}
+
+// trackOwnerFiles records Office owner files for checkOwnerFiles. Their
+// documents are looked for in Finalize, since owner files are few and
+// documents are many.
+func (v *Validator) trackOwnerFiles(item *models.FileSystemItem) {
+	if !strings.HasPrefix(item.Name, ownerFilePrefix) || v.ownerFileRule() == nil {
+		return
+	}
+
+	v.mu.Lock()
+	v.ownerFiles = append(v.ownerFiles, item)
+	v.mu.Unlock()
+}
+
+// trackFolderContents records folders and marks the folder each item is in
+// as not empty
+func (v *Validator) trackFolderContents(item *models.FileSystemItem) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if item.IsDir {
+		v.folders[item.Path] = item
+	}
+	v.nonEmpty[filepath.Dir(item.Path)] = true
+}
+
+// checkEmptyFolders reports folders without any files or subfolders.
+// Folders whose only contents were excluded from the scan are reported
+// separately, and folders the scanner could not fully list are skipped.
+func (v *Validator) checkEmptyFolders() []models.Issue {
+	if !v.contents.Complete {
+		return nil
+	}
+
+	paths := make([]string, 0, len(v.folders))
+	for path, folder := range v.folders {
+		if !v.nonEmpty[path] && !v.contents.Unread[path] && folder.ReparseType == "" {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var issues []models.Issue
+	for _, path := range paths {
+		id := msgFolderEmpty
+		if v.contents.Excluded[path] {
+			id = msgFolderOnlyExcluded
+		}
+
+		text := v.text(id)
+		issues = append(issues, models.Issue{
+			Path:            path,
+			Type:            models.IssueEmptyFolder,
+			Severity:        models.SeverityInfo,
+			Message:         text.Message,
+			MessageID:       id,
+			Details:         text.Details,
+			IsDirectory:     true,
+			RemediationHint: text.Hint,
+		})
+	}
+
+	return issues
+}
+
+// trackVersionControl records version control metadata folders and counts
+// the files in them. Items are placed by their own path, so the order
+// they arrive in does not matter.
+func (v *Validator) trackVersionControl(item *models.FileSystemItem) {
+	names := v.config.ProblematicFiles.VersionControl.FoldersSet
+
+	// Look for the innermost metadata folder above the item, no higher
+	// than the scan root
+	var meta string
+	dir := item.Path
+	for depth := strings.Count(canonicalRelativePath(item.RelativePath), "/"); depth > 0; depth-- {
+		dir = filepath.Dir(dir)
+		if names[strings.ToUpper(filepath.Base(dir))] {
+			meta = dir
+			break
+		}
+	}
+	if meta == "" && !names[strings.ToUpper(item.Name)] {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	switch {
+	case meta != "":
+		m := v.vcsFolder(meta)
+		if !item.IsDir {
+			m.files++
+			m.bytes += item.Size
 		}
+	case item.IsDir:
+		v.vcsFolder(item.Path)
+	default:
+		v.vcsLinks = append(v.vcsLinks, item)
+	}
+}
+
+func (v *Validator) vcsFolder(path string) *vcsMetadata {
+	m, ok := v.vcsFolders[path]
+	if !ok {
+		m = &vcsMetadata{}
+		v.vcsFolders[path] = m
+	}
+	return m
+}
+
+// checkVersionControl reports each working copy once, on the folder that
+// holds its metadata, with the number and size of the metadata files.
+// Nested repositories, such as Git submodules or a clone inside another
+// working copy, are reported on their own. A metadata file in place of
+// the folder, as Git writes for submodules and linked worktrees, is
+// reported too; its files are counted with the repository that holds them.
+func (v *Validator) checkVersionControl() []models.Issue {
+	rule := v.config.ProblematicFiles.VersionControl
+
+	// Fold per-folder metadata into the folder at the top of the working copy
+	top := make(map[string]*vcsMetadata)
+	for path, m := range v.vcsFolders {
+		name := filepath.Base(path)
+		if perFolderVCS[strings.ToUpper(name)] {
+			for {
+				parent := filepath.Join(filepath.Dir(filepath.Dir(path)), name)
+				if _, ok := v.vcsFolders[parent]; !ok || parent == path {
+					break
+				}
+				path = parent
+			}
+		}
+		t, ok := top[path]
+		if !ok {
+			t = &vcsMetadata{}
+			top[path] = t
+		}
+		t.files += m.files
+		t.bytes += m.bytes
+	}
+
+	paths := make([]string, 0, len(top))
+	for path := range top {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var issues []models.Issue
+	text := v.text(msgVersionControl)
+	for _, path := range paths {
+		m := top[path]
+		name := filepath.Base(path)
+		issues = append(issues, models.Issue{
+			Path:            filepath.Dir(path),
+			Type:            models.IssueProblematicFile,
+			Severity:        models.SeverityWarning,
+			Message:         v.ruleMessage(msgVersionControl, rule.Message),
+			MessageID:       msgVersionControl,
+			Details:         formatMessage(text.Details, m.files, name, formatSize(m.bytes)),
+			Category:        rule.Category,
+			Size:            m.bytes,
+			Count:           m.files,
+			IsDirectory:     true,
+			RemediationHint: formatRemediationHint(text.Hint, name),
+		})
+	}
+
+	sort.Slice(v.vcsLinks, func(i, j int) bool {
+		return v.vcsLinks[i].Path < v.vcsLinks[j].Path
+	})
+	text = v.text(msgVCSLinked)
+	for _, item := range v.vcsLinks {
+		issues = append(issues, models.Issue{
+			Path:            filepath.Dir(item.Path),
+			Type:            models.IssueProblematicFile,
+			Severity:        models.SeverityWarning,
+			Message:         text.Message,
+			MessageID:       msgVCSLinked,
+			Details:         formatMessage(text.Details, item.Name),
+			Category:        rule.Category,
+			IsDirectory:     true,
+			RemediationHint: formatRemediationHint(text.Hint, item.Name),
+		})
+	}
+
+	return issues
+}
+
+// ownerFileRule returns the prefix rule for ~$ files, or nil when owner
+// files are not reported
+func (v *Validator) ownerFileRule() *config.PrefixRule {
+	for i := range v.config.SPOLimits.PrefixRules {
+		if rule := &v.config.SPOLimits.PrefixRules[i]; rule.Prefix == ownerFilePrefix && rule.AppliesToItem(false) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// ownerDocument returns the name of the Office document in dir that an
+// owner file belongs to, given the owner file's name without ~$, or "" if
+// there is none. Word replaces the first two characters of longer names,
+// so ~$port.docx belongs to Report.docx. Names are compared ignoring case,
+// as Windows does. listings caches the contents of each folder.
+func ownerDocument(dir, suffix string, listings map[string][]os.DirEntry) string {
+	entries, listed := listings[dir]
+	if !listed {
+		entries, _ = os.ReadDir(dir) // An unreadable folder has no document to pair with
+		listings[dir] = entries
+	}
+
+	shortened := ""
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ownerFilePrefix) || !ownerFileExtensions[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		if strings.EqualFold(name, suffix) {
+			return name
+		}
+		if shortened == "" && utf8.RuneCountInString(name) > 2 {
+			_, first := utf8.DecodeRuneInString(name)
+			_, second := utf8.DecodeRuneInString(name[first:])
+			if strings.EqualFold(name[first+second:], suffix) {
+				shortened = name
+			}
+		}
+	}
+	return shortened
+}
+
+// checkOwnerFiles reports the ~$ owner files Office keeps next to an open
+// document. They do not sync and are skipped by migration tools, so the
+// ~$ prefix rule makes them Info by default, but when the document is in
+// the same folder it was probably open during the scan and may be locked
+// or have unsaved changes.
+func (v *Validator) checkOwnerFiles() []models.Issue {
+	var issues []models.Issue
+	if len(v.ownerFiles) == 0 {
+		return issues
+	}
+	severity := models.Severity(v.ownerFileRule().Severity)
+
+	sort.Slice(v.ownerFiles, func(i, j int) bool {
+		return v.ownerFiles[i].Path < v.ownerFiles[j].Path
+	})
+
+	listings := make(map[string][]os.DirEntry)
+	for _, item := range v.ownerFiles {
+		suffix := strings.TrimPrefix(item.Name, ownerFilePrefix)
+		id := msgOwnerFileOrphan
+		document := ownerDocument(filepath.Dir(item.Path), suffix, listings)
+		if document != "" {
+			id = msgOwnerFileOpen
+		}
+
+		text := v.text(id)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueInvalidCharacters,
+			Severity:        severity,
+			Message:         text.Message,
+			MessageID:       id,
+			Details:         formatMessage(text.Details, document),
+			IsDirectory:     false,
+			RemediationHint: text.Hint,
+		})
+	}
+
+	return issues
+}
+
+// checkControlCharacters flags names containing newlines and other control
+// characters. They can be created on Linux and macOS shares but SharePoint
+// rejects them, and they corrupt line-based tools that read the name.
+// Control characters already listed as invisible characters are left to
+// checkInvisibleCharacters.
+func (v *Validator) checkControlCharacters(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+	var codePoints []string
+	seen := make(map[rune]bool)
+
+	for _, ch := range item.Name {
+		if !unicode.IsControl(ch) || seen[ch] {
+			continue
+		}
+		if v.config.SPOLimits.InvisibleCharsSet[ch] || v.config.SPOLimits.InvalidCharsSet[ch] {
+			continue
+		}
+		seen[ch] = true
+		codePoints = append(codePoints, formatCodePoint(ch))
+	}
+
+	if len(codePoints) == 0 {
+		return issues
+	}
+
+	codePointList := strings.Join(codePoints, " ")
+	text := v.text(msgControlChars)
+	issues = append(issues, models.Issue{
+		Path:            item.Path,
+		Type:            models.IssueInvalidCharacters,
+		Severity:        models.SeverityCritical,
+		Message:         text.Message,
+		MessageID:       msgControlChars,
+		Details:         formatMessage(text.Details, codePointList),
+		IsDirectory:     item.IsDir,
+		RemediationHint: v.withSuggestedName(formatRemediationHint(text.Hint, codePointList), item),
+	})
+
+	return issues
+}
+
+// checkInvisibleCharacters detects zero-width and other invisible code
+// points that render fine locally but are normalized away or renamed
+// during migration, causing collisions.
+func (v *Validator) checkInvisibleCharacters(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+	var codePoints []string
+	seen := make(map[rune]bool)
+	visible := false
+
+	for _, ch := range item.Name {
+		if !v.config.SPOLimits.InvisibleCharsSet[ch] {
+			visible = true
+			continue
+		}
+		if !seen[ch] {
+			seen[ch] = true
+			codePoints = append(codePoints, formatCodePoint(ch))
+		}
+	}
+
+	if len(codePoints) == 0 {
+		return issues
+	}
+
+	codePointList := strings.Join(codePoints, " ")
+
+	if !visible {
+		text := v.text(msgInvisibleOnly)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueInvalidCharacters,
+			Severity:        models.SeverityCritical,
+			Message:         text.Message,
+			MessageID:       msgInvisibleOnly,
+			Details:         formatMessage(text.Details, codePointList),
+			IsDirectory:     item.IsDir,
+			RemediationHint: text.Hint,
+		})
+		return issues
+	}
+
+	text := v.text(msgInvisibleChars)
+	issues = append(issues, models.Issue{
+		Path:            item.Path,
+		Type:            models.IssueInvalidCharacters,
+		Severity:        models.SeverityWarning,
+		Message:         text.Message,
+		MessageID:       msgInvisibleChars,
+		Details:         formatMessage(text.Details, codePointList),
+		IsDirectory:     item.IsDir,
+		RemediationHint: formatRemediationHint(text.Hint, codePointList),
+	})
+
+	return issues
+}
+
+// checkBlankName reports names made only of dots and whitespace, such as
+// "..." or "   ", which SharePoint cannot store because they are empty once
+// trailing dots and spaces are trimmed. The whole name is checked, so
+// "...txt" is a valid name rather than a blank "..." with an extension.
+func (v *Validator) checkBlankName(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+
+	if !v.isBlankName(item.Name) {
+		return issues
+	}
+
+	text := v.text(msgBlankName)
+	issues = append(issues, models.Issue{
+		Path:            item.Path,
+		Type:            models.IssueInvalidCharacters,
+		Severity:        models.SeverityCritical,
+		Message:         text.Message,
+		MessageID:       msgBlankName,
+		Details:         formatMessage(text.Details, strconv.Quote(item.Name)),
+		IsDirectory:     item.IsDir,
+		RemediationHint: text.Hint,
+	})
+
+	return issues
+}
+
+// isBlankName reports whether name has at least one dot or whitespace
+// character and nothing else but invisible characters. Names made only of
+// invisible characters are left to checkInvisibleCharacters.
+func (v *Validator) isBlankName(name string) bool {
+	blank := false
+	for _, ch := range name {
+		switch {
+		case ch == '.' || unicode.IsSpace(ch):
+			blank = true
+		case !v.config.SPOLimits.InvisibleCharsSet[ch]:
+			return false
+		}
+	}
+	return blank
+}
+
+// checkReservedNames validates against reserved names. Device names are
+// reserved with any extension, blocked names only as the whole name, and
+// root-level names only for folders at the top of the scan.
+func (v *Validator) checkReservedNames(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+	limits := v.config.SPOLimits
+
+	if device := v.deviceName(item.Name); device != "" {
+		text := v.text(msgReservedName)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueReservedName,
+			Severity:        models.SeverityCritical,
+			Message:         text.Message,
+			MessageID:       msgReservedName,
+			Details:         formatMessage(text.Details, device),
+			IsDirectory:     item.IsDir,
+			RemediationHint: v.withSuggestedName(text.Hint, item),
+		})
+	} else if limits.BlockedNamesSet[strings.ToUpper(item.Name)] {
+		text := v.text(msgBlockedName)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueReservedName,
+			Severity:        models.SeverityCritical,
+			Message:         text.Message,
+			MessageID:       msgBlockedName,
+			Details:         formatMessage(text.Details, item.Name),
+			IsDirectory:     item.IsDir,
+			RemediationHint: text.Hint,
+		})
+	}
+
+	if item.IsDir && isRootLevel(item) && limits.RootLevelBlockedNamesSet[strings.ToUpper(item.Name)] {
+		text := v.text(msgRootLevelName)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueReservedName,
+			Severity:        models.SeverityCritical,
+			Message:         text.Message,
+			MessageID:       msgRootLevelName,
+			Details:         formatMessage(text.Details, item.Name),
+			IsDirectory:     true,
+			RemediationHint: text.Hint,
+		})
+	}
+
+	return issues
+}
+
+// deviceName returns the reserved device name that name uses, such as
+// "CON" for "con.tar.gz", or "" if it uses none. Windows reserves device
+// names with any extension, so only the part before the first dot counts.
+func (v *Validator) deviceName(name string) string {
+	stem := name
+	if i := strings.Index(name, "."); i >= 0 {
+		stem = name[:i]
+	}
+	if v.config.SPOLimits.ReservedNamesSet[strings.ToUpper(stem)] {
+		return stem
+	}
+	return ""
+}
+
+// isRootLevel reports whether item is directly inside the scan root
+func isRootLevel(item *models.FileSystemItem) bool {
+	rel := canonicalRelativePath(item.RelativePath)
+	return rel != "" && !strings.Contains(rel, "/")
+}
+
+// checkBlockedFileTypes validates against blocked file extensions
+func (v *Validator) checkBlockedFileTypes(item *models.FileSystemItem, ext string) []models.Issue {
+	var issues []models.Issue
+
+	// Check extensions blocked for this run
+	if v.config.BlockedFileTypes.Custom.ExtensionsSet[ext] {
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueBlockedFileType,
+			Severity:        models.SeverityWarning,
+			Message:         v.ruleMessage(msgBlockedCustom, v.config.BlockedFileTypes.Custom.Message),
+			MessageID:       msgBlockedCustom,
+			Category:        "Blocked - Custom",
+			Size:            item.Size,
+			IsDirectory:     false,
+			RemediationHint: v.text(msgBlockedCustom).Hint,
+		})
+		return issues
+	}
+
+	// Check executables
+	if v.config.BlockedFileTypes.Executables.ExtensionsSet[ext] {
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueBlockedFileType,
+			Severity:        models.SeverityWarning,
+			Message:         v.ruleMessage(msgBlockedExecutable, v.config.BlockedFileTypes.Executables.Message),
+			MessageID:       msgBlockedExecutable,
+			Category:        "Blocked - Executable",
+			Size:            item.Size,
+			IsDirectory:     false,
+			RemediationHint: v.text(msgBlockedExecutable).Hint,
+		})
+		return issues
+	}
+
+	// Check scripts
+	if v.config.BlockedFileTypes.Scripts.ExtensionsSet[ext] {
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueBlockedFileType,
+			Severity:        models.SeverityWarning,
+			Message:         v.ruleMessage(msgBlockedScript, v.config.BlockedFileTypes.Scripts.Message),
+			MessageID:       msgBlockedScript,
+			Category:        "Blocked - Script",
+			Size:            item.Size,
+			IsDirectory:     false,
+			RemediationHint: v.text(msgBlockedScript).Hint,
+		})
+		return issues
+	}
+
+	// Check system files
+	if v.config.BlockedFileTypes.System.ExtensionsSet[ext] {
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueBlockedFileType,
+			Severity:        models.SeverityWarning,
+			Message:         v.ruleMessage(msgBlockedSystem, v.config.BlockedFileTypes.System.Message),
+			MessageID:       msgBlockedSystem,
+			Category:        "Blocked - System",
+			Size:            item.Size,
+			IsDirectory:     false,
+			RemediationHint: v.text(msgBlockedSystem).Hint,
+		})
+		return issues
+	}
+
+	// Check dangerous file types
+	if v.config.BlockedFileTypes.Dangerous.ExtensionsSet[ext] {
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueBlockedFileType,
+			Severity:        models.SeverityWarning,
+			Message:         v.ruleMessage(msgBlockedDangerous, v.config.BlockedFileTypes.Dangerous.Message),
+			MessageID:       msgBlockedDangerous,
+			Category:        "Blocked - Potentially Dangerous",
+			Size:            item.Size,
+			IsDirectory:     false,
+			RemediationHint: v.text(msgBlockedDangerous).Hint,
+		})
+		return issues
+	}
+
+	return issues
+}
+
+// checkProblematicFiles validates against files with known issues. Files
+// no larger than settings.problematicMinSizeBytes are not reported; the
+// separate secrets check applies at any size.
+func (v *Validator) checkProblematicFiles(item *models.FileSystemItem, ext string) []models.Issue {
+	if floor := v.config.Settings.ProblematicMinSizeBytes; floor > 0 && item.Size <= floor {
+		return nil
+	}
+
+	var issues []models.Issue
+
+	// Check CAD files
+	if v.config.ProblematicFiles.CAD.ExtensionsSet[ext] {
+		issues = append(issues, models.Issue{
+			Path:        item.Path,
+			Type:        models.IssueProblematicFile,
+			Severity:    models.SeverityWarning,
+			Message:     v.ruleMessage(msgCAD, v.config.ProblematicFiles.CAD.Message),
+			MessageID:   msgCAD,
+			Category:    v.config.ProblematicFiles.CAD.Category,
+			Size:        item.Size,
+			IsDirectory: false,
+		})
+		return issues
+	}
+
+	// Check Adobe files
+	if v.config.ProblematicFiles.Adobe.ExtensionsSet[ext] {
+		issues = append(issues, models.Issue{
+			Path:        item.Path,
+			Type:        models.IssueProblematicFile,
+			Severity:    models.SeverityWarning,
+			Message:     v.ruleMessage(msgAdobe, v.config.ProblematicFiles.Adobe.Message),
+			MessageID:   msgAdobe,
+			Category:    v.config.ProblematicFiles.Adobe.Category,
+			Size:        item.Size,
+			IsDirectory: false,
+		})
+		return issues
+	}
+
+	// Check database files
+	if v.config.ProblematicFiles.Database.ExtensionsSet[ext] {
+		issues = append(issues, models.Issue{
+			Path:        item.Path,
+			Type:        models.IssueProblematicFile,
+			Severity:    models.SeverityWarning,
+			Message:     v.ruleMessage(msgDatabase, v.config.ProblematicFiles.Database.Message),
+			MessageID:   msgDatabase,
+			Category:    v.config.ProblematicFiles.Database.Category,
+			Size:        item.Size,
+			IsDirectory: false,
+		})
+		return issues
+	}
+
+	// Check email archives (with size warning)
+	if v.config.ProblematicFiles.EmailArchive.ExtensionsSet[ext] {
+		severity := models.SeverityWarning
+		if item.Size > v.config.ProblematicFiles.EmailArchive.SizeWarningBytes {
+			severity = models.SeverityCritical
+		}
+		issues = append(issues, models.Issue{
+			Path:        item.Path,
+			Type:        models.IssueProblematicFile,
+			Severity:    severity,
+			Message:     v.ruleMessage(msgEmailArchive, v.config.ProblematicFiles.EmailArchive.Message),
+			MessageID:   msgEmailArchive,
+			Category:    v.config.ProblematicFiles.EmailArchive.Category,
+			Size:        item.Size,
+			IsDirectory: false,
+		})
+		return issues
+	}
+
+	// Check large media files
+	if v.config.ProblematicFiles.LargeMedia.ExtensionsSet[ext] {
+		if item.Size > v.config.ProblematicFiles.LargeMedia.SizeThresholdBytes {
+			issues = append(issues, models.Issue{
+				Path:        item.Path,
+				Type:        models.IssueProblematicFile,
+				Severity:    models.SeverityInfo,
+				Message:     v.ruleMessage(msgLargeMedia, v.config.ProblematicFiles.LargeMedia.Message),
+				MessageID:   msgLargeMedia,
+				Category:    v.config.ProblematicFiles.LargeMedia.Category,
+				Size:        item.Size,
+				IsDirectory: false,
+			})
+		}
+		return issues
+	}
+
+	// Check virtual machine files
+	if v.config.ProblematicFiles.VirtualMachine.ExtensionsSet[ext] {
+		issues = append(issues, models.Issue{
+			Path:        item.Path,
+			Type:        models.IssueProblematicFile,
+			Severity:    models.SeverityWarning,
+			Message:     v.ruleMessage(msgVirtualMachine, v.config.ProblematicFiles.VirtualMachine.Message),
+			MessageID:   msgVirtualMachine,
+			Category:    v.config.ProblematicFiles.VirtualMachine.Category,
+			Size:        item.Size,
+			IsDirectory: false,
+		})
+		return issues
+	}
+
+	// Check backup files
+	if v.config.ProblematicFiles.Backup.ExtensionsSet[ext] {
+		if item.Size > v.config.ProblematicFiles.Backup.SizeThresholdBytes {
+			issues = append(issues, models.Issue{
+				Path:        item.Path,
+				Type:        models.IssueProblematicFile,
+				Severity:    models.SeverityInfo,
+				Message:     v.ruleMessage(msgBackup, v.config.ProblematicFiles.Backup.Message),
+				MessageID:   msgBackup,
+				Category:    v.config.ProblematicFiles.Backup.Category,
+				Size:        item.Size,
+				IsDirectory: false,
+			})
+		}
+		return issues
+	}
+
+	// Check OneNote files
+	if v.config.ProblematicFiles.OneNote.ExtensionsSet[ext] {
+		issues = append(issues, models.Issue{
+			Path:        item.Path,
+			Type:        models.IssueProblematicFile,
+			Severity:    models.SeverityInfo,
+			Message:     v.ruleMessage(msgOneNote, v.config.ProblematicFiles.OneNote.Message),
+			MessageID:   msgOneNote,
+			Category:    v.config.ProblematicFiles.OneNote.Category,
+			Size:        item.Size,
+			IsDirectory: false,
+		})
+		return issues
+	}
+
+	// Check other file types, escalating large ones
+	if rule, exists := v.config.ProblematicFiles.Other[ext]; exists {
+		severity := models.SeverityInfo
+		if rule.SizeThresholdBytes > 0 && item.Size > rule.SizeThresholdBytes {
+			severity = models.SeverityWarning
+			if rule.SizeSeverity != "" {
+				severity = models.Severity(rule.SizeSeverity)
+			}
+		}
+		issues = append(issues, models.Issue{
+			Path:        item.Path,
+			Type:        models.IssueProblematicFile,
+			Severity:    severity,
+			Message:     v.ruleMessage(msgOtherProblematic, rule.Message),
+			MessageID:   msgOtherProblematic,
+			Category:    "Other",
+			Size:        item.Size,
+			IsDirectory: false,
+		})
+		return issues
+	}
+
+	return issues
+}
+
+// checkSecrets flags files whose names suggest keys or credentials. Only
+// the name is matched; file contents are never read.
+func (v *Validator) checkSecrets(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+	nameLower := strings.ToLower(item.Name)
+	for pattern := range v.config.ProblematicFiles.Secrets.PatternsSet {
+		if matchesPattern(nameLower, strings.ToLower(pattern)) {
+			issues = append(issues, models.Issue{
+				Path:        item.Path,
+				Type:        models.IssueProblematicFile,
+				Severity:    models.SeverityWarning,
+				Message:     v.ruleMessage(msgSecrets, v.config.ProblematicFiles.Secrets.Message),
+				MessageID:   msgSecrets,
+				Category:    models.CategorySecrets,
+				Size:        item.Size,
+				IsDirectory: false,
+			})
+			break
+		}
+	}
+
+	return issues
+}
+
+// withoutAccepted drops Warning and Info issues in the problematic-file
+// categories accepted with -accept-category or settings.acceptedCategories
+func (v *Validator) withoutAccepted(issues []models.Issue) []models.Issue {
+	accepted := v.config.Settings.AcceptedCategorySet
+	if len(accepted) == 0 {
+		return issues
+	}
+
+	var kept []models.Issue
+	for _, issue := range issues {
+		if issue.Severity != models.SeverityCritical && accepted[issue.Category] {
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+// innerExtensionOuters are extensions that legitimately follow an
+// executable or script extension, such as app.exe.config next to a .NET
+// program or bundle.js.map for a source map
+var innerExtensionOuters = map[string]bool{
+	".config": true, ".manifest": true, ".map": true, ".mui": true,
+}
+
+// checkDoubleExtensions flags files with an executable or script
+// extension before the last one, such as report.exe.txt, a common way to
+// get an executable past filters that only look at the final extension.
+// Inner parts that are not executable, as in archive.tar.gz, are fine.
+func (v *Validator) checkDoubleExtensions(item *models.FileSystemItem, ext string) []models.Issue {
+	var issues []models.Issue
+
+	parts := strings.Split(item.Name, ".")
+	if len(parts) < 3 || innerExtensionOuters[ext] {
+		return issues
+	}
+
+	blocked := v.config.BlockedFileTypes
+	for _, part := range parts[1 : len(parts)-1] {
+		inner := "." + strings.ToLower(part)
+		if !blocked.Executables.ExtensionsSet[inner] && !blocked.Scripts.ExtensionsSet[inner] {
+			continue
+		}
+
+		text := v.text(msgDoubleExtension)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueBlockedFileType,
+			Severity:        models.SeverityWarning,
+			Message:         text.Message,
+			MessageID:       msgDoubleExtension,
+			Details:         formatMessage(text.Details, inner),
+			Category:        "Blocked - Double Extension",
+			Size:            item.Size,
+			IsDirectory:     false,
+			RemediationHint: text.Hint,
+		})
+		break
+	}
+
+	return issues
+}
+
+// sizeTierMessages gives the message for a file size tier by severity
+var sizeTierMessages = map[string]string{
+	"Critical": msgSizeCritical,
+	"Warning":  msgSizeHuge,
+	"Info":     msgSizeLarge,
+}
+
+// checkFileSize reports files over the upload limit, or else over the
+// largest configured size tier they exceed
+func (v *Validator) checkFileSize(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+
+	// Check max file size
+	if item.Size > v.config.SPOLimits.MaxFileSizeBytes {
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueFileSize,
+			Severity:        models.SeverityCritical,
+			Message:         v.text(msgSizeOverLimit).Message,
+			MessageID:       msgSizeOverLimit,
+			Details:         formatSize(item.Size),
+			Size:            item.Size,
+			IsDirectory:     false,
+			RemediationHint: v.text(msgSizeOverLimit).Hint,
+		})
+		return issues
+	}
+
+	// Tiers are sorted largest first; only the first one exceeded applies
+	for _, tier := range v.config.Settings.FileSizeWarnings {
+		if item.Size <= tier.Bytes {
+			continue
+		}
+		id := sizeTierMessages[tier.Severity]
+		text := v.text(id)
+		threshold := formatSize(tier.Bytes)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueFileSize,
+			Severity:        models.Severity(tier.Severity),
+			Message:         text.Message,
+			MessageID:       id,
+			Details:         formatMessage(text.Details, formatSize(item.Size), threshold),
+			Size:            item.Size,
+			IsDirectory:     false,
+			RemediationHint: formatRemediationHint(text.Hint, threshold),
+		})
+		break
+	}
+
+	return issues
+}
+
+// checkHiddenFiles validates hidden and system files
+func (v *Validator) checkHiddenFiles(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+
+	if item.IsHidden {
+		text := v.text(msgHidden)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueHiddenFile,
+			Severity:        models.SeverityInfo,
+			Message:         text.Message,
+			MessageID:       msgHidden,
+			Details:         text.Details,
+			IsDirectory:     item.IsDir,
+			RemediationHint: text.Hint,
+		})
+	}
+
+	if item.IsSystem {
+		text := v.text(msgSystem)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueSystemFile,
+			Severity:        models.SeverityWarning,
+			Message:         text.Message,
+			MessageID:       msgSystem,
+			Details:         text.Details,
+			IsDirectory:     item.IsDir,
+			RemediationHint: text.Hint,
+		})
+	}
+
+	return issues
+}
+
+// checkReparsePoints reports symbolic links, mount points, deduplicated
+// files and cloud placeholders, which migration tools do not copy as
+// ordinary files
+func (v *Validator) checkReparsePoints(item *models.FileSystemItem) []models.Issue {
+	issue := models.Issue{
+		Path:        item.Path,
+		Type:        models.IssueReparsePoint,
+		Category:    item.ReparseType,
+		IsDirectory: item.IsDir,
+	}
+
+	switch item.ReparseType {
+	case models.ReparseSymlink:
+		issue.Severity = models.SeverityWarning
+		issue.MessageID = msgSymlink
+	case models.ReparseMountPoint:
+		issue.Severity = models.SeverityWarning
+		issue.MessageID = msgMountPoint
+	case models.ReparseDedup:
+		issue.Severity = models.SeverityInfo
+		issue.Size = item.Size
+		issue.MessageID = msgDedup
+	case models.ReparseCloud:
+		issue.Severity = models.SeverityWarning
+		issue.Size = item.Size
+		issue.MessageID = msgCloud
+	default:
+		issue.Severity = models.SeverityWarning
+		issue.MessageID = msgReparseOther
+	}
+
+	text := v.text(issue.MessageID)
+	issue.Message = text.Message
+	issue.Details = text.Details
+	issue.RemediationHint = text.Hint
+
+	return []models.Issue{issue}
+}
+
+// contentTypeExtensions lists the extensions each sniffed content type may
+// be saved under, preferred extension first
+var contentTypeExtensions = map[string][]string{
+	models.ContentTypePDF:        {".pdf", ".ai"},
+	models.ContentTypePNG:        {".png"},
+	models.ContentTypeJPEG:       {".jpg", ".jpeg", ".jpe", ".jfif"},
+	models.ContentTypeDWG:        {".dwg", ".dwt", ".dws"},
+	models.ContentTypeWord:       {".docx", ".docm", ".dotx", ".dotm"},
+	models.ContentTypeExcel:      {".xlsx", ".xlsm", ".xltx", ".xltm", ".xlam"},
+	models.ContentTypePowerPoint: {".pptx", ".pptm", ".potx", ".potm", ".ppsx", ".ppsm", ".ppam"},
+	models.ContentTypeZIP: {
+		".zip", ".jar", ".war", ".ear", ".apk", ".aar", ".epub", ".odt", ".ods", ".odp", ".odg",
+		".vsdx", ".vsdm", ".xps", ".oxps", ".nupkg", ".vsix", ".xpi", ".kmz", ".3mf", ".idml",
+		".sketch", ".whl", ".appx", ".msix", ".crx", ".cbz", ".pbix", ".twbx", ".qgz",
+	},
+}
+
+// checkExtensionMismatch flags files whose sniffed content does not match
+// their extension, such as a CAD drawing renamed to .bak. Rules for
+// blocked and problematic types are applied by extension, so such files
+// slip past them.
+func (v *Validator) checkExtensionMismatch(item *models.FileSystemItem, ext string) []models.Issue {
+	var issues []models.Issue
+
+	extensions := contentTypeExtensions[item.ContentType]
+	if len(extensions) == 0 {
+		return issues
+	}
+	for _, allowed := range extensions {
+		if ext == allowed {
+			return issues
+		}
+	}
+
+	if ext == "" {
+		text := v.text(msgNoExtension)
+		issues = append(issues, models.Issue{
+			Path:            item.Path,
+			Type:            models.IssueExtensionMismatch,
+			Severity:        models.SeverityInfo,
+			Message:         text.Message,
+			MessageID:       msgNoExtension,
+			Details:         formatMessage(text.Details, item.ContentType),
+			Category:        item.ContentType,
+			Size:            item.Size,
+			IsDirectory:     false,
+			RemediationHint: formatRemediationHint(text.Hint, extensions[0]),
+		})
+		return issues
+	}
+
+	text := v.text(msgExtensionMismatch)
+	issues = append(issues, models.Issue{
+		Path:            item.Path,
+		Type:            models.IssueExtensionMismatch,
+		Severity:        models.SeverityInfo,
+		Message:         text.Message,
+		MessageID:       msgExtensionMismatch,
+		Details:         formatMessage(text.Details, ext, item.ContentType),
+		Category:        item.ContentType,
+		Size:            item.Size,
+		IsDirectory:     false,
+		RemediationHint: formatRemediationHint(text.Hint, item.ContentType, extensions[0]),
+	})
+
+	return issues
+}
+
+// checkFileLocks reports files that were open in another process
+func (v *Validator) checkFileLocks(item *models.FileSystemItem) []models.Issue {
+	text := v.text(msgFileInUse)
+	return []models.Issue{{
+		Path:            item.Path,
+		Type:            models.IssueFileLocked,
+		Severity:        models.SeverityWarning,
+		Message:         text.Message,
+		MessageID:       msgFileInUse,
+		Details:         text.Details,
+		Size:            item.Size,
+		IsDirectory:     false,
+		RemediationHint: text.Hint,
+	}}
+}
+
+// checkAlternateStreams reports files with NTFS alternate data streams,
+// which SharePoint does not keep. A file whose only stream is the mark of
+// the web gets its own message since that stream is safe to drop.
+func (v *Validator) checkAlternateStreams(item *models.FileSystemItem) []models.Issue {
+	id := msgStreams
+	if len(item.Streams) == 1 && strings.EqualFold(item.Streams[0], zoneIdentifierStream) {
+		id = msgZoneIdentifier
+	}
+
+	text := v.text(id)
+	return []models.Issue{{
+		Path:            item.Path,
+		Type:            models.IssueAlternateStream,
+		Severity:        models.SeverityInfo,
+		Message:         text.Message,
+		MessageID:       id,
+		Details:         formatMessage(text.Details, strings.Join(item.Streams, ", ")),
+		Size:            item.Size,
+		IsDirectory:     false,
+		RemediationHint: text.Hint,
+	}}
+}
+
+// checkCustomRules validates item names against user-defined regex rules
+func (v *Validator) checkCustomRules(item *models.FileSystemItem) []models.Issue {
+	var issues []models.Issue
+
+	for i := range v.config.CustomRules {
+		rule := &v.config.CustomRules[i]
+		if !rule.AppliesToItem(item.IsDir) {
+			continue
+		}
+
+		loc := rule.Regex.FindStringIndex(item.Name)
+		if loc == nil {
+			continue
+		}
+		match := item.Name[loc[0]:loc[1]]
+
+		text := v.text(msgCustomRule)
+		message := rule.Message
+		if message == "" {
+			message = formatMessage(text.Message, rule.Name)
+		}
+
+		issues = append(issues, models.Issue{
+			Path:        item.Path,
+			Type:        models.IssueCustomRule,
+			Severity:    models.Severity(rule.Severity),
+			Message:     message,
+			MessageID:   msgCustomRule,
+			Details:     formatMessage(text.Details, rule.Pattern, match),
+			Category:    rule.Name,
+			IsDirectory: item.IsDir,
+		})
+	}
+
+	return issues
+}
+
+// trackCaseConflicts records an item under its case-folded relative path
+func (v *Validator) trackCaseConflicts(item *models.FileSystemItem) {
+	key := strings.ToLower(canonicalRelativePath(item.RelativePath))
+
+	v.mu.Lock()
+	v.caseGroups[key] = append(v.caseGroups[key], item)
+	v.mu.Unlock()
+}
+
+// checkCaseConflicts flags relative paths anywhere in the tree that differ
+// only by letter case. Unlike NameConflicts this is not limited to
+// siblings in the same folder.
+func (v *Validator) checkCaseConflicts() []models.Issue {
+	var issues []models.Issue
+
+	keys := make([]string, 0, len(v.caseGroups))
+	for key, group := range v.caseGroups {
+		if len(group) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		group := v.caseGroups[key]
+
+		casings := make(map[string]bool)
+		for _, item := range group {
+			casings[canonicalRelativePath(item.RelativePath)] = true
+		}
+		if len(casings) < 2 {
+			continue
+		}
+
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].RelativePath < group[j].RelativePath
+		})
+
+		for _, item := range group {
+			var others []string
+			for _, other := range group {
+				if other != item {
+					others = append(others, canonicalRelativePath(other.RelativePath))
+				}
+			}
+
+			text := v.text(msgCaseConflict)
+			issues = append(issues, models.Issue{
+				Path:            item.Path,
+				Type:            models.IssueCaseConflict,
+				Severity:        models.SeverityWarning,
+				Message:         text.Message,
+				MessageID:       msgCaseConflict,
+				Details:         formatMessage(text.Details, strings.Join(others, ", ")),
+				IsDirectory:     item.IsDir,
+				RemediationHint: text.Hint,
+			})
+		}
+	}
+
+	return issues
+}
+
+// sharePointName returns the form of a name SharePoint compares: trailing
+// dots and spaces trimmed, as SharePoint trims them from each name, and
+// letter case folded. "Data." and "data" are both "data". Only the end of
+// the whole name is trimmed, so "Report .docx" keeps its space.
+func sharePointName(name string) string {
+	return strings.ToLower(strings.TrimRight(name, ". "))
+}
+
+// trackNameConflicts compares an item with the siblings listed before it
+// and keeps it only if it shares their SharePoint name. Folders the walk
+// has left are dropped.
+func (v *Validator) trackNameConflicts(item *models.FileSystemItem) {
+	name := sharePointName(item.Name)
+	if name == "" {
+		return // Blank names are reported on their own
+	}
+	folder := strings.ToLower(path.Dir(canonicalRelativePath(item.RelativePath)))
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for n := len(v.nameListing); n > 0; n-- {
+		dir := v.nameListing[n-1].dir
+		if dir == folder || dir == "." || strings.HasPrefix(folder, dir+"/") {
+			break
+		}
+		v.nameListing[n-1] = nil
+		v.nameListing = v.nameListing[:n-1]
+	}
+	n := len(v.nameListing)
+	if n == 0 || v.nameListing[n-1].dir != folder {
+		v.nameListing = append(v.nameListing, &nameFolder{dir: folder, names: make(map[string]nameEntry)})
+		n++
+	}
+	names := v.nameListing[n-1].names
+
+	entry := nameEntry{name: item.Name, path: item.Path, isDir: item.IsDir}
+	first, seen := names[name]
+	if !seen {
+		names[name] = entry
+		return
+	}
+	key := folder + "/" + name
+	if len(v.nameConflicts[key]) == 0 {
+		v.nameConflicts[key] = []nameEntry{first}
+	}
+	v.nameConflicts[key] = append(v.nameConflicts[key], entry)
+}
+
+// checkNameConflicts flags items in the same folder whose names become
+// the same in SharePoint, such as "Data." and "Data" or "Notes " and
+// "notes", since only one of them can be uploaded. Names that differ only
+// by case are left to CaseConflicts when that check is on.
+func (v *Validator) checkNameConflicts() []models.Issue {
+	var issues []models.Issue
+
+	keys := make([]string, 0, len(v.nameConflicts))
+	for key := range v.nameConflicts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		group := v.nameConflicts[key]
+
+		if v.enabledChecks["CaseConflicts"] {
+			spellings := make(map[string]bool)
+			for _, entry := range group {
+				spellings[strings.ToLower(entry.name)] = true
+			}
+			if len(spellings) < 2 {
+				continue
+			}
+		}
+
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].name < group[j].name
+		})
+
+		name := sharePointName(group[0].name)
+		for i, entry := range group {
+			var others []string
+			for j, other := range group {
+				if j != i {
+					others = append(others, "'"+other.name+"'") // Quoted to show trailing spaces
+				}
+			}
 
-		if v.enabledChecks["FileSize"] {
-			issues = append(issues, v.checkFileSize(item)...)
+			text := v.text(msgNameConflict)
+			issues = append(issues, models.Issue{
+				Path:            entry.path,
+				Type:            models.IssueNameConflict,
+				Severity:        models.SeverityWarning,
+				Message:         text.Message,
+				MessageID:       msgNameConflict,
+				Details:         formatMessage(text.Details, strings.Join(others, ", "), name),
+				IsDirectory:     entry.isDir,
+				RemediationHint: text.Hint,
+			})
 		}
 	}
 
-	if v.enabledChecks["HiddenFiles"] && (item.IsHidden || item.IsSystem) {
-		issues = append(issues, v.checkHiddenFiles(item)...)
-	}
-
 	return issues
 }
 
-// checkPathLength validates path length constraints
-func (v *Validator) checkPathLength(item *models.FileSystemItem) []models.Issue {
+// trackConfusableNames records an item under its folder and the
+// confusable skeleton of its name
+func (v *Validator) trackConfusableNames(item *models.FileSystemItem) {
+	folder := strings.ToLower(path.Dir(canonicalRelativePath(item.RelativePath)))
+	key := folder + "/" + confusableSkeleton(item.Name)
+
+	v.mu.Lock()
+	v.lookalikeGroups[key] = append(v.lookalikeGroups[key], item)
+	v.mu.Unlock()
+}
+
+// checkConfusableNames flags names in the same folder that look the same
+// but are spelled with lookalike letters from another script, such as a
+// Cyrillic "а" in place of a Latin "a". Names that differ only by case
+// are left to CaseConflicts. The check is advisory: the names are valid
+// and may be intended.
+func (v *Validator) checkConfusableNames() []models.Issue {
 	var issues []models.Issue
 
-	// Check individual file/folder name length
-	if len(item.Name) > v.config.SPOLimits.MaxFileNameLength {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssuePathLength,
-			Severity: models.SeverityCritical,
-			Message:  "File or folder name exceeds 255 character limit",
-			Details:  formatLength(len(item.Name), v.config.SPOLimits.MaxFileNameLength),
-			IsDirectory: item.IsDir,
-			RemediationHint: formatRemediationHint("Rename to 255 characters or fewer. Current length: %d chars.", len(item.Name)),
-		})
+	keys := make([]string, 0, len(v.lookalikeGroups))
+	for key, group := range v.lookalikeGroups {
+		if len(group) > 1 {
+			keys = append(keys, key)
+		}
 	}
+	sort.Strings(keys)
 
-	// Calculate URL-encoded path length
-	relativePath := item.RelativePath
-	if relativePath == "." {
-		relativePath = ""
-	}
-	encodedPath := urlEncodePath(relativePath)
-	totalLength := v.destinationPathLen
-	if totalLength > 0 && encodedPath != "" {
-		totalLength++
-	}
-	totalLength += len(encodedPath)
+	for _, key := range keys {
+		group := v.lookalikeGroups[key]
 
-	maxLength := v.config.SPOLimits.MaxPathLength
+		spellings := make(map[string]bool)
+		for _, item := range group {
+			spellings[strings.ToLower(item.Name)] = true
+		}
+		if len(spellings) < 2 {
+			continue
+		}
 
-	// Check if exceeds limit
-	if totalLength > maxLength {
-		overBy := totalLength - maxLength
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssuePathLength,
-			Severity: models.SeverityCritical,
-			Message:  "Path exceeds 400 character limit",
-			Details:  formatLength(totalLength, maxLength),
-			IsDirectory: item.IsDir,
-			RemediationHint: formatRemediationHint("Shorten path by at least %d characters. Consider shortening folder names or reducing nesting depth.", overBy),
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].Name < group[j].Name
 		})
-	} else {
-		// Check if approaching limit (warning threshold)
-		thresholdPercent := v.config.Settings.PathWarningThresholdPercent
-		warningThreshold := (maxLength * thresholdPercent) / 100
 
-		if totalLength >= warningThreshold {
-			remaining := maxLength - totalLength
-			percentUsed := (totalLength * 100) / maxLength
+		seen := make(map[rune]bool)
+		var chars []string
+		for _, item := range group {
+			chars = append(chars, lookalikes(item.Name, seen)...)
+		}
+		charList := strings.Join(chars, ", ")
+
+		for _, item := range group {
+			var others []string
+			for _, other := range group {
+				if strings.ToLower(other.Name) != strings.ToLower(item.Name) {
+					others = append(others, other.Name)
+				}
+			}
+
+			text := v.text(msgConfusableName)
 			issues = append(issues, models.Issue{
-				Path:     item.Path,
-				Type:     models.IssuePathLength,
-				Severity: models.SeverityWarning,
-				Message:  formatMessage("Path is at %d%% of 400 character limit", percentUsed),
-				Details:  formatLength(totalLength, maxLength),
-				IsDirectory: item.IsDir,
-				RemediationHint: formatRemediationHint("Only %d characters remaining. Consider shortening path to provide buffer for future growth.", remaining),
+				Path:            item.Path,
+				Type:            models.IssueConfusableName,
+				Severity:        models.SeverityInfo,
+				Message:         text.Message,
+				MessageID:       msgConfusableName,
+				Details:         formatMessage(text.Details, strings.Join(others, ", "), charList),
+				IsDirectory:     item.IsDir,
+				RemediationHint: text.Hint,
 			})
 		}
 	}
@@ -139,72 +1989,94 @@ func (v *Validator) checkPathLength(item *models.FileSystemItem) []models.Issue
 	return issues
 }
 
-// checkInvalidCharacters validates against invalid characters
-func (v *Validator) checkInvalidCharacters(item *models.FileSystemItem) []models.Issue {
+// trackShortNames records an item's name, and its short name if it has
+// one, under its folder
+func (v *Validator) trackShortNames(item *models.FileSystemItem) {
+	folder := strings.ToLower(path.Dir(canonicalRelativePath(item.RelativePath)))
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	f := v.shortNames[folder]
+	if f == nil {
+		f = &shortNameFolder{names: make(map[string][]*models.FileSystemItem)}
+		v.shortNames[folder] = f
+	}
+	name := strings.ToUpper(item.Name)
+	f.names[name] = append(f.names[name], item)
+	if item.ShortName != "" {
+		f.shorts = append(f.shorts, item)
+	}
+}
+
+// checkShortNames flags items whose Windows 8.3 short name is the name of
+// another item in the same folder: a path using the short name opens one
+// item on the file server and the other after migration. Names that only
+// look like generated short names, such as PROGRA~1, are reported as
+// advisory, since they often come from copying or referencing an item by
+// its short name.
+func (v *Validator) checkShortNames() []models.Issue {
 	var issues []models.Issue
-	var foundChars []rune
 
-	for _, ch := range item.Name {
-		if v.config.SPOLimits.InvalidCharsSet[ch] {
-			foundChars = append(foundChars, ch)
-		}
+	folders := make([]string, 0, len(v.shortNames))
+	for folder := range v.shortNames {
+		folders = append(folders, folder)
 	}
+	sort.Strings(folders)
 
-	if len(foundChars) > 0 {
-		charList := formatCharList(foundChars)
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueInvalidCharacters,
-			Severity: models.SeverityCritical,
-			Message:  "Contains invalid characters for SharePoint",
-			Details:  formatMessage("Invalid characters found: %s", charList),
-			IsDirectory: item.IsDir,
-			RemediationHint: formatRemediationHint("Remove or replace these characters: %s", charList),
+	for _, folder := range folders {
+		f := v.shortNames[folder]
+		sort.Slice(f.shorts, func(i, j int) bool {
+			return f.shorts[i].Name < f.shorts[j].Name
 		})
-	}
 
-	// Check for blocked patterns
-	nameLower := strings.ToLower(item.Name)
-	for _, pattern := range v.config.SPOLimits.BlockedPatterns {
-		if strings.Contains(nameLower, strings.ToLower(pattern)) {
-			issues = append(issues, models.Issue{
-				Path:     item.Path,
-				Type:     models.IssueInvalidCharacters,
-				Severity: models.SeverityCritical,
-				Message:  "Contains blocked pattern",
-				Details:  formatMessage("Blocked pattern '%s' found in name", pattern),
-				IsDirectory: item.IsDir,
-				RemediationHint: formatRemediationHint("Remove '%s' from the file/folder name", pattern),
-			})
+		collided := make(map[*models.FileSystemItem]bool)
+		for _, item := range f.shorts {
+			for _, other := range f.names[strings.ToUpper(item.ShortName)] {
+				if other == item {
+					continue
+				}
+				text := v.text(msgShortNameCollision)
+				details := formatMessage(text.Details, item.ShortName, item.Name, other.Name)
+				for _, flagged := range []*models.FileSystemItem{item, other} {
+					issues = append(issues, models.Issue{
+						Path:            flagged.Path,
+						Type:            models.IssueNameConflict,
+						Severity:        models.SeverityWarning,
+						Message:         text.Message,
+						MessageID:       msgShortNameCollision,
+						Details:         details,
+						IsDirectory:     flagged.IsDir,
+						RemediationHint: text.Hint,
+					})
+					collided[flagged] = true
+				}
+			}
 		}
-	}
 
-	// Check for blocked prefixes
-	if !item.IsDir {
-		for _, prefix := range v.config.SPOLimits.BlockedPrefixes.File {
-			if strings.HasPrefix(item.Name, prefix) {
-				issues = append(issues, models.Issue{
-					Path:     item.Path,
-					Type:     models.IssueInvalidCharacters,
-					Severity: models.SeverityWarning,
-					Message:  "File has blocked prefix",
-					Details:  formatMessage("Files starting with '%s' may not sync properly", prefix),
-					IsDirectory: false,
-					RemediationHint: formatRemediationHint("Rename to remove '%s' prefix", prefix),
-				})
-			}
+		names := make([]string, 0, len(f.names))
+		for name := range f.names {
+			names = append(names, name)
 		}
-	} else {
-		for _, prefix := range v.config.SPOLimits.BlockedPrefixes.Folder {
-			if strings.HasPrefix(item.Name, prefix) {
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !looksLikeShortName(name) {
+				continue
+			}
+			for _, item := range f.names[name] {
+				if collided[item] {
+					continue
+				}
+				text := v.text(msgShortNameAlias)
 				issues = append(issues, models.Issue{
-					Path:     item.Path,
-					Type:     models.IssueInvalidCharacters,
-					Severity: models.SeverityWarning,
-					Message:  "Folder has blocked prefix",
-					Details:  formatMessage("Folders starting with '%s' may not sync properly", prefix),
-					IsDirectory: true,
-					RemediationHint: formatRemediationHint("Rename to remove '%s' prefix", prefix),
+					Path:            item.Path,
+					Type:            models.IssueNameConflict,
+					Severity:        models.SeverityInfo,
+					Message:         text.Message,
+					MessageID:       msgShortNameAlias,
+					IsDirectory:     item.IsDir,
+					RemediationHint: text.Hint,
 				})
 			}
 		}
@@ -213,346 +2085,297 @@ func (v *Validator) checkInvalidCharacters(item *models.FileSystemItem) []models
 	return issues
 }
 
-// checkReservedNames validates against reserved names
-func (v *Validator) checkReservedNames(item *models.FileSystemItem) []models.Issue {
-	var issues []models.Issue
-
-	// Get name without extension for files
-	nameToCheck := item.Name
-	if !item.IsDir {
-		nameToCheck = strings.TrimSuffix(item.Name, filepath.Ext(item.Name))
+// looksLikeShortName reports whether a name has the form of a generated
+// 8.3 short name: up to eight characters ending in a tilde and a number,
+// such as PROGRA~1 or REPORT~12, and an extension of up to three
+func looksLikeShortName(name string) bool {
+	base, ext := name, ""
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		base, ext = name[:i], name[i+1:]
+		if ext == "" || len(ext) > 3 {
+			return false
+		}
+	}
+	tilde := strings.LastIndexByte(base, '~')
+	if tilde < 1 || len(base) > 8 || tilde == len(base)-1 {
+		return false
+	}
+	for _, ch := range base[tilde+1:] {
+		if ch < '0' || ch > '9' {
+			return false
+		}
 	}
+	return !strings.ContainsAny(base[:tilde]+ext, ". ")
+}
 
-	// Check against reserved names (case-insensitive)
-	if v.config.SPOLimits.ReservedNamesSet[strings.ToUpper(nameToCheck)] {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueReservedName,
-			Severity: models.SeverityCritical,
-			Message:  "Uses a reserved name that is not allowed in SharePoint",
-			Details:  formatMessage("'%s' is a reserved name", nameToCheck),
-			IsDirectory: item.IsDir,
-			RemediationHint: "Rename to a different name. Reserved names cannot be used in SharePoint.",
-		})
+// Helper functions
+
+// withSuggestedName appends a suggested safe name to a remediation hint
+// when one can be generated
+func (v *Validator) withSuggestedName(hint string, item *models.FileSystemItem) string {
+	suggested := v.SuggestName(item.Name, item.IsDir)
+	if suggested == "" || suggested == item.Name {
+		return hint
 	}
+	return hint + " " + formatRemediationHint(v.text(msgSuggestedName).Hint, suggested)
+}
 
-	return issues
+// text returns the catalog entry for a message ID in the configured
+// language
+func (v *Validator) text(id string) i18n.Message {
+	return v.config.Messages.Lookup(id)
 }
 
-// checkBlockedFileTypes validates against blocked file extensions
-func (v *Validator) checkBlockedFileTypes(item *models.FileSystemItem, ext string) []models.Issue {
-	var issues []models.Issue
+// ruleMessage returns the catalog's message for a rule whose text comes
+// from the config, or the configured text when the catalog has none
+func (v *Validator) ruleMessage(id, configured string) string {
+	if message := v.text(id).Message; message != "" {
+		return message
+	}
+	return configured
+}
 
-	// Check executables
-	if v.config.BlockedFileTypes.Executables.ExtensionsSet[ext] {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueBlockedFileType,
-			Severity: models.SeverityWarning,
-			Message:  v.config.BlockedFileTypes.Executables.Message,
-			Category: "Blocked - Executable",
-			Size:     item.Size,
-			IsDirectory: false,
-			RemediationHint: "Remove executable files or verify with SharePoint administrator if these files are needed.",
-		})
-		return issues
+// SuggestName returns a name that SharePoint accepts, derived from name by
+// replacing invalid and control characters with the configured replacement, collapsing
+// repeated replacements, removing invisible characters, blocked patterns
+// and prefixes, trimming trailing dots and spaces and renaming reserved
+// names. It returns "" when no valid name can be derived.
+func (v *Validator) SuggestName(name string, isDir bool) string {
+	limits := v.config.SPOLimits
+	replacement := v.config.Settings.NameReplacement
+
+	var b strings.Builder
+	for _, ch := range name {
+		switch {
+		case limits.InvisibleCharsSet[ch]:
+			continue
+		case limits.InvalidCharsSet[ch], unicode.IsControl(ch):
+			b.WriteString(replacement)
+		default:
+			b.WriteRune(ch)
+		}
 	}
+	suggested := b.String()
 
-	// Check scripts
-	if v.config.BlockedFileTypes.Scripts.ExtensionsSet[ext] {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueBlockedFileType,
-			Severity: models.SeverityWarning,
-			Message:  v.config.BlockedFileTypes.Scripts.Message,
-			Category: "Blocked - Script",
-			Size:     item.Size,
-			IsDirectory: false,
-			RemediationHint: "Script files are often blocked for security. Check with SharePoint administrator.",
-		})
-		return issues
+	for _, pattern := range limits.BlockedPatterns {
+		suggested = removeFold(suggested, pattern)
 	}
 
-	// Check system files
-	if v.config.BlockedFileTypes.System.ExtensionsSet[ext] {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueBlockedFileType,
-			Severity: models.SeverityWarning,
-			Message:  v.config.BlockedFileTypes.System.Message,
-			Category: "Blocked - System",
-			Size:     item.Size,
-			IsDirectory: false,
-			RemediationHint: "System files typically cannot be uploaded to SharePoint Online.",
-		})
-		return issues
+	if replacement != "" {
+		for strings.Contains(suggested, replacement+replacement) {
+			suggested = strings.ReplaceAll(suggested, replacement+replacement, replacement)
+		}
 	}
 
-	// Check dangerous file types
-	if v.config.BlockedFileTypes.Dangerous.ExtensionsSet[ext] {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueBlockedFileType,
-			Severity: models.SeverityWarning,
-			Message:  v.config.BlockedFileTypes.Dangerous.Message,
-			Category: "Blocked - Potentially Dangerous",
-			Size:     item.Size,
-			IsDirectory: false,
-			RemediationHint: "This file type may be blocked for security reasons. Verify if needed.",
-		})
-		return issues
+	for _, prefix := range v.blockedPrefixes(isDir) {
+		for prefix != "" && strings.HasPrefix(suggested, prefix) {
+			suggested = strings.TrimPrefix(suggested, prefix)
+		}
 	}
 
-	return issues
-}
+	suggested = strings.TrimLeft(suggested, " ")
+	suggested = strings.TrimRight(suggested, ". ")
 
-// checkProblematicFiles validates against files with known issues
-func (v *Validator) checkProblematicFiles(item *models.FileSystemItem, ext string) []models.Issue {
-	var issues []models.Issue
+	if device := v.deviceName(suggested); device != "" {
+		suggested = device + replacement + suggested[len(device):]
+	}
 
-	// Check CAD files
-	if v.config.ProblematicFiles.CAD.ExtensionsSet[ext] {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueProblematicFile,
-			Severity: models.SeverityWarning,
-			Message:  v.config.ProblematicFiles.CAD.Message,
-			Category: v.config.ProblematicFiles.CAD.Category,
-			Size:     item.Size,
-			IsDirectory: false,
-		})
-		return issues
+	base, ext := suggested, ""
+	if !isDir {
+		ext = filepath.Ext(suggested)
+		base = strings.TrimSuffix(suggested, ext)
 	}
 
-	// Check Adobe files
-	if v.config.ProblematicFiles.Adobe.ExtensionsSet[ext] {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueProblematicFile,
-			Severity: models.SeverityWarning,
-			Message:  v.config.ProblematicFiles.Adobe.Message,
-			Category: v.config.ProblematicFiles.Adobe.Category,
-			Size:     item.Size,
-			IsDirectory: false,
-		})
-		return issues
+	// Shorten the base name, keeping the extension
+	for len(base)+len(ext) > limits.MaxFileNameLength && base != "" {
+		_, size := utf8.DecodeLastRuneInString(base)
+		base = base[:len(base)-size]
 	}
+	suggested = strings.TrimRight(base, ". ") + ext
 
-	// Check database files
-	if v.config.ProblematicFiles.Database.ExtensionsSet[ext] {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueProblematicFile,
-			Severity: models.SeverityWarning,
-			Message:  v.config.ProblematicFiles.Database.Message,
-			Category: v.config.ProblematicFiles.Database.Category,
-			Size:     item.Size,
-			IsDirectory: false,
-		})
-		return issues
+	if !v.isValidName(suggested, isDir) {
+		return ""
 	}
+	return suggested
+}
 
-	// Check email archives (with size warning)
-	if v.config.ProblematicFiles.EmailArchive.ExtensionsSet[ext] {
-		severity := models.SeverityWarning
-		if item.Size > v.config.ProblematicFiles.EmailArchive.SizeWarningBytes {
-			severity = models.SeverityCritical
-		}
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueProblematicFile,
-			Severity: severity,
-			Message:  v.config.ProblematicFiles.EmailArchive.Message,
-			Category: v.config.ProblematicFiles.EmailArchive.Category,
-			Size:     item.Size,
-			IsDirectory: false,
-		})
-		return issues
+// isValidName reports whether name passes the name checks SuggestName
+// corrects for
+func (v *Validator) isValidName(name string, isDir bool) bool {
+	limits := v.config.SPOLimits
+
+	if name == "" || len(name) > limits.MaxFileNameLength {
+		return false
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return false
 	}
 
-	// Check large media files
-	if v.config.ProblematicFiles.LargeMedia.ExtensionsSet[ext] {
-		if item.Size > v.config.ProblematicFiles.LargeMedia.SizeThresholdBytes {
-			issues = append(issues, models.Issue{
-				Path:     item.Path,
-				Type:     models.IssueProblematicFile,
-				Severity: models.SeverityInfo,
-				Message:  v.config.ProblematicFiles.LargeMedia.Message,
-				Category: v.config.ProblematicFiles.LargeMedia.Category,
-				Size:     item.Size,
-				IsDirectory: false,
-			})
+	for _, ch := range name {
+		if limits.InvalidCharsSet[ch] || limits.InvisibleCharsSet[ch] || unicode.IsControl(ch) {
+			return false
 		}
-		return issues
 	}
 
-	// Check virtual machine files
-	if v.config.ProblematicFiles.VirtualMachine.ExtensionsSet[ext] {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueProblematicFile,
-			Severity: models.SeverityWarning,
-			Message:  v.config.ProblematicFiles.VirtualMachine.Message,
-			Category: v.config.ProblematicFiles.VirtualMachine.Category,
-			Size:     item.Size,
-			IsDirectory: false,
-		})
-		return issues
+	nameLower := strings.ToLower(name)
+	for _, pattern := range limits.BlockedPatterns {
+		if strings.Contains(nameLower, strings.ToLower(pattern)) {
+			return false
+		}
 	}
 
-	// Check backup files
-	if v.config.ProblematicFiles.Backup.ExtensionsSet[ext] {
-		if item.Size > v.config.ProblematicFiles.Backup.SizeThresholdBytes {
-			issues = append(issues, models.Issue{
-				Path:     item.Path,
-				Type:     models.IssueProblematicFile,
-				Severity: models.SeverityInfo,
-				Message:  v.config.ProblematicFiles.Backup.Message,
-				Category: v.config.ProblematicFiles.Backup.Category,
-				Size:     item.Size,
-				IsDirectory: false,
-			})
+	for _, prefix := range v.blockedPrefixes(isDir) {
+		if strings.HasPrefix(name, prefix) {
+			return false
 		}
-		return issues
 	}
 
-	// Check OneNote files
-	if v.config.ProblematicFiles.OneNote.ExtensionsSet[ext] {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueProblematicFile,
-			Severity: models.SeverityInfo,
-			Message:  v.config.ProblematicFiles.OneNote.Message,
-			Category: v.config.ProblematicFiles.OneNote.Category,
-			Size:     item.Size,
-			IsDirectory: false,
-		})
-		return issues
-	}
+	return v.deviceName(name) == "" && !limits.BlockedNamesSet[strings.ToUpper(name)]
+}
 
-	// Check other file types
-	if msg, exists := v.config.ProblematicFiles.Other[ext]; exists {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueProblematicFile,
-			Severity: models.SeverityInfo,
-			Message:  msg,
-			Category: "Other",
-			Size:     item.Size,
-			IsDirectory: false,
-		})
-		return issues
+// removeFold removes every case-insensitive occurrence of pattern from s
+func removeFold(s, pattern string) string {
+	if pattern == "" {
+		return s
+	}
+	lowerPattern := strings.ToLower(pattern)
+	for {
+		i := strings.Index(strings.ToLower(s), lowerPattern)
+		if i < 0 || i+len(pattern) > len(s) {
+			return s
+		}
+		s = s[:i] + s[i+len(pattern):]
 	}
+}
 
-	// Check for secret files
-	nameLower := strings.ToLower(item.Name)
-	for pattern := range v.config.ProblematicFiles.Secrets.PatternsSet {
-		if matchesPattern(nameLower, strings.ToLower(pattern)) {
-			issues = append(issues, models.Issue{
-				Path:     item.Path,
-				Type:     models.IssueProblematicFile,
-				Severity: models.SeverityWarning,
-				Message:  v.config.ProblematicFiles.Secrets.Message,
-				Category: "Security",
-				Size:     item.Size,
-				IsDirectory: false,
-			})
+// extendedLengthPrefixes are the Windows prefixes that lift MAX_PATH, as
+// they appear after filepath.ToSlash. They are not part of the path
+// SharePoint sees.
+var extendedLengthPrefixes = []string{"//?/UNC/", "//?/", "//./"}
+
+// canonicalRelativePath returns the form of a path below the scan root
+// that the checks measure and compare: forward slashes, "." and ".."
+// elements resolved, no extended-length prefix and no leading slash. The
+// scan root itself is "".
+func canonicalRelativePath(rel string) string {
+	rel = filepath.ToSlash(rel)
+	for _, prefix := range extendedLengthPrefixes {
+		if strings.HasPrefix(rel, prefix) {
+			rel = rel[len(prefix):]
 			break
 		}
 	}
 
-	return issues
+	rel = strings.TrimLeft(path.Clean("/"+rel), "/")
+	return rel
 }
 
-// checkFileSize validates file size constraints
-func (v *Validator) checkFileSize(item *models.FileSystemItem) []models.Issue {
-	var issues []models.Issue
+// serverRelativeLength returns the length SharePoint counts against the
+// path limit: the library's server-relative path plus the item's path
+// within it. The scheme and host are not part of the limit.
+func (v *Validator) serverRelativeLength(relativePath string) int {
+	var relLength int
+	if v.encodedBasis {
+		relLength = len(urlEncodePath(relativePath))
+	} else {
+		relLength = utf8.RuneCountInString(relativePath)
+	}
 
-	// Check max file size
-	if item.Size > v.config.SPOLimits.MaxFileSizeBytes {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueFileSize,
-			Severity: models.SeverityCritical,
-			Message:  "File exceeds 250 GB size limit",
-			Details:  formatSize(item.Size),
-			Size:     item.Size,
-			IsDirectory: false,
-			RemediationHint: "Split file or use alternative storage for files over 250 GB.",
-		})
-	} else if item.Size > v.config.Settings.FileSizeWarnings.Huge {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueFileSize,
-			Severity: models.SeverityWarning,
-			Message:  "Very large file may have sync issues",
-			Details:  formatSize(item.Size),
-			Size:     item.Size,
-			IsDirectory: false,
-			RemediationHint: "Files over 15 GB may experience slow sync or timeout issues.",
-		})
-	} else if item.Size > v.config.Settings.FileSizeWarnings.VeryLarge {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueFileSize,
-			Severity: models.SeverityInfo,
-			Message:  "Large file detected",
-			Details:  formatSize(item.Size),
-			Size:     item.Size,
-			IsDirectory: false,
-		})
+	totalLength := v.destinationPathLen
+	if totalLength > 0 && relLength > 0 {
+		totalLength++ // separator between library and item path
 	}
+	return totalLength + relLength
+}
 
-	return issues
+// urlEncodedChars are the printable ASCII characters SharePoint
+// percent-encodes in a server-relative URL. Other punctuation allowed in
+// names, such as & ! ' ( ) , ; [ ] + = @ $ ~, is left as it is.
+// A backslash can only be part of a name, as on Linux, where "a\b.txt" is
+// one file.
+const urlEncodedChars = "\"#%<>?\\^`{}|"
+
+// urlEncodePath encodes a canonical path, with forward slashes between
+// names, the way SharePoint writes it in a URL. Spaces, control
+// characters, the characters in urlEncodedChars and each UTF-8 byte of
+// non-ASCII characters become %XX, so "Q1 #2.xlsx" is 14 characters and
+// "Café.docx" is 14.
+func urlEncodePath(path string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c <= ' ' || c >= 0x7F || strings.IndexByte(urlEncodedChars, c) >= 0:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0F])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
 }
 
-// checkHiddenFiles validates hidden and system files
-func (v *Validator) checkHiddenFiles(item *models.FileSystemItem) []models.Issue {
-	var issues []models.Issue
+// CheckDestination looks for signs that destinationURL is not a SharePoint
+// document library URL, such as a missing scheme, a query string or a
+// personal OneDrive. It returns one warning per problem found, or nil when
+// the URL looks right. Path lengths are still calculated from a URL that
+// fails these checks, but they are likely to be wrong.
+func CheckDestination(destinationURL string) []string {
+	var warnings []string
+	trimmed := strings.TrimSpace(destinationURL)
+	if trimmed == "" {
+		return nil
+	}
 
-	if item.IsHidden {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueHiddenFile,
-			Severity: models.SeverityInfo,
-			Message:  "Hidden file or folder",
-			Details:  "Hidden files may not be needed in SharePoint",
-			IsDirectory: item.IsDir,
-			RemediationHint: "Review if this hidden item needs to be migrated.",
-		})
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return []string{"The URL cannot be parsed: " + err.Error()}
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return []string{"The URL has no https:// scheme and host, so its whole text is counted toward path length"}
 	}
 
-	if item.IsSystem {
-		issues = append(issues, models.Issue{
-			Path:     item.Path,
-			Type:     models.IssueSystemFile,
-			Severity: models.SeverityWarning,
-			Message:  "System file or folder",
-			Details:  "System files typically should not be migrated",
-			IsDirectory: item.IsDir,
-			RemediationHint: "Exclude system files from migration.",
-		})
+	if parsed.Scheme != "https" {
+		warnings = append(warnings, "SharePoint Online URLs use https, not "+parsed.Scheme)
 	}
 
-	return issues
-}
+	host := strings.ToLower(parsed.Hostname())
+	switch {
+	case strings.HasSuffix(host, "-my.sharepoint.com"):
+		warnings = append(warnings, "The URL points at a personal OneDrive ("+host+"), not a SharePoint site")
+	case !strings.HasSuffix(host, ".sharepoint.com"):
+		warnings = append(warnings, "The host "+host+" is not a SharePoint Online (*.sharepoint.com) host")
+	}
 
-// Helper functions
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		warnings = append(warnings, "The URL has a query string or fragment; use the library URL without anything after ? or #")
+	}
 
-func urlEncodePath(path string) string {
-	// Normalize to forward slashes
-	path = strings.ReplaceAll(path, "\\", "/")
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	last := segments[len(segments)-1]
+	if strings.HasSuffix(strings.ToLower(last), ".aspx") {
+		warnings = append(warnings, "The URL points at a page ("+last+"), not a library; remove the page and any Forms folder from the end")
+	}
 
-	// Encode each segment separately to preserve slashes
-	segments := strings.Split(path, "/")
-	for i, segment := range segments {
-		segments[i] = url.PathEscape(segment)
+	// Below /sites/<name> or /teams/<name>, or directly below the root site
+	library := segments
+	if len(segments) >= 2 && (strings.EqualFold(segments[0], "sites") || strings.EqualFold(segments[0], "teams")) {
+		library = segments[2:]
+	}
+	if !strings.HasSuffix(host, "-my.sharepoint.com") && (len(library) == 0 || library[0] == "") {
+		warnings = append(warnings, "The URL does not include a document library, for example /Shared Documents")
 	}
 
-	return strings.Join(segments, "/")
+	return warnings
 }
 
-func destinationLength(destinationURL string) int {
+// destinationLength returns the length of the destination's server-relative
+// path, e.g. "/sites/Proj/Shared Documents" for
+// https://contoso.sharepoint.com/sites/Proj/Shared%20Documents.
+func destinationLength(destinationURL string, encoded bool) int {
 	trimmed := strings.TrimRight(destinationURL, "/")
 	if trimmed == "" {
 		return 0
@@ -560,23 +2383,21 @@ func destinationLength(destinationURL string) int {
 
 	parsed, err := url.Parse(trimmed)
 	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
-		return len(trimmed)
+		return utf8.RuneCountInString(trimmed)
 	}
 
-	parsed.RawQuery = ""
-	parsed.Fragment = ""
-
-	base := parsed.Scheme + "://" + parsed.Host
-	escapedPath := strings.TrimRight(parsed.EscapedPath(), "/")
-	if escapedPath != "" {
-		base += escapedPath
+	if encoded {
+		return len(urlEncodePath(strings.TrimRight(parsed.Path, "/")))
 	}
-
-	return len(base)
+	return utf8.RuneCountInString(strings.TrimRight(parsed.Path, "/"))
 }
 
-func formatLength(current, max int) string {
-	return formatMessage("%d / %d characters", current, max)
+// limitPercent returns current as a percentage of max, to one decimal place
+func limitPercent(current, max int) float64 {
+	if max <= 0 {
+		return 0
+	}
+	return math.Round(float64(current)*1000/float64(max)) / 10
 }
 
 func formatSize(bytes int64) string {
@@ -600,6 +2421,15 @@ func formatCharList(chars []rune) string {
 	return strings.Join(parts, " ")
 }
 
+// formatCodePoint renders a rune in U+XXXX notation
+func formatCodePoint(ch rune) string {
+	hex := strings.ToUpper(strconv.FormatInt(int64(ch), 16))
+	for len(hex) < 4 {
+		hex = "0" + hex
+	}
+	return "U+" + hex
+}
+
 func formatMessage(format string, args ...interface{}) string {
 	return strings.TrimSpace(formatRemediationHint(format, args...))
 }
@@ -634,7 +2464,7 @@ func formatString(format string, args ...interface{}) string {
 		}
 	}
 
-	return result
+	return strings.ReplaceAll(result, "%%", "%")
 }
 
 func formatInt(v interface{}) string {
@@ -656,8 +2486,15 @@ func formatFloat(v interface{}) string {
 }
 
 func formatChar(v interface{}) string {
-	if s, ok := v.(string); ok && len(s) > 0 {
-		return string(s[0])
+	switch val := v.(type) {
+	case string:
+		if len(val) > 0 {
+			return string(val[0])
+		}
+	case byte:
+		return string(rune(val))
+	case rune:
+		return string(val)
 	}
 	return ""
 }
@@ -710,6 +2547,9 @@ func float64ToString(f float64) string {
 	return int64ToString(whole) + "." + intToString(int(frac))
 }
 
+// matchesPattern matches name against a pattern with a leading and/or
+// trailing * wildcard. Both are compared as given, so callers lower-case
+// them for SharePoint's case-insensitive names.
 func matchesPattern(name, pattern string) bool {
 	// Simple pattern matching for * wildcards
 	if !strings.Contains(pattern, "*") {
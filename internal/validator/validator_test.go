@@ -0,0 +1,878 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// testRoot is the scan root the items in these tests are placed under
+var testRoot = filepath.Join(string(filepath.Separator), "share")
+
+// newItem builds an item at rel, a slash-separated path below testRoot
+func newItem(rel string, isDir bool) *models.FileSystemItem {
+	rel = filepath.FromSlash(rel)
+	return &models.FileSystemItem{
+		Path:         filepath.Join(testRoot, rel),
+		Name:         filepath.Base(rel),
+		IsDir:        isDir,
+		RelativePath: rel,
+	}
+}
+
+// loadTestConfig loads a config file with the given content, so its rules
+// are compiled the way they are for a scan
+func loadTestConfig(t *testing.T, content string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// newTestValidator creates a validator with only the named checks on
+func newTestValidator(cfg *config.Config, destination string, checks ...string) *Validator {
+	if cfg == nil {
+		cfg = config.NewDefaultConfig()
+	}
+	enabled := make(map[string]bool, len(checks))
+	for _, name := range checks {
+		enabled[name] = true
+	}
+	return NewValidator(cfg, destination, enabled)
+}
+
+// issuesOfType returns the issues of one type
+func issuesOfType(issues []models.Issue, issueType models.IssueType) []models.Issue {
+	var matched []models.Issue
+	for _, issue := range issues {
+		if issue.Type == issueType {
+			matched = append(matched, issue)
+		}
+	}
+	return matched
+}
+
+// hasMessage reports whether any issue has the message ID
+func hasMessage(issues []models.Issue, id string) bool {
+	for _, issue := range issues {
+		if issue.MessageID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCustomRuleMatchesFolderNames(t *testing.T) {
+	cfg := loadTestConfig(t, `{
+  "customRules": [
+    {"name": "ProjectCode", "pattern": "PRJ-\\d{4}", "severity": "Critical", "appliesTo": "folders"}
+  ]
+}`)
+	v := newTestValidator(cfg, "", "CustomRules")
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"Clients/PRJ-1234 Acme", true, true},
+		{"Clients/prj-1234", true, false},
+		{"Clients/PRJ-12", true, false},
+		{"Clients/PRJ-1234.docx", false, false},
+	}
+	for _, tt := range tests {
+		issues := issuesOfType(v.ValidateItem(newItem(tt.rel, tt.isDir)), models.IssueCustomRule)
+		if got := len(issues) > 0; got != tt.want {
+			t.Errorf("%s (folder %v): reported = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+			continue
+		}
+		if !tt.want {
+			continue
+		}
+		issue := issues[0]
+		if issue.Severity != models.SeverityCritical || issue.Category != "ProjectCode" || !issue.IsDirectory {
+			t.Errorf("%s: unexpected issue %+v", tt.rel, issue)
+		}
+	}
+}
+
+func TestDestinationLength(t *testing.T) {
+	tests := []struct {
+		url     string
+		encoded bool
+		want    int
+	}{
+		// "/sites/Proj/Shared Documents"
+		{"https://contoso.sharepoint.com/sites/Proj/Shared%20Documents", false, 28},
+		{"https://contoso.sharepoint.com/sites/Proj/Shared%20Documents/", false, 28},
+		// "/sites/Proj/Shared%20Documents"
+		{"https://contoso.sharepoint.com/sites/Proj/Shared%20Documents", true, 30},
+		{"https://contoso.sharepoint.com/sites/Proj/Shared Documents", true, 30},
+		// The host does not count, however long the tenant name
+		{"https://averyveryverylongtenantname.sharepoint.com/sites/Proj/Shared%20Documents", false, 28},
+		{"https://contoso.sharepoint.com", false, 0},
+		{"", false, 0},
+		// Without a scheme and host the whole text counts
+		{"sites/Proj/Docs", false, 15},
+		// Paths are measured in characters, not bytes
+		{"https://contoso.sharepoint.com/sites/Proj/Документы", false, 21},
+		{"sites/Projekt/Übersicht", false, 23},
+	}
+
+	for _, tt := range tests {
+		if got := destinationLength(tt.url, tt.encoded); got != tt.want {
+			t.Errorf("destinationLength(%q, %v) = %d, want %d", tt.url, tt.encoded, got, tt.want)
+		}
+	}
+}
+
+func TestPathLengthCountsServerRelativePath(t *testing.T) {
+	const destination = "https://contoso.sharepoint.com/sites/Proj/Shared%20Documents"
+	item := newItem("Finance/Q1 Report.xlsx", false)
+
+	for _, tt := range []struct {
+		basis string
+		want  int
+	}{
+		// "/sites/Proj/Shared Documents" + "/" + "Finance/Q1 Report.xlsx"
+		{"decoded", 28 + 1 + 22},
+		// "/sites/Proj/Shared%20Documents" + "/" + "Finance/Q1%20Report.xlsx"
+		{"encoded", 30 + 1 + 24},
+	} {
+		cfg := config.NewDefaultConfig()
+		cfg.Settings.PathLengthBasis = tt.basis
+		// Report every path, so the measured length is always visible
+		cfg.Settings.PathWarningThresholdPercent = 0
+		v := newTestValidator(cfg, destination, "PathLength")
+
+		issues := issuesOfType(v.ValidateItem(item), models.IssuePathLength)
+		if len(issues) != 1 {
+			t.Fatalf("%s: got %d path length issues, want 1", tt.basis, len(issues))
+		}
+		if issues[0].CurrentLength != tt.want {
+			t.Errorf("%s: length = %d, want %d", tt.basis, issues[0].CurrentLength, tt.want)
+		}
+	}
+}
+
+func TestPathLengthLimitExcludesHost(t *testing.T) {
+	const destination = "https://contoso.sharepoint.com/sites/Proj/Shared%20Documents"
+	cfg := config.NewDefaultConfig()
+	maxLength := cfg.SPOLimits.MaxPathLength
+	v := newTestValidator(cfg, destination, "PathLength")
+
+	// Exactly at the limit once the 28-character library path and the
+	// separator are added; counting the host would put it over
+	name := strings.Repeat("a", 100)
+	rel := name + "/" + name + "/" + strings.Repeat("b", maxLength-28-1-202)
+	issues := issuesOfType(v.ValidateItem(newItem(rel, false)), models.IssuePathLength)
+	if hasMessage(issues, msgPathTooLong) {
+		t.Errorf("a path at the limit was reported as too long: %+v", issues)
+	}
+
+	issues = issuesOfType(v.ValidateItem(newItem(rel+"c", false)), models.IssuePathLength)
+	if !hasMessage(issues, msgPathTooLong) {
+		t.Errorf("a path one over the limit was not reported: %+v", issues)
+	}
+}
+
+func TestInvisibleCharacters(t *testing.T) {
+	v := newTestValidator(nil, "", "InvalidCharacters")
+
+	// The names hold the literal characters listed in details, except
+	// U+FEFF, which Go source cannot contain
+	tests := []struct {
+		name    string
+		want    string // Message ID, or "" for no invisible character issue
+		details string // Text the details must contain
+	}{
+		{"Report​.docx", msgInvisibleChars, "U+200B"},
+		{"Q1 Budget.xlsx", msgInvisibleChars, "U+00A0"},
+		{"a​b c​.txt", msgInvisibleChars, "U+200B U+00A0"},
+		{"​", msgInvisibleOnly, "U+200B"},
+		{"​​\uFEFF", msgInvisibleOnly, "U+200B U+FEFF"},
+		{"Report.docx", "", ""},
+	}
+
+	for _, tt := range tests {
+		issues := v.ValidateItem(newItem(tt.name, false))
+		var found *models.Issue
+		for i := range issues {
+			if issues[i].MessageID == msgInvisibleChars || issues[i].MessageID == msgInvisibleOnly {
+				found = &issues[i]
+			}
+		}
+
+		if tt.want == "" {
+			if found != nil {
+				t.Errorf("%q: unexpected issue %+v", tt.name, *found)
+			}
+			continue
+		}
+		if found == nil || found.MessageID != tt.want {
+			t.Errorf("%q: got %+v, want %s", tt.name, found, tt.want)
+			continue
+		}
+		if !strings.Contains(found.Details, tt.details) {
+			t.Errorf("%q: details %q do not list %s", tt.name, found.Details, tt.details)
+		}
+		if found.RemediationHint == "" {
+			t.Errorf("%q: no remediation hint", tt.name)
+		}
+		if tt.want == msgInvisibleOnly && found.Severity != models.SeverityCritical {
+			t.Errorf("%q: severity = %s, want Critical", tt.name, found.Severity)
+		}
+	}
+}
+
+func TestInvisibleCharactersAreConfigurable(t *testing.T) {
+	cfg := loadTestConfig(t, `{"spoLimits": {"invisibleCharacters": [160]}}`)
+	v := newTestValidator(cfg, "", "InvalidCharacters")
+
+	if issues := v.ValidateItem(newItem("Report​.docx", false)); hasMessage(issues, msgInvisibleChars) {
+		t.Error("U+200B was reported after being removed from the set")
+	}
+	if issues := v.ValidateItem(newItem("Q1 Budget.xlsx", false)); !hasMessage(issues, msgInvisibleChars) {
+		t.Error("U+00A0 was not reported")
+	}
+}
+
+func TestCanonicalRelativePath(t *testing.T) {
+	tests := []struct {
+		rel  string // Slash-separated; converted to the OS separator
+		want string
+	}{
+		{".", ""},
+		{"", ""},
+		{"./Reports/Q1.xlsx", "Reports/Q1.xlsx"},
+		{"Reports/./Q1.xlsx", "Reports/Q1.xlsx"},
+		{"Reports/Old/../Q1.xlsx", "Reports/Q1.xlsx"},
+		{"../Q1.xlsx", "Q1.xlsx"},
+		{"Reports//Q1.xlsx", "Reports/Q1.xlsx"},
+		{"/Reports/Q1.xlsx", "Reports/Q1.xlsx"},
+		{"Reports/", "Reports"},
+		{"//?/Reports/Q1.xlsx", "Reports/Q1.xlsx"},
+		{"//?/UNC/Reports/Q1.xlsx", "Reports/Q1.xlsx"},
+		{"//./Reports/Q1.xlsx", "Reports/Q1.xlsx"},
+	}
+
+	for _, tt := range tests {
+		if got := canonicalRelativePath(filepath.FromSlash(tt.rel)); got != tt.want {
+			t.Errorf("canonicalRelativePath(%q) = %q, want %q", tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestOddRelativePathsMeasureTheCanonicalForm(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Settings.PathWarningThresholdPercent = 0
+	v := newTestValidator(cfg, "", "PathLength")
+
+	for _, rel := range []string{"Reports/Q1.xlsx", "./Reports/Q1.xlsx", "Reports/Old/../Q1.xlsx", "//?/Reports/Q1.xlsx"} {
+		item := newItem("Q1.xlsx", false)
+		item.RelativePath = filepath.FromSlash(rel)
+		issues := issuesOfType(v.ValidateItem(item), models.IssuePathLength)
+		if len(issues) != 1 || issues[0].CurrentLength != len("Reports/Q1.xlsx") {
+			t.Errorf("%q: got %+v, want one issue of length %d", rel, issues, len("Reports/Q1.xlsx"))
+		}
+	}
+}
+
+func TestBlankNames(t *testing.T) {
+	v := newTestValidator(nil, "", "InvalidCharacters")
+
+	tests := []struct {
+		name  string
+		isDir bool
+		want  bool
+	}{
+		{"..", true, true},
+		{"...", false, true},
+		{"   ", true, true},
+		{" . ", false, true},
+		{"\t", false, true},
+		{". ​", false, true}, // With a zero-width space
+		{"...txt", false, false},
+		{"..hidden", true, false},
+		{"a...", false, false},
+		{"​", false, false}, // Only invisible: reported as such instead
+	}
+
+	for _, tt := range tests {
+		issues := v.ValidateItem(newItem(tt.name, tt.isDir))
+		if got := hasMessage(issues, msgBlankName); got != tt.want {
+			t.Errorf("%q: blank name reported = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for _, issue := range issues {
+			if issue.MessageID == msgBlankName && issue.Severity != models.SeverityCritical {
+				t.Errorf("%q: severity = %s, want Critical", tt.name, issue.Severity)
+			}
+		}
+	}
+}
+
+func TestNameLengthSeparatesFoldersFromFiles(t *testing.T) {
+	long := strings.Repeat("a", 260)
+
+	folder := newTestValidator(nil, "").checkPathLength(newItem(long, true))
+	if !hasMessage(folder, msgFolderNameTooLong) || hasMessage(folder, msgNameTooLong) {
+		t.Fatalf("260-character folder name: got %+v, want only %s", folder, msgFolderNameTooLong)
+	}
+	for _, issue := range folder {
+		if issue.MessageID != msgFolderNameTooLong {
+			continue
+		}
+		if issue.Severity != models.SeverityCritical || !issue.IsDirectory || issue.CurrentLength != 260 {
+			t.Errorf("folder issue = %+v, want Critical, a directory, length 260", issue)
+		}
+		if !strings.Contains(issue.RemediationHint, "everything inside it") {
+			t.Errorf("folder hint %q does not warn about the folder's contents", issue.RemediationHint)
+		}
+	}
+
+	file := newTestValidator(nil, "").checkPathLength(newItem(long+".txt", false))
+	if !hasMessage(file, msgNameTooLong) || hasMessage(file, msgFolderNameTooLong) {
+		t.Fatalf("264-character file name: got %+v, want only %s", file, msgNameTooLong)
+	}
+	for _, issue := range file {
+		if issue.MessageID == msgNameTooLong && (issue.Severity != models.SeverityCritical || issue.IsDirectory || issue.CurrentLength != 264) {
+			t.Errorf("file issue = %+v, want Critical, a file, length 264", issue)
+		}
+	}
+
+	// Names, bases and extensions are measured in characters, not bytes
+	if issues := newTestValidator(nil, "").checkPathLength(newItem(strings.Repeat("文", 200)+".docx", false)); hasMessage(issues, msgNameTooLong) {
+		t.Errorf("205-character CJK file name: got %+v, want no %s", issues, msgNameTooLong)
+	}
+	cjkFile := newTestValidator(nil, "").checkPathLength(newItem(strings.Repeat("文", 260)+".文書", false))
+	if !hasMessage(cjkFile, msgNameTooLong) {
+		t.Fatalf("263-character CJK file name: got %+v, want %s", cjkFile, msgNameTooLong)
+	}
+	for _, issue := range cjkFile {
+		if issue.MessageID != msgNameTooLong {
+			continue
+		}
+		if issue.CurrentLength != 263 || !strings.Contains(issue.Details, "263 / 255 characters (260 name + 3 extension)") {
+			t.Errorf("CJK file issue = %+v, want length 263 split 260 + 3", issue)
+		}
+		if !strings.Contains(issue.RemediationHint, "252 characters or fewer (8 too many)") {
+			t.Errorf("CJK file hint %q, want the base shortened to 252 characters", issue.RemediationHint)
+		}
+	}
+
+	// The folder-only warning threshold leaves files alone
+	cfg := config.NewDefaultConfig()
+	cfg.Settings.FolderNameWarningLength = 200
+	v := newTestValidator(cfg, "")
+	if issues := v.checkPathLength(newItem(strings.Repeat("a", 220), true)); !hasMessage(issues, msgFolderNameLong) {
+		t.Errorf("220-character folder name with a 200 warning length: got %+v, want %s", issues, msgFolderNameLong)
+	}
+	if issues := v.checkPathLength(newItem(strings.Repeat("a", 220)+".txt", false)); hasMessage(issues, msgFolderNameLong) || hasMessage(issues, msgNameTooLong) {
+		t.Errorf("224-character file name: got %+v, want no name-length issue", issues)
+	}
+
+	// Names are measured in characters, not bytes
+	cjk := newItem(strings.Repeat("文", 220), true)
+	if issues := newTestValidator(nil, "").checkPathLength(cjk); hasMessage(issues, msgFolderNameTooLong) {
+		t.Errorf("220-character CJK folder name: got %+v, want no %s", issues, msgFolderNameTooLong)
+	}
+	issues := v.checkPathLength(cjk)
+	if !hasMessage(issues, msgFolderNameLong) {
+		t.Errorf("220-character CJK folder name with a 200 warning length: got %+v, want %s", issues, msgFolderNameLong)
+	}
+	for _, issue := range issues {
+		if issue.MessageID == msgFolderNameLong && issue.CurrentLength != 220 {
+			t.Errorf("CJK folder issue = %+v, want length 220", issue)
+		}
+	}
+}
+
+func TestReservedAndBlockedNames(t *testing.T) {
+	v := newTestValidator(nil, "")
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  []string // message IDs from the reserved-name and name checks
+	}{
+		// Device names are reserved with any extension
+		{"CON.txt", false, []string{msgReservedName}},
+		{"con.tar.gz", false, []string{msgReservedName}},
+		{"LPT9", false, []string{msgReservedName}},
+		{"Reports/LPT9", true, []string{msgReservedName}},
+		{"CONTRACTS.txt", false, nil},
+		{"LPT10", false, nil},
+		// _vti_ is blocked anywhere in a name, once
+		{"my_vti_folder", true, []string{msgBlockedPattern}},
+		{"_vti_cnf", true, []string{msgBlockedPattern}},
+		// Blocked names only match the whole name
+		{"desktop.ini", false, []string{msgBlockedName}},
+		{"my desktop.ini", false, nil},
+		// forms is reserved only at the library root
+		{"forms", true, []string{msgRootLevelName}},
+		{"Team/forms", true, nil},
+		{"forms", false, nil},
+	}
+
+	for _, tt := range tests {
+		item := newItem(tt.rel, tt.isDir)
+		var got []string
+		for _, issue := range append(v.checkReservedNames(item), v.checkInvalidCharacters(item)...) {
+			got = append(got, issue.MessageID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+}
+
+// TestURLEncodePath checks urlEncodePath against names with the encoded
+// lengths SharePoint reports for them in server-relative URLs
+func TestURLEncodePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		length  int
+	}{
+		{"Budget.xlsx", "Budget.xlsx", 11},
+		{"Q1 Report.docx", "Q1%20Report.docx", 16},
+		{"Q1 #2.xlsx", "Q1%20%232.xlsx", 14},
+		{"100% done.txt", "100%25%20done.txt", 17},
+		{"R&D Plan.pptx", "R&D%20Plan.pptx", 15},
+		{"Sales & Marketing/Q3 #1.docx", "Sales%20&%20Marketing/Q3%20%231.docx", 36},
+		{"Café.docx", "Caf%C3%A9.docx", 14},
+		{"50%#off.pdf", "50%25%23off.pdf", 15},
+		{`a\b.txt`, "a%5Cb.txt", 9},
+		{"Notes {draft}.txt", "Notes%20%7Bdraft%7D.txt", 23},
+		{"it's-fine_(v2)!.txt", "it's-fine_(v2)!.txt", 19},
+		{"two  spaces.txt", "two%20%20spaces.txt", 19},
+	}
+	for _, tt := range tests {
+		got := urlEncodePath(tt.name)
+		if got != tt.encoded || len(got) != tt.length {
+			t.Errorf("urlEncodePath(%q) = %q (%d characters), want %q (%d)", tt.name, got, len(got), tt.encoded, tt.length)
+		}
+	}
+}
+
+func TestFileSizeTiers(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.SPOLimits.MaxFileSizeBytes = 1000
+	// Given smallest first; the largest tier a file exceeds applies
+	if err := cfg.SetFileSizeWarnings([]config.FileSizeTier{
+		{Bytes: 100, Severity: "info"},
+		{Bytes: 500, Severity: "critical"},
+		{Bytes: 200, Severity: "warning"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	v := newTestValidator(cfg, "")
+
+	tests := []struct {
+		size int64
+		want string // Message ID, or "" for none
+	}{
+		{100, ""},
+		{101, msgSizeLarge},
+		{200, msgSizeLarge},
+		{201, msgSizeHuge},
+		{501, msgSizeCritical},
+		{1000, msgSizeCritical},
+		{1001, msgSizeOverLimit},
+	}
+	for _, tt := range tests {
+		item := newItem("big.bin", false)
+		item.Size = tt.size
+		issues := v.checkFileSize(item)
+		var got string
+		if len(issues) > 1 {
+			t.Errorf("%d bytes: got %d issues, want at most one", tt.size, len(issues))
+		}
+		if len(issues) > 0 {
+			got = issues[0].MessageID
+		}
+		if got != tt.want {
+			t.Errorf("%d bytes: got %q, want %q", tt.size, got, tt.want)
+		}
+	}
+
+	// No tiers leaves only the upload limit
+	cfg.Settings.FileSizeWarnings = nil
+	item := newItem("big.bin", false)
+	item.Size = 999
+	if issues := v.checkFileSize(item); len(issues) != 0 {
+		t.Errorf("no tiers: got %+v, want no issues", issues)
+	}
+}
+
+// TestRulesIgnoreCase checks that upper- and mixed-case names are reported
+// the same as their lower-case forms by every rule group
+func TestRulesIgnoreCase(t *testing.T) {
+	var checks []string
+	for name := range config.NewDefaultConfig().Settings.DefaultChecks {
+		checks = append(checks, name)
+	}
+	// messageIDs validates a lone item with every check on and returns the
+	// message IDs reported
+	messageIDs := func(cfg *config.Config, rel string, isDir bool) string {
+		v := newTestValidator(cfg, "", checks...)
+		var ids []string
+		for _, issue := range append(v.ValidateItem(newItem(rel, isDir)), v.Finalize()...) {
+			ids = append(ids, issue.MessageID)
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+
+	tests := []struct {
+		group    string
+		isDir    bool
+		variants []string // The lower-case form first
+	}{
+		{"executables", false, []string{"document.exe", "DOCUMENT.EXE", "Document.Exe"}},
+		{"scripts", false, []string{"setup.ps1", "SETUP.PS1", "Setup.Ps1"}},
+		{"system types", false, []string{"driver.dll", "DRIVER.DLL", "Driver.Dll"}},
+		{"no-sync files", false, []string{"thumbs.db", "THUMBS.DB", "Thumbs.DB"}},
+		{"double extensions", false, []string{"invoice.pdf.exe", "INVOICE.PDF.EXE", "Invoice.Pdf.Exe"}},
+		{"problematic types", false, []string{"plan.dwg", "PLAN.DWG", "Plan.Dwg"}},
+		{"other types", false, []string{"shortcut.lnk", "SHORTCUT.LNK", "Shortcut.Lnk"}},
+		{"secrets", false, []string{"id_rsa", "ID_RSA", "Id_Rsa"}},
+		{"secret patterns", false, []string{"server.pem", "SERVER.PEM", "Server.Pem"}},
+		{"owner files", false, []string{"~$budget.xlsx", "~$BUDGET.XLSX", "~$Budget.Xlsx"}},
+		{"device names", false, []string{"con.txt", "CON.TXT", "Con.Txt"}},
+		{"blocked names", false, []string{"desktop.ini", "DESKTOP.INI", "Desktop.INI"}},
+		{"blocked patterns", true, []string{"my_vti_folder", "MY_VTI_FOLDER", "My_Vti_Folder"}},
+		{"root-level names", true, []string{"forms", "FORMS", "Forms"}},
+		{"version control", true, []string{".git", ".GIT", ".Git"}},
+	}
+
+	for _, tt := range tests {
+		want := messageIDs(nil, tt.variants[0], tt.isDir)
+		if want == "" {
+			t.Errorf("%s: %s is not reported", tt.group, tt.variants[0])
+			continue
+		}
+		for _, name := range tt.variants[1:] {
+			if got := messageIDs(nil, name, tt.isDir); got != want {
+				t.Errorf("%s: %s reports %s, but %s reports %s", tt.group, name, got, tt.variants[0], want)
+			}
+		}
+	}
+
+	// Extensions in a config file match in any case, with or without the dot
+	cfg := loadTestConfig(t, `{
+  "blockedFileTypes": {"custom": {"extensions": ["XYZ"], "severity": "Critical"}},
+  "problematicFiles": {"other": {".ABC": {"message": "Legacy format"}}}
+}`)
+	for _, variants := range [][]string{{"data.xyz", "DATA.XYZ", "Data.Xyz"}, {"old.abc", "OLD.ABC", "Old.Abc"}} {
+		want := messageIDs(cfg, variants[0], false)
+		if want == "" {
+			t.Errorf("config extension: %s is not reported", variants[0])
+			continue
+		}
+		for _, name := range variants[1:] {
+			if got := messageIDs(cfg, name, false); got != want {
+				t.Errorf("config extension: %s reports %s, but %s reports %s", name, got, variants[0], want)
+			}
+		}
+	}
+}
+
+// TestFinalizeMessagesNeedTree checks that every condition Finalize
+// reports is one -verify knows it cannot confirm by re-checking an item
+func TestFinalizeMessagesNeedTree(t *testing.T) {
+	var checks []string
+	for name := range config.NewDefaultConfig().Settings.DefaultChecks {
+		checks = append(checks, name)
+	}
+	v := newTestValidator(nil, "", checks...)
+
+	shortName := newItem("Reports/Quarterly Report.txt", false)
+	shortName.ShortName = "QUARTE~1.TXT"
+
+	// Owner files are paired with their document on disk
+	open := t.TempDir()
+	if err := os.WriteFile(filepath.Join(open, "Budget.xlsx"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	ownerFile := &models.FileSystemItem{
+		Path:         filepath.Join(open, "~$Budget.xlsx"),
+		Name:         "~$Budget.xlsx",
+		RelativePath: filepath.Join("Open", "~$Budget.xlsx"),
+	}
+	items := []*models.FileSystemItem{
+		newItem("Reports", true),
+		newItem("Reports/Budget.xlsx", false),
+		newItem("Reports/budget.xlsx", false),
+		newItem("Reports/Notes", false),
+		newItem("Reports/Notes.", false),
+		newItem("Reports/pay.txt", false),
+		newItem("Reports/pаy.txt", false), // Cyrillic а
+		ownerFile,
+		newItem("Reports/~$Missing.docx", false),
+		shortName,
+		newItem("Reports/QUARTE~1.TXT", false),
+		newItem("Reports/PROJEC~1.DOC", false),
+		newItem("Deep", true),
+		newItem("Deep/"+strings.Repeat("a", 420)+".txt", false),
+		newItem("Empty", true),
+		newItem("Excluded", true),
+		newItem("Repo", true),
+		newItem("Repo/.git", true),
+		newItem("Repo/.git/HEAD", false),
+		newItem("Module", true),
+		newItem("Module/.git", false),
+	}
+	for _, item := range items {
+		v.ObserveListing(item)
+		v.ValidateItem(item)
+	}
+	v.SetFolderContents(models.FolderContents{
+		Complete: true,
+		Excluded: map[string]bool{filepath.Join(testRoot, "Excluded"): true},
+	})
+
+	seen := make(map[string]bool)
+	for _, issue := range v.Finalize() {
+		seen[issue.MessageID] = true
+		if !NeedsTree(issue.MessageID) {
+			t.Errorf("Finalize reported %s on %s, but NeedsTree(%q) is false", issue.MessageID, issue.Path, issue.MessageID)
+		}
+	}
+	for id := range treeMessages {
+		if !seen[id] {
+			t.Errorf("the tree did not produce %s; add an item that does", id)
+		}
+	}
+}
+
+func TestPrefixRules(t *testing.T) {
+	v := newTestValidator(nil, "")
+	tests := []struct {
+		rel      string
+		isDir    bool
+		want     string // Message ID, or "" for none
+		severity models.Severity
+	}{
+		{"~temp", true, msgFolderPrefix, models.SeverityWarning},
+		{"Projects/~temp", true, msgFolderPrefix, models.SeverityWarning},
+		{"~temp.txt", false, "", ""},
+		{"..hidden", false, msgFilePrefix, models.SeverityWarning},
+		{"..hidden", true, msgFolderPrefix, models.SeverityWarning},
+		{".hidden", false, "", ""},
+		{"not..hidden", false, "", ""},
+		// _vti_ is blocked anywhere in a name, not as a prefix
+		{"_vti_config", true, msgBlockedPattern, models.SeverityCritical},
+		{"_vti_config", false, msgBlockedPattern, models.SeverityCritical},
+		// Owner files are paired with their documents in Finalize
+		{"~$Budget.xlsx", false, "", ""},
+	}
+	for _, tt := range tests {
+		issues := v.checkInvalidCharacters(newItem(tt.rel, tt.isDir))
+		if tt.want == "" {
+			if len(issues) != 0 {
+				t.Errorf("%s: got %+v, want no issues", tt.rel, issues)
+			}
+			continue
+		}
+		if len(issues) != 1 || issues[0].MessageID != tt.want || issues[0].Severity != tt.severity {
+			t.Errorf("%s: got %+v, want one %s %s", tt.rel, issues, tt.severity, tt.want)
+		}
+	}
+
+	// A rule from the config file sets its own severity and message
+	cfg := loadTestConfig(t, `{
+  "spoLimits": {"prefixRules": [{"prefix": "_", "appliesTo": "files", "severity": "Info", "message": "Leading underscore"}]}
+}`)
+	v = newTestValidator(cfg, "")
+	issues := v.checkInvalidCharacters(newItem("_draft.docx", false))
+	if len(issues) != 1 || issues[0].Severity != models.SeverityInfo || issues[0].Message != "Leading underscore" {
+		t.Errorf("configured rule: got %+v, want one Info issue with its message", issues)
+	}
+	if issues := v.checkInvalidCharacters(newItem("_drafts", true)); len(issues) != 0 {
+		t.Errorf("configured file rule matched a folder: %+v", issues)
+	}
+}
+
+// TestEmbeddedBackslash checks that a name containing a backslash, which
+// Linux and macOS allow, is flagged and measured as one name rather than
+// split into folders
+func TestEmbeddedBackslash(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("a backslash is a path separator on Windows")
+	}
+
+	item := newItem(`Reports/a\b.txt`, false)
+	if item.Name != `a\b.txt` {
+		t.Fatalf("item name = %q, want a\\b.txt", item.Name)
+	}
+	if got := canonicalRelativePath(item.RelativePath); got != `Reports/a\b.txt` {
+		t.Errorf("canonicalRelativePath = %q, want Reports/a\\b.txt", got)
+	}
+	if got := urlEncodePath(canonicalRelativePath(item.RelativePath)); got != "Reports/a%5Cb.txt" {
+		t.Errorf("urlEncodePath = %q, want Reports/a%%5Cb.txt", got)
+	}
+	if !isRootLevel(newItem(`a\b`, true)) {
+		t.Error(`a\b at the top of the scan is not treated as root level`)
+	}
+
+	v := newTestValidator(nil, "")
+	if issues := v.checkInvalidCharacters(item); !hasMessage(issues, msgInvalidChars) {
+		t.Errorf("got %+v, want %s for the backslash", issues, msgInvalidChars)
+	}
+
+	for _, tt := range []struct {
+		basis string
+		want  int
+	}{
+		{"decoded", len(`Reports/a\b.txt`)},
+		{"encoded", len("Reports/a%5Cb.txt")},
+	} {
+		cfg := config.NewDefaultConfig()
+		cfg.Settings.PathWarningThresholdPercent = 0
+		cfg.Settings.PathLengthBasis = tt.basis
+		issues := issuesOfType(newTestValidator(cfg, "").checkPathLength(item), models.IssuePathLength)
+		if len(issues) != 1 || issues[0].CurrentLength != tt.want {
+			t.Errorf("%s basis: got %+v, want one issue of length %d", tt.basis, issues, tt.want)
+		}
+	}
+}
+
+func TestNameConflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []*models.FileSystemItem // In walk order
+		want  []string                 // Relative paths reported
+	}{
+		{
+			"trailing dot",
+			[]*models.FileSystemItem{newItem("Data", true), newItem("Data.", true)},
+			[]string{"Data", "Data."},
+		},
+		{
+			"trailing space and case",
+			[]*models.FileSystemItem{newItem("Notes ", false), newItem("notes", false)},
+			[]string{"Notes ", "notes"},
+		},
+		{
+			// Only the end of the whole name is trimmed
+			"space before the extension",
+			[]*models.FileSystemItem{newItem("Report .docx", false), newItem("Report.docx", false)},
+			nil,
+		},
+		{
+			"same name in different folders",
+			[]*models.FileSystemItem{
+				newItem("A", true), newItem("A/Data", false),
+				newItem("B", true), newItem("B/Data.", false),
+			},
+			nil,
+		},
+		{
+			// The walk lists a subfolder between two siblings
+			"siblings either side of a subfolder",
+			[]*models.FileSystemItem{
+				newItem("P", true), newItem("P/Data", true), newItem("P/Data/Deep", true),
+				newItem("P/Data/Deep/x.txt", false), newItem("P/data.", false),
+			},
+			[]string{"P/Data", "P/data."},
+		},
+	}
+
+	for _, tt := range tests {
+		v := newTestValidator(nil, "", "NameConflicts")
+		for _, item := range tt.items {
+			v.ObserveListing(item)
+		}
+		var got []string
+		for _, issue := range issuesOfType(v.Finalize(), models.IssueNameConflict) {
+			rel, err := filepath.Rel(testRoot, issue.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, filepath.ToSlash(rel))
+		}
+		sort.Strings(got)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: conflicts reported on %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNameConflictsDropListedFolders(t *testing.T) {
+	v := newTestValidator(nil, "", "NameConflicts")
+	for _, rel := range []string{"A", "A/B", "A/B/C", "A/B/C/x.txt", "D", "D/y.txt"} {
+		v.ObserveListing(newItem(rel, !strings.Contains(rel, ".")))
+	}
+	// Only the root and D are still being listed
+	var dirs []string
+	for _, folder := range v.nameListing {
+		dirs = append(dirs, folder.dir)
+	}
+	if strings.Join(dirs, "|") != ".|d" {
+		t.Errorf("folders held = %q, want the root and d", dirs)
+	}
+}
+
+func TestOwnerFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"Budget.xlsx", "Quarterly Report.docx", "notes.txt", "Archive.docx"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "Plans.docx"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		document string // "" for an orphaned owner file
+	}{
+		{"~$Budget.xlsx", "Budget.xlsx"},
+		{"~$BUDGET.XLSX", "Budget.xlsx"},
+		// Word drops the first two characters of longer names
+		{"~$arterly Report.docx", "Quarterly Report.docx"},
+		{"~$notes.txt", ""},  // Not an Office document
+		{"~$Plans.docx", ""}, // A folder
+		{"~$Missing.docx", ""},
+	}
+
+	v := newTestValidator(nil, "", "InvalidCharacters")
+	for _, tt := range tests {
+		v.Observe(&models.FileSystemItem{Path: filepath.Join(dir, tt.name), Name: tt.name, RelativePath: tt.name})
+	}
+	// Documents are not held; only the owner files are
+	v.Observe(&models.FileSystemItem{Path: filepath.Join(dir, "Archive.docx"), Name: "Archive.docx", RelativePath: "Archive.docx"})
+	if len(v.ownerFiles) != len(tests) {
+		t.Errorf("holding %d items, want the %d owner files", len(v.ownerFiles), len(tests))
+	}
+
+	reported := make(map[string]models.Issue)
+	for _, issue := range v.Finalize() {
+		reported[filepath.Base(issue.Path)] = issue
+	}
+	for _, tt := range tests {
+		issue, ok := reported[tt.name]
+		switch {
+		case !ok:
+			t.Errorf("%s: not reported", tt.name)
+		case tt.document == "" && issue.MessageID != msgOwnerFileOrphan:
+			t.Errorf("%s: reported as %s, want %s", tt.name, issue.MessageID, msgOwnerFileOrphan)
+		case tt.document != "" && (issue.MessageID != msgOwnerFileOpen || !strings.Contains(issue.Details, tt.document)):
+			t.Errorf("%s: reported as %s (%s), want %s naming %s", tt.name, issue.MessageID, issue.Details, msgOwnerFileOpen, tt.document)
+		}
+	}
+}
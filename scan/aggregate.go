@@ -0,0 +1,193 @@
+package scan
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// aggregator builds a Result from the items and issues of a scan. Its
+// methods may be called from any goroutine.
+type aggregator struct {
+	mu      sync.Mutex
+	result  *Result
+	ignore  *IgnoreList
+	weights ReadinessWeights
+	byType  map[IssueType]int
+
+	// Issues handed off by Spill, which count toward the totals but are
+	// no longer in the result
+	spilled      int
+	spilledSum   Summary
+	spilledWorst map[string]models.Severity
+}
+
+func newAggregator(result *Result, ignore *IgnoreList, weights ReadinessWeights) *aggregator {
+	return &aggregator{
+		result:  result,
+		ignore:  ignore,
+		weights: weights,
+		byType:  make(map[IssueType]int),
+	}
+}
+
+// AddItem counts item in the totals and adds its issues, replacing
+// item.Issues with the ones that were not suppressed. Filtered items only
+// count toward the totals.
+func (a *aggregator) AddItem(item *Item) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.result.TotalItems++
+	if item.IsDir {
+		a.result.TotalFolders++
+	} else {
+		a.result.TotalFiles++
+		if !a.result.SizesSkipped {
+			a.result.TotalSize += item.Size
+		}
+	}
+
+	if !item.Filtered {
+		item.Issues = a.suppress(item.Issues)
+		a.add(item.Issues)
+	}
+}
+
+// AddIssues adds issues that do not belong to a single item, such as those
+// from the whole-tree checks, and returns the ones that were not
+// suppressed
+func (a *aggregator) AddIssues(issues []Issue) []Issue {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	issues = a.suppress(issues)
+	a.add(issues)
+	return issues
+}
+
+func (a *aggregator) add(issues []Issue) {
+	a.result.Issues = append(a.result.Issues, issues...)
+	for _, issue := range issues {
+		a.byType[issue.Type]++
+	}
+}
+
+// IssueCounts returns the number of issues added so far, and a copy of
+// their counts by type
+func (a *aggregator) IssueCounts() (int, map[IssueType]int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	byType := make(map[IssueType]int, len(a.byType))
+	for issueType, count := range a.byType {
+		byType[issueType] = count
+	}
+	return a.spilled + len(a.result.Issues), byType
+}
+
+// Spill removes the issues collected so far from the result and returns
+// them, keeping what the totals, summary and readiness score need
+func (a *aggregator) Spill() []Issue {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	issues := a.result.Issues
+	if len(issues) == 0 {
+		return nil
+	}
+	if a.spilledWorst == nil {
+		a.spilledSum = Summarize(nil)
+		a.spilledWorst = make(map[string]models.Severity)
+	}
+
+	a.spilled += len(issues)
+	for _, issue := range issues {
+		a.spilledSum.ByType[issue.Type]++
+		a.spilledSum.BySeverity[issue.Severity]++
+		if issue.ActionRequired {
+			a.spilledSum.ActionRequired++
+		} else {
+			a.spilledSum.AutoSkipped++
+		}
+		if current, ok := a.spilledWorst[issue.Path]; !ok || severityRank(issue.Severity) < severityRank(current) {
+			a.spilledWorst[issue.Path] = issue.Severity
+		}
+	}
+
+	a.result.Issues = nil
+	a.result.Spilled = a.spilled
+	return issues
+}
+
+// Result fills in the end time and the totals derived from the issues,
+// records errors as the paths that could not be scanned, and returns the
+// result. It can be called again after more items are added.
+func (a *aggregator) Result(errors []models.ScanError) *Result {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := a.result
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	result.DurationSeconds = math.Round(result.Duration.Seconds()*1000) / 1000
+	result.DurationISO = isoDuration(result.Duration)
+	result.IssuesFound = a.spilled + len(result.Issues)
+	result.Summary = Summarize(result.Issues)
+	if a.spilled > 0 {
+		for issueType, n := range a.spilledSum.ByType {
+			result.Summary.ByType[issueType] += n
+		}
+		for severity, n := range a.spilledSum.BySeverity {
+			result.Summary.BySeverity[severity] += n
+		}
+		result.Summary.ActionRequired += a.spilledSum.ActionRequired
+		result.Summary.AutoSkipped += a.spilledSum.AutoSkipped
+	}
+	result.ReadinessScore = readinessScore(result.Issues, a.spilledWorst, result.TotalItems, a.weights)
+	result.Errors = errors
+	return result
+}
+
+// suppress drops the issues matched by the ignore list, counting them in
+// Result.Suppressed, and returns the rest
+func (a *aggregator) suppress(issues []Issue) []Issue {
+	if a.ignore == nil || len(issues) == 0 {
+		return issues
+	}
+
+	var kept []Issue
+	for _, issue := range issues {
+		if a.ignore.Match(issue, a.result.ScanPath) {
+			a.result.Suppressed++
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+// isoDuration formats d as an ISO 8601 duration to the millisecond, such
+// as PT2H5M3.25S
+func isoDuration(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+
+	text := "PT"
+	if hours > 0 {
+		text += strconv.FormatInt(int64(hours), 10) + "H"
+	}
+	if minutes > 0 {
+		text += strconv.FormatInt(int64(minutes), 10) + "M"
+	}
+	if d > 0 || hours == 0 && minutes == 0 {
+		text += strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S"
+	}
+	return text
+}
@@ -0,0 +1,204 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+func TestIsoDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "PT0S"},
+		{250 * time.Millisecond, "PT0.25S"},
+		{1400 * time.Microsecond, "PT0.001S"},
+		{42 * time.Second, "PT42S"},
+		{90*time.Second + 250*time.Millisecond, "PT1M30.25S"},
+		{5 * time.Minute, "PT5M"},
+		{2*time.Hour + 5*time.Minute + 3250*time.Millisecond, "PT2H5M3.25S"},
+		{26 * time.Hour, "PT26H"},
+	}
+	for _, tt := range tests {
+		if got := isoDuration(tt.d); got != tt.want {
+			t.Errorf("isoDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestResultJSONHasReadableDuration(t *testing.T) {
+	start := time.Now().Add(-(90*time.Second + 250*time.Millisecond))
+	result := newAggregator(&Result{StartTime: start}, nil, ReadinessWeights{}).Result(nil)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		StartTime       string  `json:"startTime"`
+		EndTime         string  `json:"endTime"`
+		DurationSeconds float64 `json:"durationSeconds"`
+		DurationISO     string  `json:"durationIso"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if !regexp.MustCompile(`^PT1M30\.\d{1,3}S$`).MatchString(report.DurationISO) {
+		t.Errorf("durationIso = %q, want about PT1M30.25S", report.DurationISO)
+	}
+	if report.DurationSeconds < 90.25 || report.DurationSeconds > 91 {
+		t.Errorf("durationSeconds = %v, want about 90.25", report.DurationSeconds)
+	}
+	for name, value := range map[string]string{"startTime": report.StartTime, "endTime": report.EndTime} {
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			t.Errorf("%s = %q is not RFC 3339", name, value)
+		}
+	}
+}
+
+// testIssues returns n issues on n/2 paths, cycling through the issue
+// types and severities, with every third one requiring action
+func testIssues(n int) []Issue {
+	types := []IssueType{models.IssuePathLength, models.IssueInvalidCharacters, models.IssueReservedName}
+	issues := make([]Issue, n)
+	for i := range issues {
+		issues[i] = Issue{
+			Path:           fmt.Sprintf("/share/file%d", i/2),
+			Type:           types[i%len(types)],
+			Severity:       models.Severities[i%len(models.Severities)],
+			ActionRequired: i%3 == 0,
+		}
+	}
+	return issues
+}
+
+func TestAggregatorTotalsAndSummary(t *testing.T) {
+	a := newAggregator(&Result{}, nil, config.NewDefaultConfig().Settings.ReportSettings.ReadinessWeights)
+	issues := testIssues(6)
+
+	a.AddItem(&Item{Path: "/share/docs", IsDir: true})
+	a.AddItem(&Item{Path: "/share/file0", Size: 100, Issues: issues[:2]})
+	a.AddItem(&Item{Path: "/share/file1", Size: 20, Issues: issues[2:4]})
+	// Filtered items count toward the totals, but their issues do not
+	a.AddItem(&Item{Path: "/share/big.iso", Size: 5000, Filtered: true, Issues: testIssues(4)})
+	if kept := a.AddIssues(issues[4:]); len(kept) != 2 {
+		t.Errorf("AddIssues kept %d issues, want 2", len(kept))
+	}
+
+	result := a.Result(nil)
+	if result.TotalItems != 4 || result.TotalFiles != 3 || result.TotalFolders != 1 || result.TotalSize != 5120 {
+		t.Errorf("totals = %d items, %d files, %d folders, %d bytes; want 4, 3, 1, 5120",
+			result.TotalItems, result.TotalFiles, result.TotalFolders, result.TotalSize)
+	}
+	if result.IssuesFound != 6 || len(result.Issues) != 6 {
+		t.Errorf("IssuesFound = %d with %d issues, want 6", result.IssuesFound, len(result.Issues))
+	}
+
+	want := Summary{
+		ByType: map[IssueType]int{
+			models.IssuePathLength:        2,
+			models.IssueInvalidCharacters: 2,
+			models.IssueReservedName:      2,
+		},
+		BySeverity:     map[models.Severity]int{},
+		ActionRequired: 2,
+		AutoSkipped:    4,
+	}
+	for i := 0; i < 6; i++ {
+		want.BySeverity[models.Severities[i%len(models.Severities)]]++
+	}
+	if !reflect.DeepEqual(result.Summary, want) {
+		t.Errorf("summary = %+v, want %+v", result.Summary, want)
+	}
+
+	count, byType := a.IssueCounts()
+	if count != 6 || !reflect.DeepEqual(byType, want.ByType) {
+		t.Errorf("IssueCounts = %d, %v; want 6, %v", count, byType, want.ByType)
+	}
+}
+
+func TestAggregatorSkipsSizesWhenNotRead(t *testing.T) {
+	a := newAggregator(&Result{SizesSkipped: true}, nil, ReadinessWeights{})
+	a.AddItem(&Item{Path: "/share/file", Size: 100})
+	if result := a.Result(nil); result.TotalSize != 0 {
+		t.Errorf("TotalSize = %d with sizes skipped, want 0", result.TotalSize)
+	}
+}
+
+// TestAggregatorSpill checks that spilling issues part-way through leaves
+// the totals, summary and readiness score as they would be without it
+func TestAggregatorSpill(t *testing.T) {
+	weights := config.NewDefaultConfig().Settings.ReportSettings.ReadinessWeights
+	issues := testIssues(40)
+
+	whole := newAggregator(&Result{}, nil, weights)
+	spilled := newAggregator(&Result{}, nil, weights)
+	var handedOff []Issue
+	for i := 0; i < len(issues); i += 4 {
+		path := issues[i].Path
+		whole.AddItem(&Item{Path: path, Issues: issues[i : i+4]})
+		spilled.AddItem(&Item{Path: path, Issues: issues[i : i+4]})
+		if i%12 == 8 {
+			handedOff = append(handedOff, spilled.Spill()...)
+		}
+	}
+	handedOff = append(handedOff, spilled.Spill()...)
+	if spilled.Spill() != nil {
+		t.Error("Spill with nothing collected returned issues")
+	}
+	spilled.AddIssues(testIssues(3))
+	whole.AddIssues(testIssues(3))
+
+	want := whole.Result(nil)
+	got := spilled.Result(nil)
+	if got.Spilled != 40 || len(got.Issues) != 3 || len(handedOff) != 40 {
+		t.Errorf("spilled %d, kept %d, handed off %d; want 40, 3, 40", got.Spilled, len(got.Issues), len(handedOff))
+	}
+	if got.IssuesFound != want.IssuesFound {
+		t.Errorf("IssuesFound = %d, want %d", got.IssuesFound, want.IssuesFound)
+	}
+	if !reflect.DeepEqual(got.Summary, want.Summary) {
+		t.Errorf("summary = %+v, want %+v", got.Summary, want.Summary)
+	}
+	if got.ReadinessScore != want.ReadinessScore {
+		t.Errorf("readiness score = %d, want %d", got.ReadinessScore, want.ReadinessScore)
+	}
+	if count, _ := spilled.IssueCounts(); count != 43 {
+		t.Errorf("IssueCounts = %d, want 43", count)
+	}
+}
+
+func TestAggregatorIsSafeForConcurrentUse(t *testing.T) {
+	a := newAggregator(&Result{}, nil, ReadinessWeights{})
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				a.AddItem(&Item{Path: "/share/file", Size: 1, Issues: testIssues(2)})
+				if i%10 == 0 {
+					a.Spill()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	result := a.Result(nil)
+	if result.TotalItems != 800 || result.TotalSize != 800 || result.IssuesFound != 1600 {
+		t.Errorf("totals = %d items, %d bytes, %d issues; want 800, 800, 1600", result.TotalItems, result.TotalSize, result.IssuesFound)
+	}
+	if n := result.Summary.ActionRequired + result.Summary.AutoSkipped; n != 1600 {
+		t.Errorf("summary counts %d issues, want 1600", n)
+	}
+}
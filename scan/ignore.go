@@ -0,0 +1,155 @@
+package scan
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreList suppresses accepted findings. Each rule is a path glob,
+// optionally scoped to one issue type, matched against paths relative to
+// the scan root.
+type IgnoreList struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	issueType string // Lower-case issue type or code, or "" for every issue
+	pattern   *regexp.Regexp
+	anyDepth  bool // Pattern has no "/" and is matched against each name
+}
+
+// LoadIgnoreFile reads an ignore file. Each line holds a glob, optionally
+// preceded by an issue type or code in brackets:
+//
+//	# Accepted for the dev archive
+//	**/node_modules
+//	[ProblematicFile] Archive/*.zip
+//	[SPO-PATH-003] Projects/**
+//
+// "*" and "?" do not cross "/" and "**" matches any number of folders. A
+// glob without "/" matches a file or folder name at any depth. A rule that
+// matches a folder also matches everything under it. Matching ignores
+// case, and blank lines and lines starting with "#" are skipped.
+func LoadIgnoreFile(path string) (*IgnoreList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+	defer file.Close()
+
+	list := &IgnoreList{}
+	lineScanner := bufio.NewScanner(file)
+	for lineNum := 1; lineScanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(lineScanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule, err := parseIgnoreRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("ignore file %s line %d: %w", path, lineNum, err)
+		}
+		list.rules = append(list.rules, rule)
+	}
+	if err := lineScanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+
+	return list, nil
+}
+
+func parseIgnoreRule(line string) (ignoreRule, error) {
+	var rule ignoreRule
+
+	if strings.HasPrefix(line, "[") {
+		end := strings.Index(line, "]")
+		if end < 0 {
+			return rule, fmt.Errorf("missing ] after issue type")
+		}
+		rule.issueType = strings.ToLower(strings.TrimSpace(line[1:end]))
+		line = strings.TrimSpace(line[end+1:])
+	}
+
+	glob := strings.Trim(filepath.ToSlash(line), "/")
+	if glob == "" {
+		return rule, fmt.Errorf("missing path glob")
+	}
+	rule.anyDepth = !strings.Contains(glob, "/")
+
+	pattern, err := regexp.Compile("(?i)^" + globToRegexp(glob) + "$")
+	if err != nil {
+		return rule, fmt.Errorf("invalid glob %q: %w", glob, err)
+	}
+	rule.pattern = pattern
+
+	return rule, nil
+}
+
+// globToRegexp translates a glob into a regular expression
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			b.WriteString("[^/]*")
+		case glob[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// Match reports whether issue is suppressed. root is the scan root the
+// globs are relative to.
+func (l *IgnoreList) Match(issue Issue, root string) bool {
+	if l == nil || len(l.rules) == 0 {
+		return false
+	}
+
+	rel, err := filepath.Rel(root, issue.Path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		rel = issue.Path
+	}
+	rel = strings.Trim(filepath.ToSlash(rel), "/")
+	issueType := strings.ToLower(string(issue.Type))
+	code := strings.ToLower(issue.Code)
+
+	for _, rule := range l.rules {
+		if rule.issueType != "" && rule.issueType != issueType && rule.issueType != code {
+			continue
+		}
+		if rule.matches(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the rule matches rel or one of its parent folders
+func (r ignoreRule) matches(rel string) bool {
+	segments := strings.Split(rel, "/")
+	for i := range segments {
+		if r.anyDepth {
+			if r.pattern.MatchString(segments[i]) {
+				return true
+			}
+			continue
+		}
+		if r.pattern.MatchString(strings.Join(segments[:i+1], "/")) {
+			return true
+		}
+	}
+	return false
+}
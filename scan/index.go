@@ -0,0 +1,149 @@
+package scan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/validator"
+)
+
+// Index records the size, modification time and issues of every file from
+// a previous scan so an incremental scan can skip validating files that
+// have not changed
+type Index struct {
+	// ConfigHash identifies the scan root, destination, limits and rules
+	// the issues were produced with. Entries are ignored when it does not
+	// match the current scan.
+	ConfigHash string                `json:"configHash"`
+	Files      map[string]IndexEntry `json:"files"`
+}
+
+// IndexEntry is one file in an Index
+type IndexEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Issues  []Issue   `json:"issues,omitempty"`
+}
+
+// LoadIndex reads an index written by Save
+func LoadIndex(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse index %s: %w", path, err)
+	}
+	return &idx, nil
+}
+
+// Save writes the index to path
+func (idx *Index) Save(path string) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+
+	// Write to a temporary file first so an interrupted save never leaves
+	// a truncated index behind
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// configHash fingerprints everything that affects per-file issues: the
+// limits and rules, the enabled checks and the settings the checks read.
+// Report and console settings, such as the company name, report title and
+// output formats, are left out, so changing them keeps the index.
+func configHash(cfg *config.Config, scanPath, destination string, checks map[string]bool) string {
+	settings := cfg.Settings
+	data, err := json.Marshal(struct {
+		SchemaVersion    string
+		ScanPath         string
+		Destination      string
+		Checks           map[string]bool
+		SPOLimits        *config.SPOLimits
+		BlockedFileTypes *config.BlockedFileTypes
+		ProblematicFiles *config.ProblematicFiles
+		CustomRules      []config.CustomRule
+		Settings         interface{}
+	}{
+		SchemaVersion:    models.SchemaVersion,
+		ScanPath:         scanPath,
+		Destination:      destination,
+		Checks:           checks,
+		SPOLimits:        cfg.SPOLimits,
+		BlockedFileTypes: cfg.BlockedFileTypes,
+		ProblematicFiles: cfg.ProblematicFiles,
+		CustomRules:      cfg.CustomRules,
+		Settings: struct {
+			PathWarningThresholdPercent int
+			FolderNameWarningLength     int
+			PathLengthBasis             string
+			NameReplacement             string
+			Language                    string
+			SyncRoot                    string
+			AcceptedCategories          []string
+			AutoSkippedCodes            []string
+			ProblematicMinSizeBytes     int64
+			FileSizeWarnings            []config.FileSizeTier
+		}{
+			settings.PathWarningThresholdPercent,
+			settings.FolderNameWarningLength,
+			settings.PathLengthBasis,
+			settings.NameReplacement,
+			settings.Language,
+			settings.SyncRoot,
+			settings.AcceptedCategories,
+			settings.AutoSkippedCodes,
+			settings.ProblematicMinSizeBytes,
+			settings.FileSizeWarnings,
+		},
+	})
+	if err != nil {
+		// An unhashable config never matches, forcing a full scan
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedValidator reuses the issues of files that are unchanged since the
+// previous index and validates everything else
+type cachedValidator struct {
+	*validator.Validator
+	previous map[string]IndexEntry
+}
+
+func (c cachedValidator) ValidateItem(item *models.FileSystemItem) []models.Issue {
+	entry, ok := c.previous[item.Path]
+	if !ok || item.IsDir || item.IsLocked || entry.Size != item.Size || !entry.ModTime.Equal(item.ModTime) {
+		return c.Validator.ValidateItem(item)
+	}
+
+	c.Validator.Observe(item)
+
+	// Whether a file is in use is only true at scan time
+	issues := make([]models.Issue, 0, len(entry.Issues))
+	for _, issue := range entry.Issues {
+		if issue.Type != models.IssueFileLocked {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
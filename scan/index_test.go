@@ -0,0 +1,57 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+)
+
+func TestConfigHashIgnoresReportSettings(t *testing.T) {
+	checks := map[string]bool{"PathLength": true}
+	base := configHash(config.NewDefaultConfig(), "/share", "", checks)
+	if base == "" {
+		t.Fatal("default config could not be hashed")
+	}
+
+	unchanged := map[string]func(*config.Config){
+		"company":        func(c *config.Config) { c.Settings.ReportSettings.CompanyName = "Contoso" },
+		"project":        func(c *config.Config) { c.Settings.ReportSettings.ProjectName = "Migration" },
+		"report title":   func(c *config.Config) { c.Settings.ReportSettings.ReportTitle = "Readiness" },
+		"output formats": func(c *config.Config) { c.Settings.DefaultOutputFormats = []string{"JSON"} },
+		"weights":        func(c *config.Config) { c.Settings.ReportSettings.ReadinessWeights.Info = 0.5 },
+		"progress":       func(c *config.Config) { c.Settings.ProgressUpdateInterval = 2000 },
+		"colors":         func(c *config.Config) { c.Settings.ConsoleSettings.UseColors = false },
+	}
+	for name, change := range unchanged {
+		cfg := config.NewDefaultConfig()
+		change(cfg)
+		if got := configHash(cfg, "/share", "", checks); got != base {
+			t.Errorf("changing the %s invalidated the index", name)
+		}
+	}
+
+	changed := map[string]func(*config.Config){
+		"path limit":      func(c *config.Config) { c.SPOLimits.MaxPathLength = 260 },
+		"blocked types":   func(c *config.Config) { c.BlockExtensions([]string{"iso"}) },
+		"custom rules":    func(c *config.Config) { c.CustomRules = []config.CustomRule{{Name: "x", Pattern: "x"}} },
+		"path basis":      func(c *config.Config) { c.Settings.PathLengthBasis = "encoded" },
+		"replacement":     func(c *config.Config) { c.Settings.NameReplacement = "-" },
+		"size tiers":      func(c *config.Config) { c.Settings.FileSizeWarnings = nil },
+		"auto-skipped":    func(c *config.Config) { c.Settings.AutoSkippedCodes = nil },
+		"warning percent": func(c *config.Config) { c.Settings.PathWarningThresholdPercent = 50 },
+	}
+	for name, change := range changed {
+		cfg := config.NewDefaultConfig()
+		change(cfg)
+		if got := configHash(cfg, "/share", "", checks); got == base {
+			t.Errorf("changing the %s kept the index", name)
+		}
+	}
+
+	if configHash(config.NewDefaultConfig(), "/share", "", map[string]bool{"PathLength": false}) == base {
+		t.Error("changing the checks kept the index")
+	}
+	if configHash(config.NewDefaultConfig(), "/other", "", checks) == base {
+		t.Error("changing the scan path kept the index")
+	}
+}
@@ -0,0 +1,106 @@
+package scan
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/scanner"
+)
+
+const (
+	// memoryCheckInterval is the least time between memory readings;
+	// runtime.ReadMemStats briefly stops the world
+	memoryCheckInterval = 500 * time.Millisecond
+
+	// maxMemoryPause is the longest the walk waits for memory to fall
+	// below the limit before it carries on regardless
+	maxMemoryPause = 5 * time.Second
+
+	// memoryLimitHeadroom sets the Go runtime's memory limit this fraction
+	// above Options.MaxMemory, since a runtime limit the heap stays at, as
+	// it can for a soft limit, makes the collector run continuously
+	memoryLimitHeadroom = 4
+)
+
+// memoryGovernor keeps a scan under Options.MaxMemory. From 90% of the
+// limit it hands the issues collected so far to Options.Spill, and if
+// that is not enough, throttles the walk until the items in flight have
+// been taken in. The limit is soft: memory held elsewhere, such as by the
+// whole-tree checks, can keep the heap above it, and the walk then goes on
+// after maxMemoryPause.
+type memoryGovernor struct {
+	limit   uint64
+	scanner *scanner.Scanner
+	agg     *aggregator
+	spill   func([]Issue) error
+	logger  *slog.Logger
+
+	lastCheck time.Time
+	pausedAt  time.Time
+	paused    bool
+	gaveUp    bool // Waiting did not help; do not pause again until under the limit
+}
+
+func newMemoryGovernor(limit int64, scnr *scanner.Scanner, agg *aggregator, spill func([]Issue) error, logger *slog.Logger) *memoryGovernor {
+	return &memoryGovernor{limit: uint64(limit), scanner: scnr, agg: agg, spill: spill, logger: logger}
+}
+
+// Check measures memory, at most every memoryCheckInterval, and spills or
+// throttles as needed. It returns the error from Spill, if any.
+func (g *memoryGovernor) Check() error {
+	if time.Since(g.lastCheck) < memoryCheckInterval {
+		return nil
+	}
+	g.lastCheck = time.Now()
+
+	highWater := g.limit / 10 * 9
+	heap := heapAlloc()
+	if heap < highWater {
+		g.resume()
+		g.gaveUp = false
+		return nil
+	}
+
+	if g.spill != nil {
+		if issues := g.agg.Spill(); len(issues) > 0 {
+			if err := g.spill(issues); err != nil {
+				return fmt.Errorf("failed to spill issues: %w", err)
+			}
+			g.logger.Debug("spilled issues to stay within the memory limit", "issues", len(issues), "heapBytes", heap)
+			runtime.GC()
+			if heap = heapAlloc(); heap < highWater {
+				g.resume()
+				return nil
+			}
+		}
+	}
+
+	switch {
+	case g.gaveUp:
+	case !g.paused:
+		g.paused = true
+		g.pausedAt = time.Now()
+		g.scanner.Throttle(true)
+		g.logger.Debug("throttling the scan to stay within the memory limit", "heapBytes", heap, "limitBytes", g.limit)
+	case time.Since(g.pausedAt) >= maxMemoryPause:
+		g.resume()
+		g.gaveUp = true
+		g.logger.Warn("memory is still above the limit; continuing", "heapBytes", heap, "limitBytes", g.limit)
+	}
+	return nil
+}
+
+func (g *memoryGovernor) resume() {
+	if g.paused {
+		g.paused = false
+		g.scanner.Throttle(false)
+	}
+}
+
+func heapAlloc() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
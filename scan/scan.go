@@ -0,0 +1,529 @@
+// Package scan runs a SharePoint readiness scan without any terminal,
+// TUI or report side effects, so the checks can be embedded in other Go
+// programs. The spready command is a thin wrapper around Run.
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/scanner"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/validator"
+)
+
+// Types shared with the rest of the module, re-exported so callers
+// outside it can name them
+type (
+	Result           = models.ScanResult
+	Issue            = models.Issue
+	IssueType        = models.IssueType
+	Summary          = models.IssueSummary
+	Item             = models.FileSystemItem
+	Progress         = models.ScanProgress
+	Config           = config.Config
+	FileFilter       = scanner.FileFilter
+	ReadinessWeights = config.ReadinessWeights
+)
+
+// DefaultConfig returns the built-in SharePoint Online limits and rules
+func DefaultConfig() *Config {
+	return config.NewDefaultConfig()
+}
+
+// LoadConfig reads a JSON config file on top of the defaults
+func LoadConfig(path string) (*Config, error) {
+	return config.LoadConfig(path)
+}
+
+// Options controls a scan
+type Options struct {
+	// Path is the scan root. Path lengths are measured relative to it.
+	Path string
+
+	// Paths, when non-nil, limits the scan to these paths instead of
+	// walking Path
+	Paths []string
+
+	// Destination is the SharePoint library URL used for path lengths
+	Destination string
+
+	// Config supplies limits and rules; nil uses DefaultConfig
+	Config *Config
+
+	// Checks enables or disables checks by name (for example
+	// "CaseConflicts"), overriding Config.Settings.DefaultChecks
+	Checks map[string]bool
+
+	// ExcludeFolders replaces Config.Settings.DefaultExcludeFolders when
+	// non-nil
+	ExcludeFolders []string
+
+	// MaxItems stops the scan after this many items; 0 means no limit
+	MaxItems int64
+
+	// Workers sets the number of validation goroutines; 0 uses the CPU
+	// count, capped at 8
+	Workers int
+
+	// SniffMaxSize limits the ExtensionMismatch check to files up to this
+	// many bytes; 0 uses 100 MB. Only the first 4 KB of a file is read.
+	SniffMaxSize int64
+
+	// ReadRetries is how many times a folder listing or file lookup that
+	// fails with a transient error, such as a timeout on a network share,
+	// is repeated before the path is recorded in Result.Errors; 0 uses 2
+	// and a negative count disables retrying. RetryBackoff is the wait
+	// before the first repeat, doubled for each one after it; 0 uses
+	// 500ms.
+	ReadRetries  int
+	RetryBackoff time.Duration
+
+	// MaxMemory is a soft limit on heap memory in bytes; 0 means none.
+	// The Go runtime's memory limit is set a quarter above it while Run
+	// runs, so the collector works harder past it without thrashing. As
+	// the heap nears it, the issues collected so far are passed to Spill
+	// and dropped from the result, which still counts them in IssuesFound,
+	// Summary, ReadinessScore and Spilled, and if that is not enough the
+	// walk is paused for the items in flight to be taken in. Memory held
+	// by the whole-tree checks is not covered, so the heap can go over it.
+	MaxMemory int64
+
+	// Spill receives the issues dropped under MaxMemory, for example to
+	// write them to disk. Issues passed to OnIssues have already been
+	// seen, so Spill can discard them. Without Spill, MaxMemory only
+	// pauses the walk. An error from Spill stops the scan.
+	Spill func([]Issue) error
+
+	// Filter skips files by size or modification time. Skipped files are
+	// left out of the totals unless CountFiltered is set.
+	Filter        FileFilter
+	CountFiltered bool
+
+	// Index, if set, makes the scan incremental: files whose size and
+	// modification time match an entry in Index reuse its issues instead
+	// of being validated again. Entries are ignored if the scan root,
+	// destination, config or checks changed. When Run returns, Index holds
+	// the files of this scan; a deleted file's entry and issues are gone.
+	// Do not save the index of a scan that returned an error, since it
+	// only holds the files seen before the scan stopped.
+	Index *Index
+
+	// Logger receives skipped paths, access errors and phase timings at
+	// debug level and a start and finish line at info level; nil discards
+	// them
+	Logger *slog.Logger
+
+	// Ignore, if set, drops matching issues from the result. They are
+	// counted in Result.Suppressed.
+	Ignore *IgnoreList
+
+	// OnItem, if set, is called for every item counted in the totals,
+	// after validation. Items skipped by Filter have Filtered set.
+	OnItem func(*Item)
+
+	// ItemSizes makes the walk read the size and modification time of
+	// every item, for OnItem callbacks that use them. Without it, a walk
+	// whose checks and Filter only look at names reads them for items
+	// with issues only, which saves a stat per item on network shares;
+	// TotalSize is then not measured and Result.SizesSkipped is set.
+	ItemSizes bool
+
+	// OnIssues, if set, is called with each batch of issues as it is
+	// added to the result: an item's issues after OnItem, and the
+	// whole-tree issues at the end. Suppressed issues are not included.
+	OnIssues func([]Issue)
+
+	// OnProgress, if set, is called with periodic progress updates
+	OnProgress func(*Progress)
+
+	// Observer, if set, is called alongside the callbacks above
+	Observer ProgressObserver
+}
+
+// ProgressObserver receives a scan's progress, for programs that drive
+// their own display. Run calls it from the goroutine that called Run and
+// never concurrently, so implementations need no locking. The scan waits
+// for each call, so slow displays should drop updates rather than block.
+type ProgressObserver interface {
+	// OnProgress is called with periodic progress updates
+	OnProgress(*Progress)
+
+	// OnIssue is called for each issue as it is added to the result
+	OnIssue(Issue)
+
+	// OnDone is called once with the result Run returns, which is partial
+	// if the scan stopped early
+	OnDone(*Result)
+}
+
+// MultiObserver returns an observer that passes each call on to every
+// non-nil observer in turn, or nil when there are none
+func MultiObserver(observers ...ProgressObserver) ProgressObserver {
+	var multi multiObserver
+	for _, o := range observers {
+		if o != nil {
+			multi = append(multi, o)
+		}
+	}
+	if len(multi) == 0 {
+		return nil
+	}
+	return multi
+}
+
+type multiObserver []ProgressObserver
+
+func (m multiObserver) OnProgress(progress *Progress) {
+	for _, o := range m {
+		o.OnProgress(progress)
+	}
+}
+
+func (m multiObserver) OnIssue(issue Issue) {
+	for _, o := range m {
+		o.OnIssue(issue)
+	}
+}
+
+func (m multiObserver) OnDone(result *Result) {
+	for _, o := range m {
+		o.OnDone(result)
+	}
+}
+
+// PanicError is returned by Run when a whole-tree check or a callback
+// panicked. The result returned with it holds what was collected before.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("scan panicked: %v", e.Value)
+}
+
+// Run scans opts.Path and returns the aggregated result. Callbacks are
+// called from the goroutine that called Run. If the scan stops early,
+// because ctx was canceled, the walk failed or something panicked, Run
+// returns the partial result together with the error. A panic in a
+// single item's checks only records a scan error for that item.
+func Run(ctx context.Context, opts Options) (res *Result, err error) {
+	if opts.Path == "" {
+		return nil, errors.New("scan path is required")
+	}
+
+	absPath, err := filepath.Abs(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = config.NewDefaultConfig()
+	}
+
+	checks := make(map[string]bool, len(cfg.Settings.DefaultChecks)+len(opts.Checks))
+	for name, enabled := range cfg.Settings.DefaultChecks {
+		checks[name] = enabled
+	}
+	for name, enabled := range opts.Checks {
+		checks[name] = enabled
+	}
+
+	excludeFolders := opts.ExcludeFolders
+	if excludeFolders == nil {
+		excludeFolders = cfg.Settings.DefaultExcludeFolders
+	}
+
+	scnr := scanner.NewScanner(absPath, excludeFolders, opts.MaxItems)
+	scnr.SetWorkers(opts.Workers)
+	scnr.SetProgressInterval(time.Duration(cfg.Settings.ProgressUpdateInterval) * time.Millisecond)
+	scnr.SetLogger(opts.Logger)
+	scnr.SetReadRetries(opts.ReadRetries, opts.RetryBackoff)
+	scnr.SetMemoryLimit(opts.MaxMemory)
+	scnr.SetCheckLocks(checks["FileLocks"])
+	scnr.SetCheckStreams(checks["AlternateStreams"])
+	scnr.SetCheckShortNames(checks["ShortNames"])
+	if checks["ExtensionMismatch"] {
+		sniffMaxSize := opts.SniffMaxSize
+		if sniffMaxSize <= 0 {
+			sniffMaxSize = scanner.DefaultSniffMaxSize
+		}
+		scnr.SetSniff(sniffMaxSize)
+	}
+	scnr.SetFilter(opts.Filter, opts.CountFiltered)
+	skipSizes := opts.Paths == nil && !opts.ItemSizes && opts.Filter.IsZero() && opts.Index == nil && !needsSizes(checks)
+	scnr.SetSkipInfo(skipSizes)
+
+	// Items are validated on the scanner's workers as they are discovered
+	// so the loop below only aggregates
+	v := validator.NewValidator(cfg, opts.Destination, checks)
+	scnr.SetValidator(v)
+
+	var nextFiles map[string]IndexEntry
+	if opts.Index != nil {
+		hash := configHash(cfg, absPath, opts.Destination, checks)
+		if opts.Index.ConfigHash == hash && len(opts.Index.Files) > 0 {
+			scnr.SetValidator(cachedValidator{Validator: v, previous: opts.Index.Files})
+		}
+		opts.Index.ConfigHash = hash
+		nextFiles = make(map[string]IndexEntry)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	startTime := time.Now()
+	logger.Info("scan started", "path", absPath, "pathList", opts.Paths != nil)
+	var (
+		itemsChan    <-chan *models.FileSystemItem
+		progressChan <-chan *models.ScanProgress
+		errChan      <-chan error
+	)
+	if opts.Paths != nil {
+		itemsChan, progressChan, errChan = scnr.ScanPaths(ctx, opts.Paths)
+	} else {
+		itemsChan, progressChan, errChan = scnr.Scan(ctx)
+	}
+
+	result := &models.ScanResult{
+		SchemaVersion:  models.SchemaVersion,
+		ScanPath:       absPath,
+		DestinationURL: opts.Destination,
+		StartTime:      startTime,
+		RootIssues:     v.ValidateRoot(absPath),
+		SizesSkipped:   skipSizes,
+	}
+	agg := newAggregator(result, opts.Ignore, cfg.Settings.ReportSettings.ReadinessWeights)
+
+	var scanErr error
+	var governor *memoryGovernor
+	if opts.MaxMemory > 0 {
+		defer debug.SetMemoryLimit(debug.SetMemoryLimit(opts.MaxMemory + opts.MaxMemory/memoryLimitHeadroom))
+		governor = newMemoryGovernor(opts.MaxMemory, scnr, agg, opts.Spill, logger)
+	}
+	checkMemory := func() {
+		if governor == nil || scanErr != nil {
+			return
+		}
+		if err := governor.Check(); err != nil {
+			scanErr = err
+			cancel()
+		}
+	}
+
+	// Deferred first so it sees the result set by the recover below
+	if opts.Observer != nil {
+		defer func() {
+			opts.Observer.OnDone(res)
+		}()
+	}
+
+	// Return what was collected if a callback or whole-tree check panics
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			logger.Error("scan panicked", "panic", r, "stack", string(stack))
+			cancel()
+			res, err = agg.Result(scnr.Errors()), &PanicError{Value: r, Stack: stack}
+		}
+	}()
+	if checks["ProblematicFiles"] || checks["Secrets"] || checks["VersionControl"] {
+		result.AcceptedCategories = cfg.Settings.AcceptedCategories
+	}
+
+	for itemsChan != nil || progressChan != nil || errChan != nil {
+		select {
+		case item, ok := <-itemsChan:
+			if !ok {
+				itemsChan = nil
+				continue
+			}
+
+			// The index keeps issues from before suppression, so a changed
+			// ignore file applies on the next run
+			if nextFiles != nil && !item.Filtered && !item.IsDir {
+				nextFiles[item.Path] = IndexEntry{Size: item.Size, ModTime: item.ModTime, Issues: item.Issues}
+			}
+			agg.AddItem(item)
+
+			if opts.OnItem != nil {
+				opts.OnItem(item)
+			}
+			if opts.OnIssues != nil && !item.Filtered && len(item.Issues) > 0 {
+				opts.OnIssues(item.Issues)
+			}
+			if opts.Observer != nil && !item.Filtered {
+				for _, issue := range item.Issues {
+					opts.Observer.OnIssue(issue)
+				}
+			}
+			checkMemory()
+
+		case progress, ok := <-progressChan:
+			if !ok {
+				progressChan = nil
+				continue
+			}
+			progress.IssuesFound, progress.IssuesByType = agg.IssueCounts()
+			if opts.OnProgress != nil {
+				opts.OnProgress(progress)
+			}
+			if opts.Observer != nil {
+				opts.Observer.OnProgress(progress)
+			}
+			checkMemory()
+
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			if err != nil && scanErr == nil {
+				scanErr = err
+				cancel()
+			}
+		}
+	}
+
+	if opts.Index != nil {
+		opts.Index.Files = nextFiles
+	}
+
+	logger.Debug("walk finished", "items", result.TotalItems, "elapsed", time.Since(startTime))
+
+	// Run whole-tree checks
+	finalizeStart := time.Now()
+	v.SetFolderContents(scnr.FolderContents())
+	treeIssues := agg.AddIssues(v.Finalize())
+	if opts.OnIssues != nil && len(treeIssues) > 0 {
+		opts.OnIssues(treeIssues)
+	}
+	if opts.Observer != nil {
+		for _, issue := range treeIssues {
+			opts.Observer.OnIssue(issue)
+		}
+	}
+	logger.Debug("whole-tree checks finished", "elapsed", time.Since(finalizeStart))
+
+	result = agg.Result(scnr.Errors())
+	if scnr.Truncated() {
+		result.Truncated = true
+		result.ItemLimit = opts.MaxItems
+		logger.Info("results truncated at item limit", "maxItems", opts.MaxItems)
+	}
+
+	logger.Info("scan finished", "items", result.TotalItems, "issues", result.IssuesFound,
+		"errors", len(result.Errors), "duration", result.Duration)
+
+	return result, scanErr
+}
+
+// sizeChecks are the checks that read the size of every file they see:
+// size limits and thresholds, the sniffing size cap, and the size of
+// version control metadata
+var sizeChecks = []string{"FileSize", "ProblematicFiles", "ExtensionMismatch", "VersionControl"}
+
+// needsSizes reports whether any enabled check reads item sizes
+func needsSizes(checks map[string]bool) bool {
+	for _, name := range sizeChecks {
+		if checks[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadinessScore rates a scan from 0 to 100. Each item is counted once, at
+// the severity of its worst issue, and the score is
+//
+//	100 × (1 − (Critical × c + Warning × w + Info × i))
+//
+// where c, w and i are the fractions of totalItems at each severity and
+// Critical, Warning and Info are the weights. The result is clamped to
+// 0–100 and rounded, so a clean scan scores 100 and, with a Critical
+// weight of 1, a scan where every item is Critical scores 0. Collapsed
+// issues count as Count items.
+func ReadinessScore(issues []Issue, totalItems int64, weights config.ReadinessWeights) int {
+	return readinessScore(issues, nil, totalItems, weights)
+}
+
+// readinessScore is ReadinessScore with the worst severity of items whose
+// issues are no longer held, such as those handed to Options.Spill
+func readinessScore(issues []Issue, spilled map[string]models.Severity, totalItems int64, weights config.ReadinessWeights) int {
+	if totalItems <= 0 {
+		return 100
+	}
+
+	worst := make(map[string]models.Severity, len(spilled))
+	for path, severity := range spilled {
+		worst[path] = severity
+	}
+	var collapsed [3]int64
+	for _, issue := range issues {
+		if issue.Count > 0 {
+			collapsed[severityRank(issue.Severity)] += int64(issue.Count)
+			continue
+		}
+		if current, ok := worst[issue.Path]; !ok || severityRank(issue.Severity) < severityRank(current) {
+			worst[issue.Path] = issue.Severity
+		}
+	}
+
+	counts := collapsed
+	for _, severity := range worst {
+		counts[severityRank(severity)]++
+	}
+
+	total := float64(totalItems)
+	penalty := weights.Critical*math.Min(float64(counts[0])/total, 1) +
+		weights.Warning*math.Min(float64(counts[1])/total, 1) +
+		weights.Info*math.Min(float64(counts[2])/total, 1)
+
+	score := math.Round(100 * (1 - penalty))
+	return int(math.Max(0, math.Min(100, score)))
+}
+
+// severityRank orders severities from worst (0) to least severe (2)
+func severityRank(severity models.Severity) int {
+	switch severity {
+	case models.SeverityCritical:
+		return 0
+	case models.SeverityWarning:
+		return 1
+	}
+	return 2
+}
+
+// Summarize counts issues by type and severity
+func Summarize(issues []Issue) Summary {
+	summary := models.IssueSummary{
+		ByType:     make(map[models.IssueType]int),
+		BySeverity: make(map[models.Severity]int),
+	}
+
+	for _, issue := range issues {
+		summary.ByType[issue.Type]++
+		summary.BySeverity[issue.Severity]++
+		if issue.ActionRequired {
+			summary.ActionRequired++
+		} else {
+			summary.AutoSkipped++
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,290 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/config"
+	"github.com/ajoshuasmith/sharepoint-prescan/internal/models"
+)
+
+// benchFiles is the size of the synthetic tree the benchmarks scan. Pass
+// -bench-files=1000000 for a tree the size of a large file share.
+var benchFiles = flag.Int("bench-files", 20000, "number of files in the synthetic tree scanned by the benchmarks")
+
+var (
+	benchTreeOnce sync.Once
+	benchTreeRoot string
+	benchTreeErr  error
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	code := m.Run()
+	if benchTreeRoot != "" {
+		os.RemoveAll(benchTreeRoot)
+	}
+	os.Exit(code)
+}
+
+// benchTree returns the root of a synthetic tree of *benchFiles files, 500
+// to a folder, built once and shared by the benchmarks. One name in 50 has
+// an invalid character, so the scan has issues to aggregate.
+func benchTree(b *testing.B) string {
+	b.Helper()
+	benchTreeOnce.Do(func() {
+		benchTreeRoot, benchTreeErr = os.MkdirTemp("", "spready-bench-")
+		if benchTreeErr != nil {
+			return
+		}
+		benchTreeErr = writeTree(benchTreeRoot, *benchFiles, 500)
+	})
+	if benchTreeErr != nil {
+		b.Fatal(benchTreeErr)
+	}
+	return benchTreeRoot
+}
+
+// writeTree creates that many empty files under root, perFolder to a folder
+func writeTree(root string, files, perFolder int) error {
+	for i := 0; i < files; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("Department %03d", i/perFolder))
+		if i%perFolder == 0 {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
+		name := fmt.Sprintf("Quarterly report %06d.docx", i)
+		if i%50 == 0 {
+			name = fmt.Sprintf("Budget|%06d.xlsx", i)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BenchmarkRun measures a whole scan of the synthetic tree, with items
+// validated on the scanner's workers as they are discovered and Run only
+// aggregating them
+func BenchmarkRun(b *testing.B) {
+	root := benchTree(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		result, err := Run(context.Background(), Options{Path: root})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if result.TotalFiles != int64(*benchFiles) {
+			b.Fatalf("scanned %d files, want %d", result.TotalFiles, *benchFiles)
+		}
+	}
+	b.ReportMetric(float64(*benchFiles+(*benchFiles+499)/500)*float64(b.N)/b.Elapsed().Seconds(), "items/s")
+}
+
+func TestRunCountsOnlyChildren(t *testing.T) {
+	root := t.TempDir()
+	if err := writeTree(root, 7, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Run(context.Background(), Options{Path: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 7 files in 2 folders
+	if result.TotalItems != 9 || result.TotalFiles != 7 || result.TotalFolders != 2 {
+		t.Errorf("totals = %d items, %d files, %d folders; want 9, 7, 2", result.TotalItems, result.TotalFiles, result.TotalFolders)
+	}
+	for _, issue := range result.Issues {
+		if issue.Path == root {
+			t.Errorf("the scan root was validated as an item: %+v", issue)
+		}
+	}
+}
+
+// BenchmarkRunWorkers scans the synthetic tree with different numbers of
+// validation workers. The walk itself stays sequential, so the gain levels
+// off once validation keeps up with it.
+func BenchmarkRunWorkers(b *testing.B) {
+	root := benchTree(b)
+
+	for _, workers := range []int{1, 2, 4, 8, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := Run(context.Background(), Options{Path: root, Workers: workers}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(*benchFiles)*float64(b.N)/b.Elapsed().Seconds(), "files/s")
+		})
+	}
+}
+
+func TestReadinessScore(t *testing.T) {
+	weights := config.NewDefaultConfig().Settings.ReportSettings.ReadinessWeights
+	issue := func(path string, severity models.Severity) Issue {
+		return Issue{Path: path, Severity: severity}
+	}
+	allCritical := make([]Issue, 0, 100)
+	for i := 0; i < 100; i++ {
+		allCritical = append(allCritical, issue(fmt.Sprintf("/share/%d", i), models.SeverityCritical))
+	}
+
+	tests := []struct {
+		name       string
+		issues     []Issue
+		totalItems int64
+		want       int
+	}{
+		{"clean scan", nil, 100, 100},
+		{"empty scan", nil, 0, 100},
+		{"all critical", allCritical, 100, 0},
+		{"more issues than items", append(allCritical, allCritical...), 100, 0},
+		// One item in ten is Critical: 100 × (1 − 0.1)
+		{"a tenth critical", allCritical[:10], 100, 90},
+		// Only the worst issue of an item counts
+		{"worst per item", []Issue{issue("/a", models.SeverityInfo), issue("/a", models.SeverityCritical)}, 10, 90},
+		// 100 × (1 − 0.4 × 0.5)
+		{"half warning", []Issue{issue("/a", models.SeverityWarning), issue("/b", models.SeverityWarning)}, 4, 80},
+		// Collapsed issues count as Count items
+		{"collapsed", []Issue{{Path: "/a", Severity: models.SeverityCritical, Count: 25}}, 100, 75},
+	}
+
+	for _, tt := range tests {
+		if got := ReadinessScore(tt.issues, tt.totalItems, weights); got != tt.want {
+			t.Errorf("%s: score = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadinessScoreOfCleanAndCriticalScans(t *testing.T) {
+	clean := t.TempDir()
+	if err := os.WriteFile(filepath.Join(clean, "Report.docx"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Run(context.Background(), Options{Path: clean})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ReadinessScore != 100 {
+		t.Errorf("clean scan scored %d, want 100", result.ReadinessScore)
+	}
+
+	critical := t.TempDir()
+	for _, name := range []string{"a|1.txt", "b|2.txt", "c|3.txt"} {
+		if err := os.WriteFile(filepath.Join(critical, name), nil, 0644); err != nil {
+			t.Skipf("cannot create %q here: %v", name, err)
+		}
+	}
+	result, err = Run(context.Background(), Options{Path: critical})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ReadinessScore > 5 {
+		t.Errorf("all-critical scan scored %d, want near 0", result.ReadinessScore)
+	}
+}
+
+// checkGoroutines fails t if more goroutines are running than before once
+// those still winding down have had a moment to exit, and lists them
+func checkGoroutines(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			buf = buf[:runtime.Stack(buf, true)]
+			t.Fatalf("%d goroutines running, %d before the scan:\n%s", runtime.NumGoroutine(), before, buf)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunCancelLeavesNoGoroutines(t *testing.T) {
+	root := t.TempDir()
+	if err := writeTree(root, 2000, 100); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewDefaultConfig()
+	cfg.Settings.ProgressUpdateInterval = config.MinProgressUpdateInterval
+
+	for _, workers := range []int{1, 8} {
+		before := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+		seen := 0
+		result, err := Run(ctx, Options{
+			Path:    root,
+			Config:  cfg,
+			Workers: workers,
+			OnItem: func(*Item) {
+				if seen++; seen == 100 {
+					cancel()
+				}
+			},
+		})
+		cancel()
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("workers=%d: Run returned %v, want context.Canceled", workers, err)
+		}
+		if result == nil || result.TotalItems >= 2020 {
+			t.Errorf("workers=%d: canceled scan returned %+v, want a partial result", workers, result)
+		}
+		checkGoroutines(t, before)
+	}
+}
+
+func TestRunLeavesNoGoroutines(t *testing.T) {
+	root := t.TempDir()
+	if err := writeTree(root, 200, 50); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewDefaultConfig()
+	cfg.Settings.ProgressUpdateInterval = config.MinProgressUpdateInterval
+
+	before := runtime.NumGoroutine()
+	if _, err := Run(context.Background(), Options{Path: root, Config: cfg, Workers: 4}); err != nil {
+		t.Fatal(err)
+	}
+	checkGoroutines(t, before)
+}
+
+func TestRunFindsNameConflictsDuringTheWalk(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{"P/Data/Deep/x.txt", "P/data.", "Q/data"} {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Skipf("cannot create %q here: %v", rel, err)
+		}
+	}
+
+	result, err := Run(context.Background(), Options{Path: root, Workers: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, issue := range result.Issues {
+		if issue.Type == models.IssueNameConflict {
+			got = append(got, issue.Path)
+		}
+	}
+	sort.Strings(got)
+	want := []string{filepath.Join(root, "P", "Data"), filepath.Join(root, "P", "data.")}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("name conflicts on %q, want %q", got, want)
+	}
+}